@@ -6,6 +6,7 @@ import (
 	"io"
 	"maps"
 	"math/rand"
+	"slices"
 	"strconv"
 	"sync"
 	"time"
@@ -22,6 +23,17 @@ type ClusterAdmin interface {
 	// may not return information about the new topic.The validateOnly option is supported from version 0.10.2.0.
 	CreateTopic(topic string, detail *TopicDetail, validateOnly bool) error
 
+	// Creates several topics with a single request. The returned map contains a
+	// result for every requested topic with its error, if any, and the number of
+	// partitions, replication factor and configs the broker created it with
+	// (brokers with version 2.4.0.0 or higher). Topic creation is not
+	// transactional so it may succeed for some topics while fail for others.
+	// The returned error is set when a request failed as a whole, in which case
+	// it is also recorded against every topic without a result, or when topics
+	// still reported NOT_CONTROLLER after Admin.Retry.Max attempts.
+	// This operation is supported by brokers with version 0.10.1.0 or higher.
+	CreateTopics(topics map[string]*TopicDetail, validateOnly bool) (map[string]*CreateTopicResult, error)
+
 	// List the topics available in the cluster with the default options.
 	ListTopics() (map[string]TopicDetail, error)
 
@@ -36,6 +48,14 @@ type ClusterAdmin interface {
 	// This operation is supported by brokers with version 0.10.1.0 or higher.
 	DeleteTopic(topic string) error
 
+	// Deletes several topics with a single request. The returned map contains
+	// the error for every requested topic, which is nil if the topic was deleted.
+	// The returned error is set when a request failed as a whole, in which case
+	// it is also recorded against every topic without a result, or when topics
+	// still reported NOT_CONTROLLER after Admin.Retry.Max attempts.
+	// This operation is supported by brokers with version 0.10.1.0 or higher.
+	DeleteTopics(topics []string) (map[string]error, error)
+
 	// Increase the number of partitions of the topics  according to the corresponding values.
 	// If partitions are increased for a topic that has a key, the partition logic or ordering of
 	// the messages will be affected. It may take several seconds after this method returns
@@ -110,6 +130,15 @@ type ClusterAdmin interface {
 	// Describe the given consumer groups.
 	DescribeConsumerGroups(groups []string) ([]*GroupDescription, error)
 
+	// Describe the given consumer groups with decoded member subscriptions and
+	// assignments. Groups using the consumer rebalance protocol (KIP-848) are
+	// described with ConsumerGroupDescribe when the coordinator supports it,
+	// all other groups fall back to DescribeGroups. The descriptions are in
+	// the order of groups. Errors for single groups are reported in the Err
+	// field of their description, ErrGroupIDNotFound for groups missing from
+	// the response of their coordinator.
+	DescribeConsumerGroupDetails(groups []string) ([]*ConsumerGroupDescription, error)
+
 	// List the consumer group offsets available in the cluster.
 	ListConsumerGroupOffsets(group string, topicPartitions map[string][]int32) (*OffsetFetchResponse, error)
 
@@ -119,9 +148,16 @@ type ClusterAdmin interface {
 	// Delete a consumer group.
 	DeleteConsumerGroup(group string) error
 
-	// Get information about the nodes in the cluster
+	// Get information about the nodes in the cluster. The DescribeCluster API is
+	// used when the controller advertises it (Kafka 2.8.0.0 or higher), otherwise
+	// the information is retrieved from a metadata request.
 	DescribeCluster() (brokers []*Broker, controllerID int32, err error)
 
+	// Describe the KRaft metadata quorum: the active controller, its epoch, the
+	// high watermark and the replication state and lag of voters and observers.
+	// This operation is supported by KRaft clusters with version 3.3.0.0 or higher.
+	DescribeQuorum() (*QuorumInfo, error)
+
 	// Get information about all log directories on the given set of brokers
 	DescribeLogDirs(brokers []int32) (map[int32][]DescribeLogDirsResponseDirMetadata, error)
 
@@ -142,6 +178,17 @@ type ClusterAdmin interface {
 	// This operation is supported by brokers with version 2.6.0.0 or higher.
 	AlterClientQuotas(entity []QuotaEntityComponent, op ClientQuotasOp, validateOnly bool) error
 
+	// Get the features supported by the controller and the cluster-wide finalized
+	// features, such as metadata.version.
+	// This operation is supported by brokers with version 2.7.0.0 or higher.
+	DescribeFeatures() (*FeatureMetadata, error)
+
+	// Update the cluster-wide finalized features. Updates are not transactional so
+	// they may succeed for some features while fail for others.
+	// This operation is supported by brokers with version 2.7.0.0 or higher, the
+	// validateOnly option and downgrade types are supported from version 3.3.0.0.
+	UpdateFeatures(updates map[string]FeatureUpdate, validateOnly bool) error
+
 	// Controller returns the cluster controller broker. It will return a
 	// locally cached value if it's available.
 	Controller() (*Broker, error)
@@ -287,6 +334,66 @@ func (ca *clusterAdmin) CreateTopic(topic string, detail *TopicDetail, validateO
 	})
 }
 
+func (ca *clusterAdmin) CreateTopics(topics map[string]*TopicDetail, validateOnly bool) (map[string]*CreateTopicResult, error) {
+	if len(topics) == 0 {
+		return nil, errors.New("you must specify at least one topic")
+	}
+	for topic, detail := range topics {
+		if topic == "" {
+			return nil, ErrInvalidTopic
+		}
+		if detail == nil {
+			return nil, errors.New("you must specify topic details")
+		}
+	}
+
+	results := make(map[string]*CreateTopicResult, len(topics))
+	pending := maps.Clone(topics)
+
+	err := ca.retryOnError(isRetriableControllerError, func() error {
+		b, err := ca.Controller()
+		if err != nil {
+			return err
+		}
+
+		request := NewCreateTopicsRequest(ca.conf.Version, pending, ca.conf.Admin.Timeout, validateOnly)
+		rsp, err := b.CreateTopics(request)
+		if err != nil {
+			return err
+		}
+
+		for topic := range pending {
+			topicErr, ok := rsp.TopicErrors[topic]
+			if !ok {
+				results[topic] = &CreateTopicResult{Err: ErrIncompleteResponse, NumPartitions: -1, ReplicationFactor: -1}
+				delete(pending, topic)
+				continue
+			}
+			results[topic] = newCreateTopicResult(topicErr, rsp.TopicResults[topic])
+			// topics whose controller moved are retried, everything else is final
+			if !isRetriableControllerError(topicErr.Err) {
+				delete(pending, topic)
+			}
+		}
+
+		if len(pending) > 0 {
+			_, _ = ca.refreshController()
+			return ErrNotController
+		}
+		return nil
+	})
+	if err != nil {
+		for topic := range pending {
+			if _, ok := results[topic]; !ok {
+				results[topic] = &CreateTopicResult{Err: err, NumPartitions: -1, ReplicationFactor: -1}
+			}
+		}
+		return results, err
+	}
+
+	return results, nil
+}
+
 func (ca *clusterAdmin) DescribeTopics(topics []string) (metadata []*TopicMetadata, err error) {
 	var response *MetadataResponse
 	err = ca.retryOnError(isRetriableControllerError, func() error {
@@ -308,15 +415,21 @@ func (ca *clusterAdmin) DescribeTopics(topics []string) (metadata []*TopicMetada
 }
 
 func (ca *clusterAdmin) DescribeCluster() (brokers []*Broker, controllerID int32, err error) {
-	var response *MetadataResponse
 	err = ca.retryOnError(isRetriableControllerError, func() error {
 		controller, err := ca.Controller()
 		if err != nil {
 			return err
 		}
 
-		request := NewMetadataRequest(ca.conf.Version, nil)
-		response, err = controller.GetMetadata(request)
+		if ca.conf.Version.IsAtLeast(V2_8_0_0) && controller.supportsAPI(apiKeyDescribeCluster) {
+			brokers, controllerID, err = ca.describeCluster(controller)
+		} else {
+			var response *MetadataResponse
+			response, err = controller.GetMetadata(NewMetadataRequest(ca.conf.Version, nil))
+			if err == nil {
+				brokers, controllerID = response.Brokers, response.ControllerID
+			}
+		}
 		if isRetriableControllerError(err) {
 			_, _ = ca.refreshController()
 		}
@@ -326,9 +439,75 @@ func (ca *clusterAdmin) DescribeCluster() (brokers []*Broker, controllerID int32
 		return nil, int32(0), err
 	}
 
+	return brokers, controllerID, nil
+}
+
+func (ca *clusterAdmin) describeCluster(b *Broker) ([]*Broker, int32, error) {
+	response, err := b.DescribeCluster(NewDescribeClusterRequest(ca.conf.Version))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if !errors.Is(response.ErrorCode, ErrNoError) {
+		if response.ErrorMessage != nil && len(*response.ErrorMessage) > 0 {
+			return nil, 0, fmt.Errorf("%w - %s", response.ErrorCode, *response.ErrorMessage)
+		}
+		return nil, 0, response.ErrorCode
+	}
+
 	return response.Brokers, response.ControllerID, nil
 }
 
+func (ca *clusterAdmin) DescribeQuorum() (*QuorumInfo, error) {
+	request := NewDescribeQuorumRequest(ca.conf.Version)
+
+	var partition *DescribeQuorumPartitionData
+	err := ca.retryOnError(isRetriableControllerError, func() error {
+		partition = nil
+
+		b, err := ca.findAnyBroker()
+		if err != nil {
+			return err
+		}
+		_ = b.Open(ca.client.Config())
+
+		rsp, err := b.DescribeQuorum(request)
+		if err != nil {
+			return err
+		}
+
+		if !errors.Is(rsp.ErrorCode, ErrNoError) {
+			return rsp.ErrorCode
+		}
+
+		for i := range rsp.Topics {
+			topic := &rsp.Topics[i]
+			if topic.TopicName != clusterMetadataTopic {
+				continue
+			}
+			for j := range topic.Partitions {
+				if topic.Partitions[j].PartitionIndex == 0 {
+					partition = &topic.Partitions[j]
+				}
+			}
+		}
+		if partition == nil {
+			return ErrIncompleteResponse
+		}
+
+		if !errors.Is(partition.ErrorCode, ErrNoError) {
+			return partition.ErrorCode
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return newQuorumInfo(partition), nil
+}
+
 func (ca *clusterAdmin) findBroker(id int32) (*Broker, error) {
 	brokers := ca.client.Brokers()
 	for _, b := range brokers {
@@ -478,6 +657,68 @@ func (ca *clusterAdmin) DeleteTopic(topic string) error {
 	})
 }
 
+func (ca *clusterAdmin) DeleteTopics(topics []string) (map[string]error, error) {
+	if len(topics) == 0 {
+		return nil, errors.New("you must specify at least one topic")
+	}
+	pending := make(map[string]struct{}, len(topics))
+	for _, topic := range topics {
+		if topic == "" {
+			return nil, ErrInvalidTopic
+		}
+		pending[topic] = struct{}{}
+	}
+
+	results := make(map[string]error, len(topics))
+
+	err := ca.retryOnError(isRetriableControllerError, func() error {
+		b, err := ca.Controller()
+		if err != nil {
+			return err
+		}
+
+		request := NewDeleteTopicsRequest(ca.conf.Version, slices.Sorted(maps.Keys(pending)), ca.conf.Admin.Timeout)
+		rsp, err := b.DeleteTopics(request)
+		if err != nil {
+			return err
+		}
+
+		for topic := range pending {
+			topicErr, ok := rsp.TopicErrorCodes[topic]
+			if !ok {
+				results[topic] = ErrIncompleteResponse
+				delete(pending, topic)
+				continue
+			}
+			if errors.Is(topicErr, ErrNoError) {
+				results[topic] = nil
+			} else {
+				results[topic] = topicErr
+			}
+			// topics whose controller moved are retried, everything else is final
+			if !isRetriableControllerError(topicErr) {
+				delete(pending, topic)
+			}
+		}
+
+		if len(pending) > 0 {
+			_, _ = ca.refreshController()
+			return ErrNotController
+		}
+		return nil
+	})
+	if err != nil {
+		for topic := range pending {
+			if _, ok := results[topic]; !ok {
+				results[topic] = err
+			}
+		}
+		return results, err
+	}
+
+	return results, nil
+}
+
 func (ca *clusterAdmin) CreatePartitions(topic string, count int32, assignment [][]int32, validateOnly bool) error {
 	if topic == "" {
 		return ErrInvalidTopic
@@ -980,25 +1221,7 @@ func (ca *clusterAdmin) DescribeConsumerGroups(groups []string) (result []*Group
 	}
 
 	for broker, brokerGroups := range groupsPerBroker {
-		describeReq := &DescribeGroupsRequest{
-			Groups: brokerGroups,
-		}
-
-		if ca.conf.Version.IsAtLeast(V2_4_0_0) {
-			// Starting in version 4, the response will include group.instance.id info for members.
-			// Starting in version 5, the response uses flexible encoding
-			describeReq.Version = 5
-		} else if ca.conf.Version.IsAtLeast(V2_3_0_0) {
-			// Starting in version 3, authorized operations can be requested.
-			describeReq.Version = 3
-		} else if ca.conf.Version.IsAtLeast(V2_0_0_0) {
-			// Version 2 is the same as version 0.
-			describeReq.Version = 2
-		} else if ca.conf.Version.IsAtLeast(V1_1_0_0) {
-			// Version 1 is the same as version 0.
-			describeReq.Version = 1
-		}
-		response, err := broker.DescribeGroups(describeReq)
+		response, err := broker.DescribeGroups(ca.newDescribeGroupsRequest(brokerGroups))
 		if err != nil {
 			return nil, err
 		}
@@ -1008,6 +1231,89 @@ func (ca *clusterAdmin) DescribeConsumerGroups(groups []string) (result []*Group
 	return result, nil
 }
 
+func (ca *clusterAdmin) newDescribeGroupsRequest(groups []string) *DescribeGroupsRequest {
+	describeReq := &DescribeGroupsRequest{
+		Groups: groups,
+	}
+
+	if ca.conf.Version.IsAtLeast(V2_4_0_0) {
+		// Starting in version 4, the response will include group.instance.id info for members.
+		// Starting in version 5, the response uses flexible encoding
+		describeReq.Version = 5
+	} else if ca.conf.Version.IsAtLeast(V2_3_0_0) {
+		// Starting in version 3, authorized operations can be requested.
+		describeReq.Version = 3
+	} else if ca.conf.Version.IsAtLeast(V2_0_0_0) {
+		// Version 2 is the same as version 0.
+		describeReq.Version = 2
+	} else if ca.conf.Version.IsAtLeast(V1_1_0_0) {
+		// Version 1 is the same as version 0.
+		describeReq.Version = 1
+	}
+	return describeReq
+}
+
+func (ca *clusterAdmin) DescribeConsumerGroupDetails(groups []string) ([]*ConsumerGroupDescription, error) {
+	groupsPerBroker := make(map[*Broker][]string)
+
+	for _, group := range groups {
+		coordinator, err := ca.client.Coordinator(group)
+		if err != nil {
+			return nil, err
+		}
+		groupsPerBroker[coordinator] = append(groupsPerBroker[coordinator], group)
+	}
+
+	descriptions := make(map[string]*ConsumerGroupDescription, len(groups))
+	for broker, brokerGroups := range groupsPerBroker {
+		classicGroups := brokerGroups
+
+		if ca.conf.Version.IsAtLeast(V4_0_0_0) && broker.supportsAPI(apiKeyConsumerGroupDescribe) {
+			response, err := broker.ConsumerGroupDescribe(&ConsumerGroupDescribeRequest{GroupIds: brokerGroups})
+			if err != nil {
+				return nil, err
+			}
+			classicGroups = nil
+			for i := range response.Groups {
+				group := &response.Groups[i]
+				// the coordinator reports classic groups as not found
+				if errors.Is(group.ErrorCode, ErrGroupIDNotFound) {
+					classicGroups = append(classicGroups, group.GroupId)
+					continue
+				}
+				descriptions[group.GroupId] = newConsumerGroupDescription(group)
+			}
+		}
+
+		if len(classicGroups) == 0 {
+			continue
+		}
+		response, err := broker.DescribeGroups(ca.newDescribeGroupsRequest(classicGroups))
+		if err != nil {
+			return nil, err
+		}
+		for _, group := range response.Groups {
+			descriptions[group.GroupId] = newClassicConsumerGroupDescription(group)
+		}
+	}
+
+	// the results line up with groups, even if a coordinator left some out
+	result := make([]*ConsumerGroupDescription, 0, len(groups))
+	for _, group := range groups {
+		d, ok := descriptions[group]
+		if !ok {
+			d = &ConsumerGroupDescription{
+				GroupId:         group,
+				GroupEpoch:      -1,
+				AssignmentEpoch: -1,
+				Err:             ErrGroupIDNotFound,
+			}
+		}
+		result = append(result, d)
+	}
+	return result, nil
+}
+
 func (ca *clusterAdmin) ListConsumerGroups() (allGroups map[string]string, err error) {
 	allGroups = make(map[string]string)
 
@@ -1354,6 +1660,97 @@ func (ca *clusterAdmin) AlterClientQuotas(entity []QuotaEntityComponent, op Clie
 	return nil
 }
 
+func (ca *clusterAdmin) DescribeFeatures() (*FeatureMetadata, error) {
+	request := &ApiVersionsRequest{
+		Version:               3,
+		ClientSoftwareName:    defaultClientSoftwareName,
+		ClientSoftwareVersion: version(),
+	}
+
+	var rsp *ApiVersionsResponse
+	err := ca.retryOnError(isRetriableControllerError, func() error {
+		b, err := ca.Controller()
+		if err != nil {
+			return err
+		}
+
+		rsp, err = b.ApiVersions(request)
+		if isRetriableControllerError(err) {
+			_, _ = ca.refreshController()
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !errors.Is(KError(rsp.ErrorCode), ErrNoError) {
+		return nil, KError(rsp.ErrorCode)
+	}
+
+	return newFeatureMetadata(rsp), nil
+}
+
+func (ca *clusterAdmin) UpdateFeatures(updates map[string]FeatureUpdate, validateOnly bool) error {
+	if len(updates) == 0 {
+		return errors.New("you must specify at least one feature update")
+	}
+
+	features := slices.Sorted(maps.Keys(updates))
+	featureUpdates := make([]UpdateFeaturesRequestFeatureUpdate, 0, len(features))
+	for _, feature := range features {
+		update := updates[feature]
+		featureUpdates = append(featureUpdates, UpdateFeaturesRequestFeatureUpdate{
+			Feature:         feature,
+			MaxVersionLevel: update.MaxVersionLevel,
+			AllowDowngrade:  update.UpgradeType == FeatureUpgradeTypeSafeDowngrade || update.UpgradeType == FeatureUpgradeTypeUnsafeDowngrade,
+			UpgradeType:     update.UpgradeType,
+		})
+	}
+
+	request := NewUpdateFeaturesRequest(ca.conf.Version, featureUpdates, ca.conf.Admin.Timeout, validateOnly)
+
+	return ca.retryOnError(isRetriableControllerError, func() error {
+		b, err := ca.Controller()
+		if err != nil {
+			return err
+		}
+
+		rsp, err := b.UpdateFeatures(request)
+		if err != nil {
+			return err
+		}
+
+		if !errors.Is(rsp.ErrorCode, ErrNoError) {
+			if isRetriableControllerError(rsp.ErrorCode) {
+				_, _ = ca.refreshController()
+			}
+			if rsp.ErrorMessage != nil && len(*rsp.ErrorMessage) > 0 {
+				return fmt.Errorf("%w - %s", rsp.ErrorCode, *rsp.ErrorMessage)
+			}
+			return rsp.ErrorCode
+		}
+
+		errs := make([]error, 0)
+		for _, result := range rsp.Results {
+			if errors.Is(result.ErrorCode, ErrNoError) {
+				continue
+			}
+			if result.ErrorMessage != nil && len(*result.ErrorMessage) > 0 {
+				errs = append(errs, fmt.Errorf("[%s]: %w - %s", result.Feature, result.ErrorCode, *result.ErrorMessage))
+			} else {
+				errs = append(errs, fmt.Errorf("[%s]: %w", result.Feature, result.ErrorCode))
+			}
+		}
+
+		if len(errs) > 0 {
+			return Wrap(ErrUpdateFeatures, errs...)
+		}
+
+		return nil
+	})
+}
+
 func (ca *clusterAdmin) RemoveMemberFromConsumerGroup(group string, groupInstanceIds []string) (*LeaveGroupResponse, error) {
 	if !ca.conf.Version.IsAtLeast(V2_4_0_0) {
 		return nil, ConfigurationError("Removing members from a consumer group headers requires Kafka version of at least v2.4.0")
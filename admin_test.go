@@ -84,6 +84,104 @@ func TestClusterAdminCreateTopic(t *testing.T) {
 	}
 }
 
+func TestClusterAdminCreateTopics(t *testing.T) {
+	seedBroker := NewMockBroker(t, 1)
+	defer seedBroker.Close()
+
+	seedBroker.SetHandlerByMap(map[string]MockResponse{
+		"ApiVersionsRequest": NewMockApiVersionsResponse(t),
+		"MetadataRequest": NewMockMetadataResponse(t).
+			SetController(seedBroker.BrokerID()).
+			SetBroker(seedBroker.Addr(), seedBroker.BrokerID()),
+		"CreateTopicsRequest": NewMockCreateTopicsResponse(t).
+			SetError("existing_topic", ErrTopicAlreadyExists),
+	})
+
+	config := NewTestConfig()
+	config.Version = V2_4_0_0
+	admin, err := NewClusterAdmin([]string{seedBroker.Addr()}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer safeClose(t, admin)
+
+	retention := "3600000"
+	results, err := admin.CreateTopics(map[string]*TopicDetail{
+		"my_topic": {
+			NumPartitions:     3,
+			ReplicationFactor: 1,
+			ConfigEntries:     map[string]*string{"retention.ms": &retention},
+		},
+		"existing_topic": {NumPartitions: 1, ReplicationFactor: 1},
+	}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+
+	created := results["my_topic"]
+	if created.Err != nil {
+		t.Fatalf("unexpected error for my_topic: %v", created.Err)
+	}
+	if created.NumPartitions != 3 || created.ReplicationFactor != 1 {
+		t.Errorf("unexpected partitions/replication factor %d/%d", created.NumPartitions, created.ReplicationFactor)
+	}
+	if cfg, ok := created.Configs["retention.ms"]; !ok || cfg.Value == nil || *cfg.Value != retention {
+		t.Errorf("expected retention.ms config %s, got %+v", retention, cfg)
+	}
+
+	existing := results["existing_topic"]
+	if !errors.Is(existing.Err, ErrTopicAlreadyExists) {
+		t.Errorf("expected ErrTopicAlreadyExists for existing_topic, got %v", existing.Err)
+	}
+	if existing.NumPartitions != -1 || existing.ReplicationFactor != -1 {
+		t.Errorf("expected unknown partitions/replication factor, got %d/%d", existing.NumPartitions, existing.ReplicationFactor)
+	}
+}
+
+func TestClusterAdminCreateTopicsBeforeV5(t *testing.T) {
+	seedBroker := NewMockBroker(t, 1)
+	defer seedBroker.Close()
+
+	seedBroker.SetHandlerByMap(map[string]MockResponse{
+		"MetadataRequest": NewMockMetadataResponse(t).
+			SetController(seedBroker.BrokerID()).
+			SetBroker(seedBroker.Addr(), seedBroker.BrokerID()),
+		"CreateTopicsRequest": NewMockCreateTopicsResponse(t),
+	})
+
+	config := NewTestConfig()
+	config.Version = V0_10_2_0
+	admin, err := NewClusterAdmin([]string{seedBroker.Addr()}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer safeClose(t, admin)
+
+	results, err := admin.CreateTopics(map[string]*TopicDetail{
+		"my_topic":  {NumPartitions: 1, ReplicationFactor: 1},
+		"_reserved": {NumPartitions: 1, ReplicationFactor: 1},
+	}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results["my_topic"].Err != nil {
+		t.Errorf("unexpected error for my_topic: %v", results["my_topic"].Err)
+	}
+	if results["my_topic"].NumPartitions != -1 || results["my_topic"].Configs != nil {
+		t.Errorf("expected no topic details before v5, got %+v", results["my_topic"])
+	}
+	if !errors.Is(results["_reserved"].Err, ErrTopicAuthorizationFailed) {
+		t.Errorf("expected ErrTopicAuthorizationFailed for _reserved, got %v", results["_reserved"].Err)
+	}
+
+	if _, err := admin.CreateTopics(nil, false); err == nil {
+		t.Error("expected error when no topics are given")
+	}
+}
+
 func TestClusterAdminCreateTopicWithInvalidTopicDetail(t *testing.T) {
 	seedBroker := NewMockBroker(t, 1)
 	defer seedBroker.Close()
@@ -222,6 +320,97 @@ func TestClusterAdminDeleteTopic(t *testing.T) {
 	}
 }
 
+func TestClusterAdminDeleteTopics(t *testing.T) {
+	seedBroker := NewMockBroker(t, 1)
+	defer seedBroker.Close()
+
+	seedBroker.SetHandlerByMap(map[string]MockResponse{
+		"MetadataRequest": NewMockMetadataResponse(t).
+			SetController(seedBroker.BrokerID()).
+			SetBroker(seedBroker.Addr(), seedBroker.BrokerID()),
+		"DeleteTopicsRequest": NewMockDeleteTopicsResponse(t),
+	})
+
+	config := NewTestConfig()
+	config.Version = V0_10_2_0
+	admin, err := NewClusterAdmin([]string{seedBroker.Addr()}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer safeClose(t, admin)
+
+	results, err := admin.DeleteTopics([]string{"topic_a", "topic_b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	for topic, topicErr := range results {
+		if topicErr != nil {
+			t.Errorf("unexpected error for %s: %v", topic, topicErr)
+		}
+	}
+
+	seedBroker.SetHandlerByMap(map[string]MockResponse{
+		"MetadataRequest": NewMockMetadataResponse(t).
+			SetController(seedBroker.BrokerID()).
+			SetBroker(seedBroker.Addr(), seedBroker.BrokerID()),
+		"DeleteTopicsRequest": NewMockDeleteTopicsResponse(t).SetError(ErrUnknownTopicOrPartition),
+	})
+
+	results, err = admin.DeleteTopics([]string{"missing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !errors.Is(results["missing"], ErrUnknownTopicOrPartition) {
+		t.Errorf("expected ErrUnknownTopicOrPartition, got %v", results["missing"])
+	}
+}
+
+func TestClusterAdminTopicsControllerRetriesExhausted(t *testing.T) {
+	seedBroker := NewMockBroker(t, 1)
+	defer seedBroker.Close()
+
+	seedBroker.SetHandlerByMap(map[string]MockResponse{
+		"ApiVersionsRequest": NewMockApiVersionsResponse(t),
+		"MetadataRequest": NewMockMetadataResponse(t).
+			SetController(seedBroker.BrokerID()).
+			SetBroker(seedBroker.Addr(), seedBroker.BrokerID()),
+		"CreateTopicsRequest": NewMockCreateTopicsResponse(t).
+			SetError("my_topic", ErrNotController),
+		"DeleteTopicsRequest": NewMockDeleteTopicsResponse(t).SetError(ErrNotController),
+	})
+
+	config := NewTestConfig()
+	config.Version = V2_4_0_0
+	config.Admin.Retry.Max = 2
+	config.Admin.Retry.Backoff = 0
+	admin, err := NewClusterAdmin([]string{seedBroker.Addr()}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer safeClose(t, admin)
+
+	created, err := admin.CreateTopics(map[string]*TopicDetail{
+		"my_topic": {NumPartitions: 1, ReplicationFactor: 1},
+	}, false)
+	if !errors.Is(err, ErrNotController) {
+		t.Errorf("expected ErrNotController from CreateTopics, got %v", err)
+	}
+	if result, ok := created["my_topic"]; !ok || !errors.Is(result.Err, ErrNotController) {
+		t.Errorf("expected ErrNotController result for my_topic, got %+v", result)
+	}
+
+	deleted, err := admin.DeleteTopics([]string{"my_topic"})
+	if !errors.Is(err, ErrNotController) {
+		t.Errorf("expected ErrNotController from DeleteTopics, got %v", err)
+	}
+	if !errors.Is(deleted["my_topic"], ErrNotController) {
+		t.Errorf("expected ErrNotController result for my_topic, got %v", deleted["my_topic"])
+	}
+}
+
 func TestClusterAdminDeleteEmptyTopic(t *testing.T) {
 	seedBroker := NewMockBroker(t, 1)
 	defer seedBroker.Close()
@@ -1488,6 +1677,231 @@ func TestDescribeConsumerGroup(t *testing.T) {
 	}
 }
 
+func TestDescribeConsumerGroupDetails(t *testing.T) {
+	seedBroker := NewMockBroker(t, 1)
+	defer seedBroker.Close()
+
+	instanceID := "instance-1"
+	metadata, err := encode(&ConsumerGroupMemberMetadata{
+		Version:         1,
+		Topics:          []string{"my-topic"},
+		UserData:        []byte{0x01},
+		OwnedPartitions: []*OwnedPartition{{Topic: "my-topic", Partitions: []int32{0}}},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assignment, err := encode(&ConsumerGroupMemberAssignment{
+		Topics: map[string][]int32{"my-topic": {0, 1}},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seedBroker.SetHandlerByMap(map[string]MockResponse{
+		"DescribeGroupsRequest": NewMockDescribeGroupsResponse(t).
+			AddGroupDescription("my-group", &GroupDescription{
+				GroupId:      "my-group",
+				State:        "Stable",
+				ProtocolType: ConsumerProtocolType,
+				Protocol:     "range",
+				Members: map[string]*GroupMemberDescription{
+					"member-1": {
+						MemberId:         "member-1",
+						GroupInstanceId:  &instanceID,
+						ClientId:         "client-1",
+						MemberMetadata:   metadata,
+						MemberAssignment: assignment,
+					},
+				},
+			}).
+			AddGroupDescription("connect-cluster", &GroupDescription{
+				GroupId:      "connect-cluster",
+				State:        "Stable",
+				ProtocolType: "connect",
+				Members: map[string]*GroupMemberDescription{
+					"worker-1": {MemberId: "worker-1"},
+				},
+			}),
+		"MetadataRequest": NewMockMetadataResponse(t).
+			SetController(seedBroker.BrokerID()).
+			SetBroker(seedBroker.Addr(), seedBroker.BrokerID()),
+		"FindCoordinatorRequest": NewMockFindCoordinatorResponse(t).
+			SetCoordinator(CoordinatorGroup, "my-group", seedBroker).
+			SetCoordinator(CoordinatorGroup, "connect-cluster", seedBroker),
+	})
+
+	config := NewTestConfig()
+	config.Version = V2_4_0_0
+	admin, err := NewClusterAdmin([]string{seedBroker.Addr()}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer safeClose(t, admin)
+
+	result, err := admin.DescribeConsumerGroupDetails([]string{"my-group", "connect-cluster"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 2 {
+		t.Fatalf("Expected 2 results, got %v", len(result))
+	}
+
+	group := result[0]
+	if group.Err != nil {
+		t.Fatal(group.Err)
+	}
+	if group.Type != ConsumerGroupTypeClassic || group.Assignor != "range" || group.GroupEpoch != -1 {
+		t.Errorf("unexpected group description %+v", group)
+	}
+	member := group.Members["member-1"]
+	if member == nil {
+		t.Fatal("expected member-1 to be described")
+	}
+	if member.GroupInstanceId == nil || *member.GroupInstanceId != instanceID {
+		t.Errorf("expected group instance id %s, got %v", instanceID, member.GroupInstanceId)
+	}
+	if len(member.Topics) != 1 || member.Topics[0] != "my-topic" {
+		t.Errorf("unexpected subscription %v", member.Topics)
+	}
+	if len(member.OwnedPartitions["my-topic"]) != 1 || len(member.UserData) != 1 {
+		t.Errorf("unexpected owned partitions %v or user data %v", member.OwnedPartitions, member.UserData)
+	}
+	if len(member.Assignment["my-topic"]) != 2 {
+		t.Errorf("unexpected assignment %v", member.Assignment)
+	}
+
+	if !errors.Is(result[1].Err, ErrNotConsumerProtocolGroup) {
+		t.Errorf("expected ErrNotConsumerProtocolGroup, got %v", result[1].Err)
+	}
+}
+
+func TestDescribeConsumerGroupDetailsMissingGroup(t *testing.T) {
+	seedBroker := NewMockBroker(t, 1)
+	defer seedBroker.Close()
+
+	seedBroker.SetHandlerByMap(map[string]MockResponse{
+		"DescribeGroupsRequest": NewMockWrapper(&DescribeGroupsResponse{
+			Version: 5,
+			Groups: []*GroupDescription{{
+				Version:      5,
+				GroupId:      "my-group",
+				State:        "Empty",
+				ProtocolType: ConsumerProtocolType,
+			}},
+		}),
+		"MetadataRequest": NewMockMetadataResponse(t).
+			SetController(seedBroker.BrokerID()).
+			SetBroker(seedBroker.Addr(), seedBroker.BrokerID()),
+		"FindCoordinatorRequest": NewMockFindCoordinatorResponse(t).
+			SetCoordinator(CoordinatorGroup, "missing-group", seedBroker).
+			SetCoordinator(CoordinatorGroup, "my-group", seedBroker),
+	})
+
+	config := NewTestConfig()
+	config.Version = V2_4_0_0
+	admin, err := NewClusterAdmin([]string{seedBroker.Addr()}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer safeClose(t, admin)
+
+	result, err := admin.DescribeConsumerGroupDetails([]string{"missing-group", "my-group"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 2 {
+		t.Fatalf("Expected 2 results, got %v", len(result))
+	}
+	if result[0].GroupId != "missing-group" || !errors.Is(result[0].Err, ErrGroupIDNotFound) {
+		t.Errorf("expected missing-group to be reported as not found, got %+v", result[0])
+	}
+	if result[1].GroupId != "my-group" || result[1].Err != nil || result[1].State != "Empty" {
+		t.Errorf("expected my-group to be described, got %+v", result[1])
+	}
+}
+
+func TestDescribeConsumerGroupDetailsConsumerProtocol(t *testing.T) {
+	seedBroker := NewMockBroker(t, 1)
+	defer seedBroker.Close()
+
+	seedBroker.SetHandlerByMap(map[string]MockResponse{
+		"ApiVersionsRequest": NewMockApiVersionsResponse(t).SetApiKeys([]ApiVersionsResponseKey{
+			{ApiKey: apiKeyMetadata, MinVersion: 0, MaxVersion: 12},
+			{ApiKey: apiKeyFindCoordinator, MinVersion: 0, MaxVersion: 4},
+			{ApiKey: apiKeyDescribeGroups, MinVersion: 0, MaxVersion: 5},
+			{ApiKey: apiKeyConsumerGroupDescribe, MinVersion: 0, MaxVersion: 0},
+		}),
+		"ConsumerGroupDescribeRequest": NewMockConsumerGroupDescribeResponse(t).
+			AddGroup(&ConsumerGroupDescribeGroup{
+				GroupId:         "new-group",
+				GroupState:      "Stable",
+				GroupEpoch:      4,
+				AssignmentEpoch: 4,
+				AssignorName:    "uniform",
+				Members: []ConsumerGroupDescribeMember{{
+					MemberId:             "member-1",
+					MemberEpoch:          4,
+					SubscribedTopicNames: []string{"my-topic"},
+					Assignment: ConsumerGroupDescribeAssignment{
+						TopicPartitions: []ConsumerGroupDescribeTopicPartitions{{TopicName: "my-topic", Partitions: []int32{0}}},
+					},
+					TargetAssignment: ConsumerGroupDescribeAssignment{
+						TopicPartitions: []ConsumerGroupDescribeTopicPartitions{{TopicName: "my-topic", Partitions: []int32{0, 1}}},
+					},
+				}},
+			}),
+		"DescribeGroupsRequest": NewMockDescribeGroupsResponse(t).
+			AddGroupDescription("old-group", &GroupDescription{
+				GroupId:      "old-group",
+				State:        "Empty",
+				ProtocolType: ConsumerProtocolType,
+			}),
+		"MetadataRequest": NewMockMetadataResponse(t).
+			SetController(seedBroker.BrokerID()).
+			SetBroker(seedBroker.Addr(), seedBroker.BrokerID()),
+		"FindCoordinatorRequest": NewMockFindCoordinatorResponse(t).
+			SetCoordinator(CoordinatorGroup, "new-group", seedBroker).
+			SetCoordinator(CoordinatorGroup, "old-group", seedBroker),
+	})
+
+	config := NewTestConfig()
+	config.Version = V4_0_0_0
+	config.ApiVersionsRequest = true
+	admin, err := NewClusterAdmin([]string{seedBroker.Addr()}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer safeClose(t, admin)
+
+	result, err := admin.DescribeConsumerGroupDetails([]string{"new-group", "old-group"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 2 {
+		t.Fatalf("Expected 2 results, got %v", len(result))
+	}
+
+	group := result[0]
+	if group.Err != nil {
+		t.Fatal(group.Err)
+	}
+	if group.Type != ConsumerGroupTypeConsumer || group.GroupEpoch != 4 || group.Assignor != "uniform" {
+		t.Errorf("unexpected group description %+v", group)
+	}
+	member := group.Members["member-1"]
+	if member == nil || member.MemberEpoch != 4 {
+		t.Fatalf("unexpected member %+v", member)
+	}
+	if len(member.Assignment["my-topic"]) != 1 || len(member.TargetAssignment["my-topic"]) != 2 {
+		t.Errorf("unexpected assignment %v / target %v", member.Assignment, member.TargetAssignment)
+	}
+
+	if result[1].Type != ConsumerGroupTypeClassic || result[1].State != "Empty" || result[1].Err != nil {
+		t.Errorf("expected old-group to be described with DescribeGroups, got %+v", result[1])
+	}
+}
+
 func TestListConsumerGroups(t *testing.T) {
 	seedBroker := NewMockBroker(t, 1)
 	defer seedBroker.Close()
@@ -1872,6 +2286,183 @@ func TestDescribeLogDirsUnknownBroker(t *testing.T) {
 	}
 }
 
+func TestClusterAdminDescribeFeatures(t *testing.T) {
+	seedBroker := NewMockBroker(t, 1)
+	defer seedBroker.Close()
+
+	seedBroker.SetHandlerByMap(map[string]MockResponse{
+		"MetadataRequest": NewMockMetadataResponse(t).
+			SetController(seedBroker.BrokerID()).
+			SetBroker(seedBroker.Addr(), seedBroker.BrokerID()),
+		"ApiVersionsRequest": NewMockApiVersionsResponse(t).
+			SetSupportedFeatures([]ApiVersionsResponseSupportedFeatureKey{
+				{Name: MetadataVersionFeature, MinVersion: 1, MaxVersion: 20},
+			}).
+			SetFinalizedFeatures(3, []ApiVersionsResponseFinalizedFeatureKey{
+				{Name: MetadataVersionFeature, MinVersionLevel: 1, MaxVersionLevel: 14},
+			}),
+	})
+
+	config := NewTestConfig()
+	config.Version = V3_3_0_0
+	admin, err := NewClusterAdmin([]string{seedBroker.Addr()}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	features, err := admin.DescribeFeatures()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if features.FinalizedFeaturesEpoch != 3 {
+		t.Errorf("Expected finalized features epoch 3, got %d", features.FinalizedFeaturesEpoch)
+	}
+	if level := features.FinalizedFeatures[MetadataVersionFeature].MaxVersionLevel; level != 14 {
+		t.Errorf("Expected metadata.version level 14, got %d", level)
+	}
+	if maxVersion := features.SupportedFeatures[MetadataVersionFeature].MaxVersion; maxVersion != 20 {
+		t.Errorf("Expected metadata.version max supported version 20, got %d", maxVersion)
+	}
+
+	err = admin.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClusterAdminUpdateFeatures(t *testing.T) {
+	seedBroker := NewMockBroker(t, 1)
+	defer seedBroker.Close()
+
+	seedBroker.SetHandlerByMap(map[string]MockResponse{
+		"MetadataRequest": NewMockMetadataResponse(t).
+			SetController(seedBroker.BrokerID()).
+			SetBroker(seedBroker.Addr(), seedBroker.BrokerID()),
+		"UpdateFeaturesRequest": NewMockUpdateFeaturesResponse(t).
+			SetFeatureError("unknown.feature", ErrInvalidRequest),
+	})
+
+	config := NewTestConfig()
+	config.Version = V3_3_0_0
+	admin, err := NewClusterAdmin([]string{seedBroker.Addr()}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = admin.UpdateFeatures(map[string]FeatureUpdate{
+		MetadataVersionFeature: {MaxVersionLevel: 15, UpgradeType: FeatureUpgradeTypeUpgrade},
+	}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = admin.UpdateFeatures(map[string]FeatureUpdate{
+		MetadataVersionFeature: {MaxVersionLevel: 15, UpgradeType: FeatureUpgradeTypeUpgrade},
+		"unknown.feature":      {MaxVersionLevel: 1, UpgradeType: FeatureUpgradeTypeUpgrade},
+	}, false)
+	if !errors.Is(err, ErrUpdateFeatures) || !errors.Is(err, ErrInvalidRequest) {
+		t.Fatalf("Expected ErrUpdateFeatures wrapping ErrInvalidRequest, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "unknown.feature") {
+		t.Errorf("Expected error to name the failing feature, got %v", err)
+	}
+
+	err = admin.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClusterAdminDescribeClusterUsesDescribeClusterAPI(t *testing.T) {
+	seedBroker := NewMockBroker(t, 1)
+	defer seedBroker.Close()
+
+	seedBroker.SetHandlerByMap(map[string]MockResponse{
+		"ApiVersionsRequest": NewMockApiVersionsResponse(t).
+			SetApiKeys([]ApiVersionsResponseKey{
+				{ApiKey: apiKeyMetadata, MinVersion: 0, MaxVersion: 12},
+				{ApiKey: apiKeyDescribeCluster, MinVersion: 0, MaxVersion: 1},
+			}),
+		"MetadataRequest": NewMockMetadataResponse(t).
+			SetController(seedBroker.BrokerID()).
+			SetBroker(seedBroker.Addr(), seedBroker.BrokerID()),
+		"DescribeClusterRequest": NewMockDescribeClusterResponse(t).
+			SetClusterID("my-cluster").
+			SetController(seedBroker.BrokerID()).
+			SetBroker(seedBroker.Addr(), seedBroker.BrokerID()).
+			SetBroker("other:9092", 2),
+	})
+
+	config := NewTestConfig()
+	config.ApiVersionsRequest = true
+	config.Version = V3_7_0_0
+	admin, err := NewClusterAdmin([]string{seedBroker.Addr()}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	brokers, controllerID, err := admin.DescribeCluster()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if controllerID != seedBroker.BrokerID() {
+		t.Errorf("Expected controller ID %d, got %d", seedBroker.BrokerID(), controllerID)
+	}
+	if len(brokers) != 2 {
+		t.Fatalf("Expected 2 brokers from the DescribeCluster API, got %d", len(brokers))
+	}
+	if brokers[1].ID() != 2 || brokers[1].Addr() != "other:9092" {
+		t.Errorf("Unexpected broker %d at %s", brokers[1].ID(), brokers[1].Addr())
+	}
+
+	err = admin.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClusterAdminDescribeQuorum(t *testing.T) {
+	seedBroker := NewMockBroker(t, 1)
+	defer seedBroker.Close()
+
+	seedBroker.SetHandlerByMap(map[string]MockResponse{
+		"MetadataRequest": NewMockMetadataResponse(t).
+			SetController(seedBroker.BrokerID()).
+			SetBroker(seedBroker.Addr(), seedBroker.BrokerID()),
+		"DescribeQuorumRequest": NewMockDescribeQuorumResponse(t).
+			SetLeader(3000, 12, 500).
+			AddVoter(3000, 520).
+			AddVoter(3001, 510).
+			AddObserver(seedBroker.BrokerID(), 400),
+	})
+
+	config := NewTestConfig()
+	config.Version = V3_3_0_0
+	admin, err := NewClusterAdmin([]string{seedBroker.Addr()}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	quorum, err := admin.DescribeQuorum()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quorum.LeaderID != 3000 || quorum.LeaderEpoch != 12 || quorum.HighWatermark != 500 {
+		t.Errorf("Unexpected quorum leader state %+v", quorum)
+	}
+	if len(quorum.Voters) != 2 || quorum.Voters[1].Lag != 10 {
+		t.Errorf("Unexpected voters %+v", quorum.Voters)
+	}
+	if len(quorum.Observers) != 1 || quorum.Observers[0].Lag != 120 {
+		t.Errorf("Unexpected observers %+v", quorum.Observers)
+	}
+
+	err = admin.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
 func Test_retryOnError(t *testing.T) {
 	testBackoffTime := 100 * time.Millisecond
 	config := NewTestConfig()
@@ -81,4 +81,10 @@ const (
 	apiKeyAlterClientQuotas            = 49
 	apiKeyDescribeUserScramCredentials = 50
 	apiKeyAlterUserScramCredentials    = 51
+	apiKeyDescribeQuorum               = 55
+	apiKeyUpdateFeatures               = 57
+	apiKeyDescribeCluster              = 60
+	apiKeyConsumerGroupDescribe        = 69
+	apiKeyGetTelemetrySubscriptions    = 71
+	apiKeyPushTelemetry                = 72
 )
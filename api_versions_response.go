@@ -49,6 +49,74 @@ func (a *ApiVersionsResponseKey) decode(pd packetDecoder, version int16) (err er
 	return err
 }
 
+// ApiVersionsResponseSupportedFeatureKey contains a feature supported by the broker.
+type ApiVersionsResponseSupportedFeatureKey struct {
+	// Name contains the name of the feature.
+	Name string
+	// MinVersion contains the minimum supported version for the feature.
+	MinVersion int16
+	// MaxVersion contains the maximum supported version for the feature.
+	MaxVersion int16
+}
+
+func (f *ApiVersionsResponseSupportedFeatureKey) encode(pe packetEncoder) (err error) {
+	if err := pe.putString(f.Name); err != nil {
+		return err
+	}
+	pe.putInt16(f.MinVersion)
+	pe.putInt16(f.MaxVersion)
+	pe.putEmptyTaggedFieldArray()
+	return nil
+}
+
+func (f *ApiVersionsResponseSupportedFeatureKey) decode(pd packetDecoder) (err error) {
+	if f.Name, err = pd.getString(); err != nil {
+		return err
+	}
+	if f.MinVersion, err = pd.getInt16(); err != nil {
+		return err
+	}
+	if f.MaxVersion, err = pd.getInt16(); err != nil {
+		return err
+	}
+	_, err = pd.getEmptyTaggedFieldArray()
+	return err
+}
+
+// ApiVersionsResponseFinalizedFeatureKey contains a cluster-wide finalized feature.
+type ApiVersionsResponseFinalizedFeatureKey struct {
+	// Name contains the name of the feature.
+	Name string
+	// MaxVersionLevel contains the cluster-wide finalized max version level for the feature.
+	MaxVersionLevel int16
+	// MinVersionLevel contains the cluster-wide finalized min version level for the feature.
+	MinVersionLevel int16
+}
+
+func (f *ApiVersionsResponseFinalizedFeatureKey) encode(pe packetEncoder) (err error) {
+	if err := pe.putString(f.Name); err != nil {
+		return err
+	}
+	pe.putInt16(f.MaxVersionLevel)
+	pe.putInt16(f.MinVersionLevel)
+	pe.putEmptyTaggedFieldArray()
+	return nil
+}
+
+func (f *ApiVersionsResponseFinalizedFeatureKey) decode(pd packetDecoder) (err error) {
+	if f.Name, err = pd.getString(); err != nil {
+		return err
+	}
+	if f.MaxVersionLevel, err = pd.getInt16(); err != nil {
+		return err
+	}
+	if f.MinVersionLevel, err = pd.getInt16(); err != nil {
+		return err
+	}
+	_, err = pd.getEmptyTaggedFieldArray()
+	return err
+}
+
 type ApiVersionsResponse struct {
 	// Version defines the protocol version to use for encode and decode
 	Version int16
@@ -58,6 +126,16 @@ type ApiVersionsResponse struct {
 	ApiKeys []ApiVersionsResponseKey
 	// ThrottleTimeMs contains the duration in milliseconds for which the request was throttled due to a quota violation, or zero if the request did not violate any quota.
 	ThrottleTimeMs int32
+	// SupportedFeatures contains the features supported by the broker (tagged field, v3+).
+	SupportedFeatures []ApiVersionsResponseSupportedFeatureKey
+	// FinalizedFeaturesEpoch contains the monotonically increasing epoch for the
+	// finalized features information, or -1 if unknown (tagged field, v3+).
+	FinalizedFeaturesEpoch int64
+	// FinalizedFeatures contains the cluster-wide finalized features (tagged field, v3+).
+	FinalizedFeatures []ApiVersionsResponseFinalizedFeatureKey
+	// ZkMigrationReady is set by a KRaft controller if the required configurations
+	// for ZK migration are present (tagged field, v3+).
+	ZkMigrationReady bool
 }
 
 func (r *ApiVersionsResponse) setVersion(v int16) {
@@ -81,12 +159,55 @@ func (r *ApiVersionsResponse) encode(pe packetEncoder) (err error) {
 	}
 
 	if r.Version >= 3 {
-		pe.putEmptyTaggedFieldArray()
+		return putTaggedFields(pe, r.taggedFields())
 	}
 
 	return nil
 }
 
+func (r *ApiVersionsResponse) taggedFields() taggedFieldEncoders {
+	fields := taggedFieldEncoders{}
+	if len(r.SupportedFeatures) > 0 {
+		fields[0] = func(pe packetEncoder) error {
+			if err := pe.putArrayLength(len(r.SupportedFeatures)); err != nil {
+				return err
+			}
+			for i := range r.SupportedFeatures {
+				if err := r.SupportedFeatures[i].encode(pe); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+	if r.FinalizedFeaturesEpoch >= 0 {
+		fields[1] = func(pe packetEncoder) error {
+			pe.putInt64(r.FinalizedFeaturesEpoch)
+			return nil
+		}
+	}
+	if len(r.FinalizedFeatures) > 0 {
+		fields[2] = func(pe packetEncoder) error {
+			if err := pe.putArrayLength(len(r.FinalizedFeatures)); err != nil {
+				return err
+			}
+			for i := range r.FinalizedFeatures {
+				if err := r.FinalizedFeatures[i].encode(pe); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+	if r.ZkMigrationReady {
+		fields[3] = func(pe packetEncoder) error {
+			pe.putBool(r.ZkMigrationReady)
+			return nil
+		}
+	}
+	return fields
+}
+
 func (r *ApiVersionsResponse) decode(pd packetDecoder, version int16) (err error) {
 	r.Version = version
 	if r.ErrorCode, err = pd.getInt16(); err != nil {
@@ -121,8 +242,47 @@ func (r *ApiVersionsResponse) decode(pd packetDecoder, version int16) (err error
 		}
 	}
 
-	_, err = pd.getEmptyTaggedFieldArray()
-	return err
+	r.FinalizedFeaturesEpoch = -1
+	if r.Version < 3 {
+		return nil
+	}
+
+	return pd.getTaggedFieldArray(taggedFieldDecoders{
+		0: func(pd packetDecoder) error {
+			n, err := pd.getArrayLength()
+			if err != nil {
+				return err
+			}
+			r.SupportedFeatures = make([]ApiVersionsResponseSupportedFeatureKey, n)
+			for i := 0; i < n; i++ {
+				if err := r.SupportedFeatures[i].decode(pd); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		1: func(pd packetDecoder) (err error) {
+			r.FinalizedFeaturesEpoch, err = pd.getInt64()
+			return err
+		},
+		2: func(pd packetDecoder) error {
+			n, err := pd.getArrayLength()
+			if err != nil {
+				return err
+			}
+			r.FinalizedFeatures = make([]ApiVersionsResponseFinalizedFeatureKey, n)
+			for i := 0; i < n; i++ {
+				if err := r.FinalizedFeatures[i].decode(pd); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		3: func(pd packetDecoder) (err error) {
+			r.ZkMigrationReady, err = pd.getBool()
+			return err
+		},
+	})
 }
 
 func (r *ApiVersionsResponse) key() int16 {
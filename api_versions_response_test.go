@@ -48,6 +48,29 @@ var (
 		0x00, // empty tagged fields
 	}
 
+	apiVersionResponseV3WithFeatures = []byte{
+		0x00, 0x00, // no error
+		0x02,                               // compact array length 1 (APIs)
+		0x00, 0x12, 0x00, 0x00, 0x00, 0x03, // API Version ApiVersions (v0-3)
+		0x00,                   // empty tagged fields
+		0x00, 0x00, 0x00, 0x00, // throttle time (0ms)
+		0x03,       // 3 tagged fields
+		0x00, 0x17, // tag 0 (SupportedFeatures), length 23
+		0x02, // compact array length 1
+		0x11, 'm', 'e', 't', 'a', 'd', 'a', 't', 'a', '.', 'v', 'e', 'r', 's', 'i', 'o', 'n',
+		0x00, 0x01, // MinVersion
+		0x00, 0x14, // MaxVersion
+		0x00,       // empty tagged fields
+		0x01, 0x08, // tag 1 (FinalizedFeaturesEpoch), length 8
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x05,
+		0x02, 0x17, // tag 2 (FinalizedFeatures), length 23
+		0x02, // compact array length 1
+		0x11, 'm', 'e', 't', 'a', 'd', 'a', 't', 'a', '.', 'v', 'e', 'r', 's', 'i', 'o', 'n',
+		0x00, 0x11, // MaxVersionLevel
+		0x00, 0x01, // MinVersionLevel
+		0x00, // empty tagged fields
+	}
+
 	// unsupported version from kafka 0.10.2.1
 	apiVersionsResponseUnsupportedVersionV0 = []byte{
 		0x00, 0x23, // unsupported version error
@@ -124,6 +147,49 @@ func TestApiVersionsResponseV3(t *testing.T) {
 	assert.Equal(t, int32(128), response.ThrottleTimeMs)
 }
 
+func TestApiVersionsResponseV3Features(t *testing.T) {
+	response := &ApiVersionsResponse{
+		Version: 3,
+		ApiKeys: []ApiVersionsResponseKey{
+			{Version: 3, ApiKey: apiKeyApiVersions, MinVersion: 0, MaxVersion: 3},
+		},
+		SupportedFeatures: []ApiVersionsResponseSupportedFeatureKey{
+			{Name: MetadataVersionFeature, MinVersion: 1, MaxVersion: 20},
+		},
+		FinalizedFeaturesEpoch: 5,
+		FinalizedFeatures: []ApiVersionsResponseFinalizedFeatureKey{
+			{Name: MetadataVersionFeature, MaxVersionLevel: 17, MinVersionLevel: 1},
+		},
+	}
+	testResponse(t, "features V3", response, apiVersionResponseV3WithFeatures)
+
+	fm := newFeatureMetadata(response)
+	assert.Equal(t, int64(5), fm.FinalizedFeaturesEpoch)
+	assert.Equal(t, FinalizedFeatureVersionRange{MinVersionLevel: 1, MaxVersionLevel: 17}, fm.FinalizedFeatures[MetadataVersionFeature])
+	assert.Equal(t, SupportedFeatureVersionRange{MinVersion: 1, MaxVersion: 20}, fm.SupportedFeatures[MetadataVersionFeature])
+}
+
+func TestApiVersionsResponseV3WithoutFeatures(t *testing.T) {
+	response := new(ApiVersionsResponse)
+	testVersionDecodable(t, "no features V3", response, apiVersionResponseV3, 3)
+	assert.Equal(t, int64(-1), response.FinalizedFeaturesEpoch)
+	assert.Empty(t, response.SupportedFeatures)
+	assert.Empty(t, response.FinalizedFeatures)
+}
+
+func TestApiVersionsResponseDefaultFeaturesEpoch(t *testing.T) {
+	response := allocateResponseBody(apiKeyApiVersions, 3).(*ApiVersionsResponse)
+	assert.Equal(t, int64(-1), response.FinalizedFeaturesEpoch)
+
+	// without finalized features epoch, no tag 1 is encoded
+	testEncodable(t, "default V3", response, []byte{
+		0x00, 0x00, // no error
+		0x01,                   // compact array length 0 (APIs)
+		0x00, 0x00, 0x00, 0x00, // throttle time
+		0x00, // empty tagged fields
+	})
+}
+
 func TestApiVersionsResponseUnsupportedVersion(t *testing.T) {
 	t.Run("V0", func(t *testing.T) {
 		response := new(ApiVersionsResponse)
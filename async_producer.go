@@ -101,6 +101,7 @@ type asyncProducer struct {
 	txLock sync.Mutex
 
 	metricsRegistry metrics.Registry
+	log             *logger
 }
 
 // NewAsyncProducer creates a new AsyncProducer using the given broker addresses and configuration.
@@ -142,12 +143,22 @@ func newAsyncProducer(client Client) (AsyncProducer, error) {
 		brokers:         make(map[*Broker]*brokerProducer),
 		brokerRefs:      make(map[*brokerProducer]int),
 		txnmgr:          txnmgr,
-		metricsRegistry: newCleanupRegistry(client.Config().MetricRegistry),
+		metricsRegistry: newMetricRegistry(client.Config()),
+		log:             newLogger(client.Config()),
+	}
+
+	// register the retry buffer metrics before the retry handler starts
+	retryMetrics := &retryBufferMetrics{
+		length: getOrRegisterGauge("retry-buffer-length", p.metricsRegistry),
+		bytes:  getOrRegisterGauge("retry-buffer-bytes", p.metricsRegistry),
+	}
+	if p.conf.Producer.Retry.MaxBufferBytes > 0 {
+		retryMetrics.availableBytes = getOrRegisterGauge("buffer-available-bytes", p.metricsRegistry)
 	}
 
 	// launch our singleton dispatchers
 	go withRecover(p.dispatcher)
-	go withRecover(p.retryHandler)
+	go withRecover(func() { p.retryHandler(retryMetrics) })
 
 	return p, nil
 }
@@ -207,6 +218,7 @@ type ProducerMessage struct {
 
 	retries        int
 	flags          flagSet
+	enqueued       time.Time // when the dispatcher first read the message
 	expectation    chan *ProducerError
 	sequenceNumber int32
 	producerEpoch  int16
@@ -287,11 +299,11 @@ func (p *asyncProducer) AddOffsetsToTxn(offsets map[string][]*PartitionOffsetMet
 	defer p.txLock.Unlock()
 
 	if !p.IsTransactional() {
-		DebugLogger.Printf("producer/txnmgr [%s] attempt to call AddOffsetsToTxn on a non-transactional producer\n", p.txnmgr.transactionalID)
+		p.txnmgr.log.Debug("producer/txnmgr attempt to call AddOffsetsToTxn on a non-transactional producer")
 		return ErrNonTransactedProducer
 	}
 
-	DebugLogger.Printf("producer/txnmgr [%s] add offsets to transaction\n", p.txnmgr.transactionalID)
+	p.txnmgr.log.Debug("producer/txnmgr add offsets to transaction")
 	return p.txnmgr.addOffsetsToTxn(offsets, groupId)
 }
 
@@ -304,7 +316,7 @@ func (p *asyncProducer) BeginTxn() error {
 	defer p.txLock.Unlock()
 
 	if !p.IsTransactional() {
-		DebugLogger.Println("producer/txnmgr attempt to call BeginTxn on a non-transactional producer")
+		p.txnmgr.log.Debug("producer/txnmgr attempt to call BeginTxn on a non-transactional producer")
 		return ErrNonTransactedProducer
 	}
 
@@ -316,16 +328,16 @@ func (p *asyncProducer) CommitTxn() error {
 	defer p.txLock.Unlock()
 
 	if !p.IsTransactional() {
-		DebugLogger.Printf("producer/txnmgr [%s] attempt to call CommitTxn on a non-transactional producer\n", p.txnmgr.transactionalID)
+		p.txnmgr.log.Debug("producer/txnmgr attempt to call CommitTxn on a non-transactional producer")
 		return ErrNonTransactedProducer
 	}
 
-	DebugLogger.Printf("producer/txnmgr [%s] committing transaction\n", p.txnmgr.transactionalID)
+	p.txnmgr.log.Debug("producer/txnmgr committing transaction")
 	err := p.finishTransaction(true)
 	if err != nil {
 		return err
 	}
-	DebugLogger.Printf("producer/txnmgr [%s] transaction committed\n", p.txnmgr.transactionalID)
+	p.txnmgr.log.Debug("producer/txnmgr transaction committed")
 	return nil
 }
 
@@ -334,15 +346,15 @@ func (p *asyncProducer) AbortTxn() error {
 	defer p.txLock.Unlock()
 
 	if !p.IsTransactional() {
-		DebugLogger.Printf("producer/txnmgr [%s] attempt to call AbortTxn on a non-transactional producer\n", p.txnmgr.transactionalID)
+		p.txnmgr.log.Debug("producer/txnmgr attempt to call AbortTxn on a non-transactional producer")
 		return ErrNonTransactedProducer
 	}
-	DebugLogger.Printf("producer/txnmgr [%s] aborting transaction\n", p.txnmgr.transactionalID)
+	p.txnmgr.log.Debug("producer/txnmgr aborting transaction")
 	err := p.finishTransaction(false)
 	if err != nil {
 		return err
 	}
-	DebugLogger.Printf("producer/txnmgr [%s] transaction aborted\n", p.txnmgr.transactionalID)
+	p.txnmgr.log.Debug("producer/txnmgr transaction aborted")
 	return nil
 }
 
@@ -406,7 +418,7 @@ func (p *asyncProducer) dispatcher() {
 
 	for msg := range p.input {
 		if msg == nil {
-			Logger.Println("Something tried to send a nil message, it was ignored.")
+			p.log.Warn("Something tried to send a nil message, it was ignored.")
 			continue
 		}
 
@@ -418,7 +430,7 @@ func (p *asyncProducer) dispatcher() {
 				err = p.txnmgr.transitionTo(ProducerTxnFlagEndTransaction|ProducerTxnFlagAbortingTransaction, nil)
 			}
 			if err != nil {
-				Logger.Printf("producer/txnmgr unable to end transaction %s", err)
+				p.txnmgr.log.Error("producer/txnmgr unable to end transaction", "err", err)
 			}
 			p.inFlight.Done()
 			continue
@@ -434,26 +446,40 @@ func (p *asyncProducer) dispatcher() {
 			if shuttingDown {
 				// we can't just call returnError here because that decrements the wait group,
 				// which hasn't been incremented yet for this message, and shouldn't be
+				p.acknowledge(msg, ErrShuttingDown)
 				pErr := &ProducerError{Msg: msg, Err: ErrShuttingDown}
 				if p.conf.Producer.Return.Errors {
 					p.errors <- pErr
 				} else {
-					Logger.Println(pErr)
+					p.log.Error("producer failed to produce message", "topic", msg.Topic, "partition", msg.Partition, "err", pErr.Err)
 				}
 				continue
 			}
 			p.inFlight.Add(1)
+			msg.enqueued = time.Now()
 			// Ignore retried msg, there are already in txn.
 			// Can't produce new record when transaction is not started.
 			if p.IsTransactional() && p.txnmgr.currentTxnStatus()&ProducerTxnFlagInTransaction == 0 {
-				Logger.Printf("attempt to send message when transaction is not started or is in ending state, got %d, expect %d\n", p.txnmgr.currentTxnStatus(), ProducerTxnFlagInTransaction)
+				p.txnmgr.log.Warn("producer/txnmgr attempt to send message when transaction is not started or is in ending state", "status", p.txnmgr.currentTxnStatus())
 				p.returnError(msg, ErrTransactionNotReady)
 				continue
 			}
 		}
 
-		for _, interceptor := range p.conf.Producer.Interceptors {
-			msg.safelyApplyInterceptor(interceptor)
+		// the fin messages of the retried partitions are not the user's
+		if msg.flags == 0 {
+			if err := p.applyInterceptors(msg); err != nil {
+				p.returnError(msg, err)
+				continue
+			}
+		}
+
+		// retried messages are already encrypted
+		if p.conf.Producer.Encryptor != nil && msg.retries == 0 {
+			if err := p.conf.Producer.Encryptor.Encrypt(msg); err != nil {
+				p.returnError(msg, err)
+				continue
+			}
 		}
 
 		version := 1
@@ -582,6 +608,7 @@ type partitionProducer struct {
 	topic     string
 	partition int32
 	input     <-chan *ProducerMessage
+	log       *logger
 
 	leader         *Broker
 	breaker        *breaker.Breaker
@@ -607,6 +634,7 @@ func (p *asyncProducer) newPartitionProducer(topic string, partition int32) chan
 		topic:     topic,
 		partition: partition,
 		input:     input,
+		log:       p.log.with("topic", topic, "partition", partition),
 
 		breaker:    breaker.New(3, 1, 10*time.Second),
 		retryState: make([]partitionRetryState, p.conf.Producer.Retry.Max+1),
@@ -635,7 +663,7 @@ func (pp *partitionProducer) updateLeaderIfBrokerProducerIsNil(msg *ProducerMess
 			pp.backoff(msg.retries)
 			return err
 		}
-		Logger.Printf("producer/leader/%s/%d selected broker %d\n", pp.topic, pp.partition, pp.leader.ID())
+		pp.log.Info("producer/leader selected broker", "broker", pp.leader.ID())
 	}
 	return nil
 }
@@ -661,7 +689,7 @@ func (pp *partitionProducer) dispatch() {
 			select {
 			case <-pp.brokerProducer.abandoned:
 				// a message on the abandoned channel means that our current broker selection is out of date
-				Logger.Printf("producer/leader/%s/%d abandoning broker %d\n", pp.topic, pp.partition, pp.leader.ID())
+				pp.log.Info("producer/leader abandoning broker", "broker", pp.leader.ID())
 				pp.parent.unrefBrokerProducer(pp.leader, pp.brokerProducer)
 				pp.brokerProducer = nil
 				time.Sleep(pp.parent.conf.Producer.Retry.Backoff)
@@ -722,7 +750,7 @@ func (pp *partitionProducer) dispatch() {
 }
 
 func (pp *partitionProducer) newHighWatermark(hwm int) {
-	Logger.Printf("producer/leader/%s/%d state change to [retrying-%d]\n", pp.topic, pp.partition, hwm)
+	pp.log.Info("producer/leader state change", "state", fmt.Sprintf("retrying-%d", hwm))
 	pp.highWatermark = hwm
 
 	// send off a fin so that we know when everything "in between" has made it
@@ -732,13 +760,13 @@ func (pp *partitionProducer) newHighWatermark(hwm int) {
 	pp.brokerProducer.input <- &ProducerMessage{Topic: pp.topic, Partition: pp.partition, flags: fin, retries: pp.highWatermark - 1}
 
 	// a new HWM means that our current broker selection is out of date
-	Logger.Printf("producer/leader/%s/%d abandoning broker %d\n", pp.topic, pp.partition, pp.leader.ID())
+	pp.log.Info("producer/leader abandoning broker", "broker", pp.leader.ID())
 	pp.parent.unrefBrokerProducer(pp.leader, pp.brokerProducer)
 	pp.brokerProducer = nil
 }
 
 func (pp *partitionProducer) flushRetryBuffers() {
-	Logger.Printf("producer/leader/%s/%d state change to [flushing-%d]\n", pp.topic, pp.partition, pp.highWatermark)
+	pp.log.Info("producer/leader state change", "state", fmt.Sprintf("flushing-%d", pp.highWatermark))
 	for {
 		pp.highWatermark--
 
@@ -747,7 +775,7 @@ func (pp *partitionProducer) flushRetryBuffers() {
 				pp.parent.returnErrors(pp.retryState[pp.highWatermark].buf, err)
 				goto flushDone
 			}
-			Logger.Printf("producer/leader/%s/%d selected broker %d\n", pp.topic, pp.partition, pp.leader.ID())
+			pp.log.Info("producer/leader selected broker", "broker", pp.leader.ID())
 		}
 
 		for _, msg := range pp.retryState[pp.highWatermark].buf {
@@ -757,10 +785,10 @@ func (pp *partitionProducer) flushRetryBuffers() {
 	flushDone:
 		pp.retryState[pp.highWatermark].buf = nil
 		if pp.retryState[pp.highWatermark].expectChaser {
-			Logger.Printf("producer/leader/%s/%d state change to [retrying-%d]\n", pp.topic, pp.partition, pp.highWatermark)
+			pp.log.Info("producer/leader state change", "state", fmt.Sprintf("retrying-%d", pp.highWatermark))
 			break
 		} else if pp.highWatermark == 0 {
-			Logger.Printf("producer/leader/%s/%d state change to [normal]\n", pp.topic, pp.partition)
+			pp.log.Info("producer/leader state change", "state", "normal")
 			break
 		}
 	}
@@ -801,9 +829,13 @@ func (p *asyncProducer) newBrokerProducer(broker *Broker) *brokerProducer {
 		responses:      responses,
 		buffer:         newProduceSet(p),
 		currentRetries: make(map[string]map[int32]error),
+		log:            p.log.with("broker", broker.ID()),
 	}
 	go withRecover(bp.run)
 
+	batchesInFlight := getOrRegisterCounter("batches-in-flight", p.metricsRegistry, brokerMetricLabel(broker.ID()))
+	queueTime := getOrRegisterHistogram("record-queue-time-in-ms", p.metricsRegistry)
+
 	// minimal bridge to make the network response `select`able
 	go withRecover(func() {
 		// Use a wait group to know if we still have in flight requests
@@ -811,12 +843,15 @@ func (p *asyncProducer) newBrokerProducer(broker *Broker) *brokerProducer {
 
 		for set := range bridge {
 			request := set.buildRequest()
+			batches := updateQueueTimeMetric(set, queueTime)
 
 			// Count the in flight requests to know when we can close the pending channel safely
 			wg.Add(1)
+			batchesInFlight.Inc(batches)
 			// Capture the current set to forward in the callback
 			sendResponse := func(set *produceSet) ProduceCallback {
 				return func(response *ProduceResponse, err error) {
+					batchesInFlight.Dec(batches)
 					// Forward the response to make sure we do not block the responseReceiver
 					pending <- &brokerProducerResponse{
 						set: set,
@@ -920,18 +955,20 @@ type brokerProducer struct {
 
 	closing        error
 	currentRetries map[string]map[int32]error
+
+	log *logger
 }
 
 func (bp *brokerProducer) run() {
 	var output chan<- *produceSet
 	var timerChan <-chan time.Time
-	Logger.Printf("producer/broker/%d starting up\n", bp.broker.ID())
+	bp.log.Info("producer/broker starting up")
 
 	for {
 		select {
 		case msg, ok := <-bp.input:
 			if !ok {
-				Logger.Printf("producer/broker/%d input chan closed\n", bp.broker.ID())
+				bp.log.Info("producer/broker input chan closed")
 				bp.shutdown()
 				return
 			}
@@ -941,8 +978,7 @@ func (bp *brokerProducer) run() {
 			}
 
 			if msg.flags&syn == syn {
-				Logger.Printf("producer/broker/%d state change to [open] on %s/%d\n",
-					bp.broker.ID(), msg.Topic, msg.Partition)
+				bp.log.Info("producer/broker state change", "state", "open", "topic", msg.Topic, "partition", msg.Partition)
 				if bp.currentRetries[msg.Topic] == nil {
 					bp.currentRetries[msg.Topic] = make(map[int32]error)
 				}
@@ -957,8 +993,7 @@ func (bp *brokerProducer) run() {
 				if bp.closing == nil && msg.flags&fin == fin {
 					// we were retrying this partition but we can start processing again
 					delete(bp.currentRetries[msg.Topic], msg.Partition)
-					Logger.Printf("producer/broker/%d state change to [closed] on %s/%d\n",
-						bp.broker.ID(), msg.Topic, msg.Partition)
+					bp.log.Info("producer/broker state change", "state", "closed", "topic", msg.Topic, "partition", msg.Partition)
 				}
 
 				continue
@@ -967,13 +1002,12 @@ func (bp *brokerProducer) run() {
 			if msg.flags&fin == fin {
 				// New broker producer that was caught up by the retry loop
 				bp.parent.retryMessage(msg, ErrShuttingDown)
-				DebugLogger.Printf("producer/broker/%d state change to [dying-%d] on %s/%d\n",
-					bp.broker.ID(), msg.retries, msg.Topic, msg.Partition)
+				bp.log.Debug("producer/broker state change", "state", fmt.Sprintf("dying-%d", msg.retries), "topic", msg.Topic, "partition", msg.Partition)
 				continue
 			}
 
 			if bp.buffer.wouldOverflow(msg) {
-				Logger.Printf("producer/broker/%d maximum request accumulated, waiting for space\n", bp.broker.ID())
+				bp.log.Info("producer/broker maximum request accumulated, waiting for space")
 				if err := bp.waitForSpace(msg, false); err != nil {
 					bp.parent.retryMessage(msg, err)
 					continue
@@ -982,7 +1016,7 @@ func (bp *brokerProducer) run() {
 
 			if bp.parent.txnmgr.producerID != noProducerID && bp.buffer.producerEpoch != msg.producerEpoch {
 				// The epoch was reset, need to roll the buffer over
-				Logger.Printf("producer/broker/%d detected epoch rollover, waiting for new buffer\n", bp.broker.ID())
+				bp.log.Info("producer/broker detected epoch rollover, waiting for new buffer")
 				if err := bp.waitForSpace(msg, true); err != nil {
 					bp.parent.retryMessage(msg, err)
 					continue
@@ -1031,7 +1065,7 @@ func (bp *brokerProducer) shutdown() {
 		bp.handleResponse(response)
 	}
 	// No more brokerProducer related goroutine should be running
-	Logger.Printf("producer/broker/%d shut down\n", bp.broker.ID())
+	bp.log.Info("producer/broker shut down")
 }
 
 func (bp *brokerProducer) needsRetry(msg *ProducerMessage) error {
@@ -1082,6 +1116,12 @@ func (bp *brokerProducer) handleResponse(response *brokerProducerResponse) {
 }
 
 func (bp *brokerProducer) handleSuccess(sent *produceSet, response *ProduceResponse) {
+	if response != nil {
+		throttleTime := int64(response.ThrottleTime / time.Millisecond)
+		getOrRegisterAggregateHistogram("produce-throttle-time-in-ms", bp.parent.metricsRegistry).Update(throttleTime)
+		getOrRegisterHistogram("produce-throttle-time-in-ms", bp.parent.metricsRegistry, brokerMetricLabel(bp.broker.ID())).Update(throttleTime)
+	}
+
 	// we iterate through the blocks in the request set, not the response, so that we notice
 	// if the response is missing a block completely
 	var retryTopics []string
@@ -1135,7 +1175,7 @@ func (bp *brokerProducer) handleSuccess(sent *produceSet, response *ProduceRespo
 		if bp.parent.conf.Producer.Idempotent {
 			err := bp.parent.client.RefreshMetadata(retryTopics...)
 			if err != nil {
-				Logger.Printf("Failed refreshing metadata because of %v\n", err)
+				bp.log.Warn("producer/broker failed refreshing metadata", "err", err)
 			}
 		}
 
@@ -1149,8 +1189,7 @@ func (bp *brokerProducer) handleSuccess(sent *produceSet, response *ProduceRespo
 			switch block.Err {
 			case ErrInvalidMessage, ErrUnknownTopicOrPartition, ErrLeaderNotAvailable, ErrNotLeaderForPartition,
 				ErrRequestTimedOut, ErrNotEnoughReplicas, ErrNotEnoughReplicasAfterAppend, ErrKafkaStorageError:
-				Logger.Printf("producer/broker/%d state change to [retrying] on %s/%d because %v\n",
-					bp.broker.ID(), topic, partition, block.Err)
+				bp.log.Info("producer/broker state change", "state", "retrying", "topic", topic, "partition", partition, "err", block.Err)
 				if bp.currentRetries[topic] == nil {
 					bp.currentRetries[topic] = make(map[int32]error)
 				}
@@ -1168,7 +1207,7 @@ func (bp *brokerProducer) handleSuccess(sent *produceSet, response *ProduceRespo
 }
 
 func (p *asyncProducer) retryBatch(topic string, partition int32, pSet *partitionSet, kerr KError) {
-	Logger.Printf("Retrying batch for %v-%d because of %s\n", topic, partition, kerr)
+	p.log.Info("producer/retry retrying batch", "topic", topic, "partition", partition, "err", kerr)
 	produceSet := newProduceSet(p)
 	produceSet.msgs[topic] = make(map[int32]*partitionSet)
 	produceSet.msgs[topic][partition] = pSet
@@ -1181,11 +1220,12 @@ func (p *asyncProducer) retryBatch(topic string, partition int32, pSet *partitio
 		}
 		msg.retries++
 	}
+	p.updateRetryMetrics(topic, int64(len(pSet.msgs)))
 
 	// it's expected that a metadata refresh has been requested prior to calling retryBatch
 	leader, err := p.client.Leader(topic, partition)
 	if err != nil {
-		Logger.Printf("Failed retrying batch for %v-%d because of %v while looking up for new leader\n", topic, partition, err)
+		p.log.Error("producer/retry failed retrying batch while looking up for new leader", "topic", topic, "partition", partition, "err", err)
 		for _, msg := range pSet.msgs {
 			p.returnError(msg, kerr)
 		}
@@ -1203,7 +1243,7 @@ func (bp *brokerProducer) handleError(sent *produceSet, err error) {
 			bp.parent.returnErrors(pSet.msgs, err)
 		})
 	} else {
-		Logger.Printf("producer/broker/%d state change to [closing] because %s\n", bp.broker.ID(), err)
+		bp.log.Info("producer/broker state change", "state", "closing", "err", err)
 		bp.parent.abandonBrokerConnection(bp.broker)
 		_ = bp.broker.Close()
 		bp.closing = err
@@ -1217,10 +1257,16 @@ func (bp *brokerProducer) handleError(sent *produceSet, err error) {
 	}
 }
 
+// retryBufferMetrics are the gauges of the retry handler's buffer, registered
+// before it starts; availableBytes is nil without Producer.Retry.MaxBufferBytes.
+type retryBufferMetrics struct {
+	length, bytes, availableBytes metrics.Gauge
+}
+
 // singleton
 // effectively a "bridge" between the flushers and the dispatcher in order to avoid deadlock
 // based on https://godoc.org/github.com/eapache/channels#InfiniteChannel
-func (p *asyncProducer) retryHandler() {
+func (p *asyncProducer) retryHandler(bufferMetrics *retryBufferMetrics) {
 	maxBufferLength := p.conf.Producer.Retry.MaxBufferLength
 	if 0 < maxBufferLength && maxBufferLength < minFunctionalRetryBufferLength {
 		maxBufferLength = minFunctionalRetryBufferLength
@@ -1240,6 +1286,15 @@ func (p *asyncProducer) retryHandler() {
 	var msg *ProducerMessage
 	buf := queue.New()
 
+	updateBufferMetrics := func() {
+		bufferMetrics.length.Update(int64(buf.Length()))
+		bufferMetrics.bytes.Update(currentByteSize)
+		if bufferMetrics.availableBytes != nil {
+			bufferMetrics.availableBytes.Update(max(maxBufferBytes-currentByteSize, 0))
+		}
+	}
+	updateBufferMetrics()
+
 	for {
 		if buf.Length() == 0 {
 			msg = <-p.retries
@@ -1249,6 +1304,7 @@ func (p *asyncProducer) retryHandler() {
 			case p.input <- buf.Peek().(*ProducerMessage):
 				msgToRemove := buf.Remove().(*ProducerMessage)
 				currentByteSize -= int64(msgToRemove.ByteSize(version))
+				updateBufferMetrics()
 				continue
 			}
 		}
@@ -1259,6 +1315,7 @@ func (p *asyncProducer) retryHandler() {
 
 		buf.Add(msg)
 		currentByteSize += int64(msg.ByteSize(version))
+		updateBufferMetrics()
 
 		if (maxBufferLength <= 0 || buf.Length() < maxBufferLength) && (maxBufferBytes <= 0 || currentByteSize < maxBufferBytes) {
 			continue
@@ -1275,6 +1332,7 @@ func (p *asyncProducer) retryHandler() {
 				currentByteSize -= int64(msgToHandle.ByteSize(version))
 				p.returnError(msgToHandle, ErrProducerRetryBufferOverflow)
 			}
+			updateBufferMetrics()
 		}
 	}
 }
@@ -1282,7 +1340,7 @@ func (p *asyncProducer) retryHandler() {
 // utility functions
 
 func (p *asyncProducer) shutdown() {
-	Logger.Println("Producer shutting down.")
+	p.log.Info("Producer shutting down.")
 	p.inFlight.Add(1)
 	p.input <- &ProducerMessage{flags: shutdown}
 
@@ -1290,7 +1348,11 @@ func (p *asyncProducer) shutdown() {
 
 	err := p.client.Close()
 	if err != nil {
-		Logger.Println("producer/shutdown failed to close the embedded client:", err)
+		p.log.Error("producer/shutdown failed to close the embedded client", "err", err)
+	}
+
+	for _, interceptor := range p.conf.Producer.Interceptors {
+		safelyCloseInterceptor(interceptor, p.log)
 	}
 
 	close(p.input)
@@ -1304,10 +1366,10 @@ func (p *asyncProducer) shutdown() {
 func (p *asyncProducer) bumpIdempotentProducerEpoch() {
 	_, epoch := p.txnmgr.getProducerID()
 	if epoch == math.MaxInt16 {
-		Logger.Println("producer/txnmanager epoch exhausted, requesting new producer ID")
+		p.txnmgr.log.Info("producer/txnmanager epoch exhausted, requesting new producer ID")
 		txnmgr, err := newTransactionManager(p.conf, p.client)
 		if err != nil {
-			Logger.Println(err)
+			p.txnmgr.log.Error("producer/txnmanager failed to request new producer ID", "err", err)
 			return
 		}
 
@@ -1337,20 +1399,42 @@ func (p *asyncProducer) returnError(msg *ProducerMessage, err error) {
 	// We need to reset the producer ID epoch if we set a sequence number on it, because the broker
 	// will never see a message with this number, so we can never continue the sequence.
 	if !p.IsTransactional() && msg.hasSequence {
-		Logger.Printf("producer/txnmanager rolling over epoch due to publish failure on %s/%d", msg.Topic, msg.Partition)
+		p.txnmgr.log.Info("producer/txnmanager rolling over epoch due to publish failure", "topic", msg.Topic, "partition", msg.Partition)
 		p.bumpIdempotentProducerEpoch()
 	}
 
 	msg.clear()
+	p.acknowledge(msg, err)
+	getOrRegisterAggregateMeter("record-error-rate", p.metricsRegistry).Mark(1)
+	getOrRegisterTopicMeter("record-error-rate", msg.Topic, p.metricsRegistry).Mark(1)
 	pErr := &ProducerError{Msg: msg, Err: err}
 	if p.conf.Producer.Return.Errors {
 		p.errors <- pErr
 	} else {
-		Logger.Println(pErr)
+		p.log.Error("producer failed to produce message", "topic", msg.Topic, "partition", msg.Partition, "err", err)
 	}
 	p.inFlight.Done()
 }
 
+// applyInterceptors runs the message through the interceptor chain in order,
+// stopping at the first interceptor rejecting it.
+func (p *asyncProducer) applyInterceptors(msg *ProducerMessage) error {
+	for _, interceptor := range p.conf.Producer.Interceptors {
+		if err := msg.safelyApplyInterceptor(interceptor, p.log); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *asyncProducer) acknowledge(msg *ProducerMessage, err error) {
+	for _, interceptor := range p.conf.Producer.Interceptors {
+		if i, ok := interceptor.(ProducerAcknowledgementInterceptor); ok {
+			msg.safelyAcknowledge(i, err, p.log)
+		}
+	}
+}
+
 func (p *asyncProducer) returnErrors(batch []*ProducerMessage, err error) {
 	for _, msg := range batch {
 		p.returnError(msg, err)
@@ -1359,6 +1443,7 @@ func (p *asyncProducer) returnErrors(batch []*ProducerMessage, err error) {
 
 func (p *asyncProducer) returnSuccesses(batch []*ProducerMessage) {
 	for _, msg := range batch {
+		p.acknowledge(msg, nil)
 		if p.conf.Producer.Return.Successes {
 			msg.clear()
 			p.successes <- msg
@@ -1372,10 +1457,35 @@ func (p *asyncProducer) retryMessage(msg *ProducerMessage, err error) {
 		p.returnError(msg, err)
 	} else {
 		msg.retries++
+		if msg.flags == 0 {
+			p.updateRetryMetrics(msg.Topic, 1)
+		}
 		p.retries <- msg
 	}
 }
 
+// updateRetryMetrics records that n messages of topic are retried.
+func (p *asyncProducer) updateRetryMetrics(topic string, n int64) {
+	getOrRegisterAggregateMeter("record-retry-rate", p.metricsRegistry).Mark(n)
+	getOrRegisterTopicMeter("record-retry-rate", topic, p.metricsRegistry).Mark(n)
+}
+
+// updateQueueTimeMetric records in queueTime the time the batches of set,
+// about to be sent, have been queued in the producer for, and returns their
+// number.
+func updateQueueTimeMetric(set *produceSet, queueTime metrics.Histogram) int64 {
+	var batches int64
+	now := time.Now()
+	set.eachPartition(func(topic string, partition int32, pSet *partitionSet) {
+		batches++
+		// the first message of the batch is the one queued for the longest
+		if len(pSet.msgs) > 0 && !pSet.msgs[0].enqueued.IsZero() {
+			queueTime.Update(int64(now.Sub(pSet.msgs[0].enqueued) / time.Millisecond))
+		}
+	})
+	return batches
+}
+
 func (p *asyncProducer) retryMessages(batch []*ProducerMessage, err error) {
 	for _, msg := range batch {
 		p.retryMessage(msg, err)
@@ -1730,6 +1730,63 @@ func TestAsyncProducerInterceptors(t *testing.T) {
 	}
 }
 
+func TestAsyncProducerInterceptorLifecycle(t *testing.T) {
+	seedBroker := NewMockBroker(t, 1)
+	leader := NewMockBroker(t, 2)
+	metadataLeader := new(MetadataResponse)
+	metadataLeader.AddBroker(leader.Addr(), leader.BrokerID())
+	metadataLeader.AddTopicPartition("my_topic", 0, leader.BrokerID(), nil, nil, nil, ErrNoError)
+	seedBroker.Returns(metadataLeader)
+
+	interceptor := &lifecycleInterceptor{}
+	appender := &appendInterceptor{i: 0}
+	config := NewTestConfig()
+	config.Producer.Flush.Messages = 2
+	config.Producer.Return.Successes = true
+	config.Producer.Interceptors = []ProducerInterceptor{interceptor, appender}
+	producer, err := NewAsyncProducer([]string{seedBroker.Addr()}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, v := range []string{"a", "b", "reject"} {
+		producer.Input() <- &ProducerMessage{Topic: "my_topic", Value: StringEncoder(v)}
+	}
+
+	prodSuccess := new(ProduceResponse)
+	prodSuccess.AddTopicPartition("my_topic", 0, ErrNoError)
+	leader.Returns(prodSuccess)
+
+	for range 3 {
+		select {
+		case pErr := <-producer.Errors():
+			if !errors.Is(pErr, errRejectedByInterceptor) {
+				t.Error(pErr)
+			}
+		case <-producer.Successes():
+		}
+	}
+	closeProducer(t, producer)
+	leader.Close()
+	seedBroker.Close()
+
+	if appender.i != 2 {
+		t.Errorf("expected the rejected message to skip the rest of the chain, got %d calls", appender.i)
+	}
+	expected := map[string]error{"a0": nil, "reject": errRejectedByInterceptor, "b1": nil}
+	if len(interceptor.acks) != len(expected) {
+		t.Errorf("unexpected acknowledgements %v", interceptor.acks)
+	}
+	for v, err := range expected {
+		if ackErr, ok := interceptor.acks[v]; !ok || !errors.Is(ackErr, err) {
+			t.Errorf("expected %q to be acknowledged with %v, got %v", v, err, ackErr)
+		}
+	}
+	if !interceptor.closed {
+		t.Error("expected the interceptor to be closed with the producer")
+	}
+}
+
 func TestProducerError(t *testing.T) {
 	t.Parallel()
 	err := ProducerError{Err: ErrOutOfBrokers}
@@ -2434,3 +2491,62 @@ ProducerLoop:
 
 	log.Printf("Successfully produced: %d; errors: %d\n", successes, producerErrors)
 }
+
+func TestAsyncProducerMetrics(t *testing.T) {
+	seedBroker := NewMockBroker(t, 1)
+	defer seedBroker.Close()
+	leader := NewMockBroker(t, 2)
+	defer leader.Close()
+
+	metadataLeader := new(MetadataResponse)
+	metadataLeader.AddBroker(leader.Addr(), leader.BrokerID())
+	metadataLeader.AddTopicPartition("my_topic", 0, leader.BrokerID(), nil, nil, nil, ErrNoError)
+	seedBroker.Returns(metadataLeader)
+
+	config := NewTestConfig()
+	config.Producer.Return.Successes = true
+	config.Producer.Retry.Max = 1
+	config.Producer.Retry.Backoff = 0
+	config.Producer.Retry.MaxBufferBytes = 64 * 1024 * 1024
+	producer, err := NewAsyncProducer([]string{seedBroker.Addr()}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// retried once then produced
+	producer.Input() <- &ProducerMessage{Topic: "my_topic", Value: StringEncoder(TestMessage)}
+	prodNotLeader := new(ProduceResponse)
+	prodNotLeader.AddTopicPartition("my_topic", 0, ErrNotLeaderForPartition)
+	leader.Returns(prodNotLeader)
+	leader.Returns(metadataLeader)
+	prodSuccess := new(ProduceResponse)
+	prodSuccess.AddTopicPartition("my_topic", 0, ErrNoError)
+	leader.Returns(prodSuccess)
+	expectResults(t, producer, 1, 0)
+
+	// failed with a non retriable error
+	producer.Input() <- &ProducerMessage{Topic: "my_topic", Value: StringEncoder(TestMessage)}
+	prodTooLarge := new(ProduceResponse)
+	prodTooLarge.AddTopicPartition("my_topic", 0, ErrMessageSizeTooLarge)
+	leader.Returns(prodTooLarge)
+	expectResults(t, producer, 0, 1)
+
+	metricValidators := newMetricValidators()
+	metricValidators.registerForGlobalAndTopic("my_topic", countMeterValidator("record-retry-rate", 1))
+	metricValidators.registerForGlobalAndTopic("my_topic", countMeterValidator("record-error-rate", 1))
+	metricValidators.register(minCountHistogramValidator("record-queue-time-in-ms", 3))
+	metricValidators.register(minCountHistogramValidator("produce-throttle-time-in-ms", 3))
+	metricValidators.register(minCountHistogramValidator("produce-throttle-time-in-ms-for-broker-2", 3))
+	metricValidators.register(counterValidator("batches-in-flight-for-broker-2", 0))
+	metricValidators.register(&metricValidator{
+		name: "buffer-available-bytes",
+		validator: func(t *testing.T, metric interface{}) {
+			if gauge, ok := metric.(metrics.Gauge); !ok || gauge.Value() != config.Producer.Retry.MaxBufferBytes {
+				t.Errorf("Expected all the retry buffer to be available, got %v", metric)
+			}
+		},
+	})
+	metricValidators.run(t, config.MetricRegistry)
+
+	closeProducer(t, producer)
+}
@@ -1,11 +1,13 @@
 package sarama
 
 import (
+	"context"
 	"crypto/tls"
 	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"math/rand"
 	"net"
 	"sort"
@@ -27,12 +29,20 @@ type Broker struct {
 	addr          string
 	correlationID int32
 	conn          net.Conn
+	connectionID  int64 // identifies conn in the captures
 	connErr       error
 	lock          sync.Mutex
 	opened        atomic.Bool
+	log           atomic.Pointer[logger]
 	responses     chan *responsePromise
 	done          chan bool
 
+	// connections holds the additional connections opened when
+	// Net.ConnectionsPerBroker > 1, and owner is set on those connections to
+	// the Broker they belong to
+	connections atomic.Pointer[brokerConnections]
+	owner       *Broker
+
 	metricRegistry             metrics.Registry
 	incomingByteRate           metrics.Meter
 	requestRate                metrics.Meter
@@ -56,9 +66,27 @@ type Broker struct {
 	brokerThrottleTime         metrics.Histogram
 	brokerProtocolRequestsRate map[int16]metrics.Meter
 	brokerAPIVersions          apiVersionMap
+	brokerFeatures             *FeatureMetadata
 
 	kerberosAuthenticator               GSSAPIKerberosAuth
 	clientSessionReauthenticationTimeMs int64
+	reauthenticationTimer               *time.Timer
+	tlsRecycleTimer                     *time.Timer
+
+	// lastActivity is when the last request was sent or response received
+	// (in Unix nanoseconds), not counting health checks, and
+	// pendingResponses the number of responses being waited for
+	lastActivity     atomic.Int64
+	pendingResponses atomic.Int64
+	lastHealthCheck  time.Time
+	idleTimer        *time.Timer
+	// reopenConf is set when the connection was reaped, to re-open it on the
+	// next request
+	reopenConf atomic.Pointer[Config]
+
+	// dialIndex is the resolved address tried first by the next connection
+	// attempt when Net.UseAllDNSIPs is enabled
+	dialIndex int
 
 	throttleTimer     *time.Timer
 	throttleTimerLock sync.Mutex
@@ -134,6 +162,10 @@ type responsePromise struct {
 	handler       func([]byte, error)
 	packets       chan []byte
 	errors        chan error
+	observe       func(res protocolBody, size int, latency time.Duration, err error)
+	responseSize  int
+	latency       time.Duration
+	healthCheck   bool // the response does not count as activity
 }
 
 func (p *responsePromise) handle(packets []byte, err error) {
@@ -150,6 +182,19 @@ func (p *responsePromise) handle(packets []byte, err error) {
 	p.packets <- packets
 }
 
+// observed notifies the RequestObserver, if any, of the outcome of the
+// request of p, res being its decoded response.
+func (p *responsePromise) observed(res protocolBody, err error) {
+	if p.observe == nil {
+		return
+	}
+	latency := p.latency
+	if latency == 0 {
+		latency = time.Since(p.requestTime)
+	}
+	p.observe(res, p.responseSize, latency, err)
+}
+
 // NewBroker creates and returns a Broker targeting the given host:port address.
 // This does not attempt to actually connect, you have to call Open() for that.
 func NewBroker(addr string) *Broker {
@@ -165,6 +210,7 @@ func (b *Broker) Open(conf *Config) error {
 	if !b.opened.CompareAndSwap(false, true) {
 		return ErrAlreadyConnected
 	}
+	b.reopenConf.Store(nil)
 
 	if conf == nil {
 		conf = NewConfig()
@@ -177,44 +223,60 @@ func (b *Broker) Open(conf *Config) error {
 
 	b.lock.Lock()
 
+	b.log.Store(newLogger(conf, "broker", b.id, "addr", b.addr))
 	if b.metricRegistry == nil {
-		b.metricRegistry = newCleanupRegistry(conf.MetricRegistry)
+		b.metricRegistry = newMetricRegistry(conf)
 	}
+	b.openConnections(conf)
 
 	go withRecover(func() {
 		defer b.lock.Unlock()
 
-		dialer := conf.getDialer()
-		b.conn, b.connErr = dialer.Dial("tcp", b.addr)
+		b.conn, b.connErr = b.dial(conf)
 		if b.connErr != nil {
-			Logger.Printf("Failed to connect to broker %s: %s\n", b.addr, b.connErr)
+			b.logger().Error("Failed to connect to broker", "err", b.connErr)
 			b.conn = nil
 			b.opened.Store(false)
 			return
 		}
+		var certExpiry time.Time
 		if conf.Net.TLS.Enable {
-			b.conn = tls.Client(b.conn, validServerNameTLS(b.addr, conf.Net.TLS.Config))
+			var tlsConfig *tls.Config
+			tlsConfig, certExpiry, b.connErr = tlsConfigForBroker(b.addr, conf)
+			if b.connErr != nil {
+				b.logger().Error("Failed to get TLS credentials for broker", "err", b.connErr)
+				_ = b.conn.Close()
+				b.conn = nil
+				b.opened.Store(false)
+				return
+			}
+			b.conn = tls.Client(b.conn, tlsConfig)
 		}
 
 		b.conn = newBufConn(b.conn)
+		b.connectionID = nextConnectionID.Add(1)
 		b.conf = conf
 
 		// Create or reuse the global metrics shared between brokers
-		b.incomingByteRate = metrics.GetOrRegisterMeter("incoming-byte-rate", b.metricRegistry)
-		b.requestRate = metrics.GetOrRegisterMeter("request-rate", b.metricRegistry)
-		b.fetchRate = metrics.GetOrRegisterMeter("consumer-fetch-rate", b.metricRegistry)
-		b.requestSize = getOrRegisterHistogram("request-size", b.metricRegistry)
-		b.requestLatency = getOrRegisterHistogram("request-latency-in-ms", b.metricRegistry)
-		b.outgoingByteRate = metrics.GetOrRegisterMeter("outgoing-byte-rate", b.metricRegistry)
-		b.responseRate = metrics.GetOrRegisterMeter("response-rate", b.metricRegistry)
-		b.responseSize = getOrRegisterHistogram("response-size", b.metricRegistry)
-		b.requestsInFlight = metrics.GetOrRegisterCounter("requests-in-flight", b.metricRegistry)
+		b.incomingByteRate = getOrRegisterAggregateMeter("incoming-byte-rate", b.metricRegistry)
+		b.requestRate = getOrRegisterAggregateMeter("request-rate", b.metricRegistry)
+		b.fetchRate = getOrRegisterAggregateMeter("consumer-fetch-rate", b.metricRegistry)
+		b.requestSize = getOrRegisterAggregateHistogram("request-size", b.metricRegistry)
+		b.requestLatency = getOrRegisterAggregateHistogram("request-latency-in-ms", b.metricRegistry)
+		b.outgoingByteRate = getOrRegisterAggregateMeter("outgoing-byte-rate", b.metricRegistry)
+		b.responseRate = getOrRegisterAggregateMeter("response-rate", b.metricRegistry)
+		b.responseSize = getOrRegisterAggregateHistogram("response-size", b.metricRegistry)
+		b.requestsInFlight = getOrRegisterAggregateCounter("requests-in-flight", b.metricRegistry)
 		b.protocolRequestsRate = map[int16]metrics.Meter{}
 		// Do not gather metrics for seeded broker (only used during bootstrap) because they share
-		// the same id (-1) and are already exposed through the global metrics above
-		if b.id >= 0 && !metrics.UseNilMetrics {
+		// the same id (-1) and are already exposed through the global metrics above, unless
+		// they are reported to a MetricsSink which has no such global metrics
+		if b.gathersBrokerMetrics() {
 			b.registerMetrics()
 		}
+		if !certExpiry.IsZero() {
+			b.updateCertificateExpiryMetrics(certExpiry)
+		}
 
 		// Send an ApiVersionsRequest to identify the client (KIP-511).
 		// Store the response in the brokerAPIVersions map.
@@ -223,7 +285,7 @@ func (b *Broker) Open(conf *Config) error {
 		if conf.ApiVersionsRequest {
 			apiVersionsResponse, err := b.sendAndReceiveApiVersions(3)
 			if err != nil {
-				Logger.Printf("Error while sending ApiVersionsRequest V3 to broker %s: %s\n", b.addr, err)
+				b.logger().Warn("Error while sending ApiVersionsRequest", "version", 3, "err", err)
 				// send a lower version request in case remote cluster is <= 2.4.0.0
 				maxVersion := int16(0)
 				if apiVersionsResponse != nil {
@@ -236,7 +298,7 @@ func (b *Broker) Open(conf *Config) error {
 				}
 				apiVersionsResponse, err = b.sendAndReceiveApiVersions(maxVersion)
 				if err != nil {
-					Logger.Printf("Error while sending ApiVersionsRequest V%d to broker %s: %s\n", maxVersion, b.addr, err)
+					b.logger().Error("Error while sending ApiVersionsRequest", "version", maxVersion, "err", err)
 				}
 			}
 			if apiVersionsResponse != nil {
@@ -247,6 +309,9 @@ func (b *Broker) Open(conf *Config) error {
 						maxVersion: key.MaxVersion,
 					}
 				}
+				if apiVersionsResponse.Version >= 3 {
+					b.brokerFeatures = newFeatureMetadata(apiVersionsResponse)
+				}
 			}
 		}
 
@@ -261,9 +326,9 @@ func (b *Broker) Open(conf *Config) error {
 			if b.connErr != nil {
 				err = b.conn.Close()
 				if err == nil {
-					DebugLogger.Printf("Closed connection to broker %s due to SASL v0 auth error: %s\n", b.addr, b.connErr)
+					b.logger().Debug("Closed connection to broker due to SASL v0 auth error", "err", b.connErr)
 				} else {
-					Logger.Printf("Error while closing connection to broker %s (due to SASL v0 auth error: %s): %s\n", b.addr, b.connErr, err)
+					b.logger().Error("Error while closing connection to broker due to SASL v0 auth error", "auth_err", b.connErr, "err", err)
 				}
 				b.conn = nil
 				b.opened.Store(false)
@@ -282,25 +347,83 @@ func (b *Broker) Open(conf *Config) error {
 				<-b.done
 				err = b.conn.Close()
 				if err == nil {
-					DebugLogger.Printf("Closed connection to broker %s due to SASL v1 auth error: %s\n", b.addr, b.connErr)
+					b.logger().Debug("Closed connection to broker due to SASL v1 auth error", "err", b.connErr)
 				} else {
-					Logger.Printf("Error while closing connection to broker %s (due to SASL v1 auth error: %s): %s\n", b.addr, b.connErr, err)
+					b.logger().Error("Error while closing connection to broker due to SASL v1 auth error", "auth_err", b.connErr, "err", err)
 				}
 				b.conn = nil
 				b.opened.Store(false)
 				return
 			}
 		}
-		if b.id >= 0 {
-			DebugLogger.Printf("Connected to broker at %s (registered as #%d)\n", b.addr, b.id)
-		} else {
-			DebugLogger.Printf("Connected to broker at %s (unregistered)\n", b.addr)
+		if !certExpiry.IsZero() && conf.Net.TLS.RecycleBeforeExpiry > 0 {
+			// don't recycle in a loop if the current credentials expire soon already
+			if delay := time.Until(certExpiry) - conf.Net.TLS.RecycleBeforeExpiry; delay > 0 {
+				b.scheduleTLSRecycle(b.conn, delay)
+			} else {
+				b.logger().Warn("TLS certificate used for broker expires soon", "expiry", certExpiry)
+			}
+		}
+		if conf.Net.MaxIdleTime > 0 || conf.Net.HealthCheckInterval > 0 {
+			b.lastActivity.Store(time.Now().UnixNano())
+			b.lastHealthCheck = time.Now()
+			b.scheduleIdleCheck(b.conn)
+		}
+		b.logger().Debug("Connected to broker", "registered", b.id >= 0)
+		if b.owner == nil {
+			notifyEvent(conf, &BrokerConnected{BrokerID: b.id, Addr: b.addr})
 		}
 	})
 
 	return nil
 }
 
+// nextConnectionID numbers the broker connections of the process, to tell
+// them apart in the captures.
+var nextConnectionID atomic.Int64
+
+// lookupHost resolves broker host names when Net.UseAllDNSIPs is enabled.
+var lookupHost = net.DefaultResolver.LookupHost
+
+// dial connects to the broker. With Net.UseAllDNSIPs, the broker host name is
+// resolved again for each connection, and each of its addresses is tried in
+// turn, starting with the address last connected to. When all of them fail,
+// the next attempt starts with the following address.
+// b.lock must be held by caller
+func (b *Broker) dial(conf *Config) (net.Conn, error) {
+	dialer := conf.getDialer()
+	if !conf.Net.UseAllDNSIPs {
+		return dialer.Dial("tcp", b.addr)
+	}
+
+	host, port, err := net.SplitHostPort(b.addr)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), conf.Net.DialTimeout)
+	ips, err := lookupHost(ctx, host)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+
+	var firstErr error
+	for i := range ips {
+		addr := net.JoinHostPort(ips[(b.dialIndex+i)%len(ips)], port)
+		conn, err := dialer.Dial("tcp", addr)
+		if err == nil {
+			b.dialIndex = (b.dialIndex + i) % len(ips)
+			return conn, nil
+		}
+		b.logger().Debug("Failed to connect to broker address", "ip", addr, "err", err)
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	b.dialIndex = (b.dialIndex + 1) % len(ips)
+	return nil, firstErr
+}
+
 func (b *Broker) ResponseSize() int {
 	b.lock.Lock()
 	defer b.lock.Unlock()
@@ -337,13 +460,31 @@ func (b *Broker) TLSConnectionState() (state tls.ConnectionState, ok bool) {
 
 // Close closes the broker resources
 func (b *Broker) Close() error {
+	b.reopenConf.Store(nil)
+	b.closeConnections()
+
 	b.lock.Lock()
 	defer b.lock.Unlock()
 
+	return b.close()
+}
+
+// b.lock must be held by caller
+func (b *Broker) close() error {
 	if b.conn == nil {
 		return ErrNotConnected
 	}
 
+	b.stopReauthenticationTimer()
+	if b.tlsRecycleTimer != nil {
+		b.tlsRecycleTimer.Stop()
+		b.tlsRecycleTimer = nil
+	}
+	if b.idleTimer != nil {
+		b.idleTimer.Stop()
+		b.idleTimer = nil
+	}
+
 	close(b.responses)
 	<-b.done
 
@@ -354,18 +495,33 @@ func (b *Broker) Close() error {
 	b.done = nil
 	b.responses = nil
 
-	b.metricRegistry.UnregisterAll()
+	// metrics are shared with the broker owning this connection
+	if b.owner == nil {
+		b.metricRegistry.UnregisterAll()
+	}
 
 	if err == nil {
-		DebugLogger.Printf("Closed connection to broker %s\n", b.addr)
+		b.logger().Debug("Closed connection to broker")
 	} else {
-		Logger.Printf("Error while closing connection to broker %s: %s\n", b.addr, err)
+		b.logger().Error("Error while closing connection to broker", "err", err)
+	}
+	if b.owner == nil {
+		notifyEvent(b.conf, &BrokerDisconnected{BrokerID: b.id, Addr: b.addr, Err: err})
 	}
 	b.opened.Store(false)
 
 	return err
 }
 
+// logger returns the logger of the broker, which logs to the global loggers
+// until the broker is opened.
+func (b *Broker) logger() *logger {
+	if l := b.log.Load(); l != nil {
+		return l
+	}
+	return newLogger(nil, "broker", b.id, "addr", b.addr)
+}
+
 // ID returns the broker ID retrieved from Kafka's metadata, or -1 if that is not known.
 func (b *Broker) ID() int32 {
 	return b.id
@@ -387,6 +543,28 @@ func (b *Broker) Rack() string {
 	return *b.rack
 }
 
+// Features returns the supported and finalized features the broker advertised
+// in its ApiVersionsResponse when the connection was opened. It returns nil if
+// Config.ApiVersionsRequest is disabled or the broker did not reply with
+// ApiVersionsResponse v3 or higher. Use ClusterAdmin.DescribeFeatures to fetch
+// the current cluster-wide finalized features.
+func (b *Broker) Features() *FeatureMetadata {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.brokerFeatures
+}
+
+// supportsAPI returns true if the broker advertised the given API key in its
+// ApiVersionsResponse. It returns false when the API versions are unknown.
+func (b *Broker) supportsAPI(key int16) bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	_, ok := b.brokerAPIVersions[key]
+	return ok
+}
+
 // GetMetadata send a metadata request and returns a metadata response or error
 func (b *Broker) GetMetadata(request *MetadataRequest) (*MetadataResponse, error) {
 	response := new(MetadataResponse)
@@ -453,6 +631,11 @@ type ProduceCallback func(*ProduceResponse, error)
 //
 // Make sure not to Close the broker in the callback as it will lead to a deadlock.
 func (b *Broker) AsyncProduce(request *ProduceRequest, cb ProduceCallback) error {
+	if conn := b.connectionFor(request); conn != b {
+		return conn.AsyncProduce(request, cb)
+	}
+	b.reopenIfReaped()
+
 	b.lock.Lock()
 	defer b.lock.Unlock()
 
@@ -471,17 +654,20 @@ func (b *Broker) AsyncProduce(request *ProduceRequest, cb ProduceCallback) error
 			handler: func(packets []byte, err error) {
 				if err != nil {
 					// Failed request
+					promise.observed(nil, err)
 					cb(nil, err)
 					return
 				}
 
 				if err := versionedDecode(packets, res, request.version(), metricRegistry); err != nil {
 					// Malformed response
+					promise.observed(nil, err)
 					cb(nil, err)
 					return
 				}
 
 				// Well-formed response
+				promise.observed(res, nil)
 				b.handleThrottledResponse(res)
 				cb(res, nil)
 			},
@@ -960,6 +1146,80 @@ func (b *Broker) AlterClientQuotas(request *AlterClientQuotasRequest) (*AlterCli
 	return response, nil
 }
 
+// DescribeQuorum sends a request to describe the state of the KRaft quorum
+func (b *Broker) DescribeQuorum(request *DescribeQuorumRequest) (*DescribeQuorumResponse, error) {
+	response := new(DescribeQuorumResponse)
+
+	err := b.sendAndReceive(request, response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// DescribeCluster sends a request to describe the brokers of the cluster
+func (b *Broker) DescribeCluster(request *DescribeClusterRequest) (*DescribeClusterResponse, error) {
+	response := new(DescribeClusterResponse)
+
+	err := b.sendAndReceive(request, response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// ConsumerGroupDescribe sends a request to describe groups using the consumer
+// rebalance protocol (KIP-848)
+func (b *Broker) ConsumerGroupDescribe(request *ConsumerGroupDescribeRequest) (*ConsumerGroupDescribeResponse, error) {
+	response := new(ConsumerGroupDescribeResponse)
+
+	err := b.sendAndReceive(request, response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// GetTelemetrySubscriptions sends a request to get the client metrics the
+// broker wants to be pushed (KIP-714)
+func (b *Broker) GetTelemetrySubscriptions(request *GetTelemetrySubscriptionsRequest) (*GetTelemetrySubscriptionsResponse, error) {
+	response := new(GetTelemetrySubscriptionsResponse)
+
+	err := b.sendAndReceive(request, response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// PushTelemetry sends a request to push client metrics (KIP-714)
+func (b *Broker) PushTelemetry(request *PushTelemetryRequest) (*PushTelemetryResponse, error) {
+	response := new(PushTelemetryResponse)
+
+	err := b.sendAndReceive(request, response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// UpdateFeatures sends a request to update the cluster-wide finalized features
+func (b *Broker) UpdateFeatures(request *UpdateFeaturesRequest) (*UpdateFeaturesResponse, error) {
+	response := new(UpdateFeaturesResponse)
+
+	err := b.sendAndReceive(request, response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
 // readFull ensures the conn ReadDeadline has been setup before making a
 // call to io.ReadFull
 func (b *Broker) readFull(buf []byte) (n int, err error) {
@@ -1024,6 +1284,7 @@ func (b *Broker) sendWithPromise(rb protocolBody, promise *responsePromise) erro
 		}
 		return ErrNotConnected
 	}
+	b.lastActivity.Store(time.Now().UnixNano())
 
 	if b.clientSessionReauthenticationTimeMs > 0 && currentUnixMilli() > b.clientSessionReauthenticationTimeMs {
 		err := b.authenticateViaSASLv1()
@@ -1060,6 +1321,7 @@ func (b *Broker) sendInternal(rb protocolBody, promise *responsePromise) error {
 	// check and wait if throttled
 	b.waitIfThrottled()
 
+	observe := b.startRequestObservation(rb, req.correlationID, len(buf))
 	requestTime := time.Now()
 	// Will be decremented in responseReceiver (except error or request with NoResponse)
 	b.addRequestInFlightMetrics(1)
@@ -1068,24 +1330,34 @@ func (b *Broker) sendInternal(rb protocolBody, promise *responsePromise) error {
 	b.updateProtocolMetrics(rb)
 	if err != nil {
 		b.addRequestInFlightMetrics(-1)
+		observe(nil, 0, time.Since(requestTime), err)
 		return err
 	}
+	b.capture(false, rb.key(), rb.version(), req.correlationID, buf[4:], nil)
 	b.correlationID++
 
 	if promise == nil {
 		// Record request latency without the response
 		b.updateRequestLatencyAndInFlightMetrics(time.Since(requestTime))
+		observe(nil, 0, time.Since(requestTime), nil)
 		return nil
 	}
 
 	promise.requestTime = requestTime
 	promise.correlationID = req.correlationID
+	promise.observe = observe
+	b.pendingResponses.Add(1)
 	b.responses <- promise
 
 	return nil
 }
 
 func (b *Broker) sendAndReceive(req protocolBody, res protocolBody) error {
+	if conn := b.connectionFor(req); conn != b {
+		return conn.sendAndReceive(req, res)
+	}
+	b.reopenIfReaped()
+
 	b.lock.Lock()
 	defer b.lock.Unlock()
 
@@ -1109,12 +1381,14 @@ func (b *Broker) sendAndReceive(req protocolBody, res protocolBody) error {
 }
 
 func handleResponsePromise(req protocolBody, res protocolBody, promise *responsePromise, metricRegistry metrics.Registry) error {
+	var err error
 	select {
 	case buf := <-promise.packets:
-		return versionedDecode(buf, res, req.version(), metricRegistry)
-	case err := <-promise.errors:
-		return err
+		err = versionedDecode(buf, res, req.version(), metricRegistry)
+	case err = <-promise.errors:
 	}
+	promise.observed(res, err)
+	return err
 }
 
 func (b *Broker) decode(pd packetDecoder, version int16) (err error) {
@@ -1182,13 +1456,20 @@ func (b *Broker) encode(pe packetEncoder, version int16) (err error) {
 
 func (b *Broker) responseReceiver() {
 	var dead error
+	handle := func(promise *responsePromise, packets []byte, err error) {
+		b.pendingResponses.Add(-1)
+		if !promise.healthCheck {
+			b.lastActivity.Store(time.Now().UnixNano())
+		}
+		promise.handle(packets, err)
+	}
 
 	for promise := range b.responses {
 		if dead != nil {
 			// This was previously incremented in send() and
 			// we are not calling updateIncomingCommunicationMetrics()
 			b.addRequestInFlightMetrics(-1)
-			promise.handle(nil, dead)
+			handle(promise, nil, dead)
 			continue
 		}
 
@@ -1197,10 +1478,12 @@ func (b *Broker) responseReceiver() {
 
 		bytesReadHeader, err := b.readFull(header)
 		requestLatency := time.Since(promise.requestTime)
+		promise.latency = requestLatency
+		promise.responseSize = bytesReadHeader
 		if err != nil {
 			b.updateIncomingCommunicationMetrics(bytesReadHeader, requestLatency)
 			dead = err
-			promise.handle(nil, err)
+			handle(promise, nil, err)
 			continue
 		}
 
@@ -1209,7 +1492,7 @@ func (b *Broker) responseReceiver() {
 		if err != nil {
 			b.updateIncomingCommunicationMetrics(bytesReadHeader, requestLatency)
 			dead = err
-			promise.handle(nil, err)
+			handle(promise, nil, err)
 			continue
 		}
 		if decodedHeader.correlationID != promise.correlationID {
@@ -1217,20 +1500,22 @@ func (b *Broker) responseReceiver() {
 			// TODO if decoded ID < cur ID, discard until we catch up
 			// TODO if decoded ID > cur ID, save it so when cur ID catches up we have a response
 			dead = PacketDecodingError{fmt.Sprintf("correlation ID didn't match, wanted %d, got %d", promise.correlationID, decodedHeader.correlationID)}
-			promise.handle(nil, dead)
+			handle(promise, nil, dead)
 			continue
 		}
 
 		buf := make([]byte, decodedHeader.length-int32(headerLength)+4)
 		bytesReadBody, err := b.readFull(buf)
+		promise.responseSize += bytesReadBody
 		b.updateIncomingCommunicationMetrics(bytesReadHeader+bytesReadBody, requestLatency)
 		if err != nil {
 			dead = err
-			promise.handle(nil, err)
+			handle(promise, nil, err)
 			continue
 		}
+		b.capture(true, promise.response.key(), promise.response.version(), promise.correlationID, header[4:], buf)
 
-		promise.handle(buf, nil)
+		handle(promise, buf, nil)
 	}
 	close(b.done)
 }
@@ -1264,9 +1549,10 @@ func (b *Broker) sendAndReceiveApiVersions(v int16) (*ApiVersionsResponse, error
 	b.updateOutgoingCommunicationMetrics(bytes)
 	if err != nil {
 		b.addRequestInFlightMetrics(-1)
-		Logger.Printf("Failed to send ApiVersionsRequest V%d to %s: %s\n", v, b.addr, err)
+		b.logger().Error("Failed to send ApiVersionsRequest", "version", v, "err", err)
 		return nil, err
 	}
+	b.capture(false, rb.key(), rb.version(), req.correlationID, buf[4:], nil)
 	b.correlationID++
 
 	// Kafka protocol response structure:
@@ -1276,7 +1562,7 @@ func (b *Broker) sendAndReceiveApiVersions(v int16) (*ApiVersionsResponse, error
 	_, err = b.readFull(header)
 	if err != nil {
 		b.addRequestInFlightMetrics(-1)
-		Logger.Printf("Failed to read ApiVersionsResponse V%d header from %s: %s\n", v, b.addr, err)
+		b.logger().Error("Failed to read ApiVersionsResponse header", "version", v, "err", err)
 		return nil, err
 	}
 
@@ -1288,15 +1574,16 @@ func (b *Broker) sendAndReceiveApiVersions(v int16) (*ApiVersionsResponse, error
 	n, err := b.readFull(payload)
 	if err != nil {
 		b.addRequestInFlightMetrics(-1)
-		Logger.Printf("Failed to read ApiVersionsResponse V%d payload from %s: %s\n", v, b.addr, err)
+		b.logger().Error("Failed to read ApiVersionsResponse payload", "version", v, "err", err)
 		return nil, err
 	}
 
 	b.updateIncomingCommunicationMetrics(n+8, time.Since(requestTime))
-	res := &ApiVersionsResponse{Version: rb.version()}
+	b.capture(true, rb.key(), rb.version(), req.correlationID, header[4:], payload)
+	res := &ApiVersionsResponse{Version: rb.version(), FinalizedFeaturesEpoch: -1}
 	err = versionedDecode(payload, res, rb.version(), b.metricRegistry)
 	if err != nil {
-		Logger.Printf("Failed to parse ApiVersionsResponse V%d from %s: %s\n", v, b.addr, err)
+		b.logger().Error("Failed to parse ApiVersionsResponse", "version", v, "err", err)
 		return nil, err
 	}
 
@@ -1305,7 +1592,7 @@ func (b *Broker) sendAndReceiveApiVersions(v int16) (*ApiVersionsResponse, error
 		return res, fmt.Errorf("Error in ApiVersionsResponse V%d from %s: %w", res.Version, b.addr, kerr)
 	}
 
-	DebugLogger.Printf("Completed ApiVersionsRequest V%d to %s. Broker supports %d APIs\n", v, b.addr, len(res.ApiKeys))
+	b.logger().Debug("Completed ApiVersionsRequest", "version", v, "apis", len(res.ApiKeys))
 	return res, nil
 }
 
@@ -1329,12 +1616,12 @@ func (b *Broker) authenticateViaSASLv1() error {
 
 		handshakeErr := b.sendInternal(handshakeRequest, prom)
 		if handshakeErr != nil {
-			Logger.Printf("Error while performing SASL handshake %s: %s\n", b.addr, handshakeErr)
+			b.logger().Error("Error while performing SASL handshake", "err", handshakeErr)
 			return handshakeErr
 		}
 		handshakeErr = handleResponsePromise(handshakeRequest, handshakeResponse, prom, metricRegistry)
 		if handshakeErr != nil {
-			Logger.Printf("Error while handling SASL handshake response %s: %s\n", b.addr, handshakeErr)
+			b.logger().Error("Error while handling SASL handshake response", "err", handshakeErr)
 			return handshakeErr
 		}
 
@@ -1349,12 +1636,12 @@ func (b *Broker) authenticateViaSASLv1() error {
 		prom := makeResponsePromise(authenticateResponse)
 		authErr := b.sendInternal(authenticateRequest, prom)
 		if authErr != nil {
-			Logger.Printf("Error while performing SASL Auth %s\n", b.addr)
+			b.logger().Error("Error while performing SASL Auth", "err", authErr)
 			return nil, authErr
 		}
 		authErr = handleResponsePromise(authenticateRequest, authenticateResponse, prom, metricRegistry)
 		if authErr != nil {
-			Logger.Printf("Error while performing SASL Auth %s: %s\n", b.addr, authErr)
+			b.logger().Error("Error while performing SASL Auth", "err", authErr)
 			return nil, authErr
 		}
 
@@ -1366,25 +1653,29 @@ func (b *Broker) authenticateViaSASLv1() error {
 			return nil, err
 		}
 
-		b.computeSaslSessionLifetime(authenticateResponse)
 		return authenticateResponse, nil
 	}
 
-	switch b.conf.Net.SASL.Mechanism {
-	case SASLTypeGSSAPI:
-		b.kerberosAuthenticator.Config = &b.conf.Net.SASL.GSSAPI
-		if b.kerberosAuthenticator.NewKerberosClientFunc == nil {
-			b.kerberosAuthenticator.NewKerberosClientFunc = NewKerberosClient
+	mechanism, err := b.newSASLMechanismClient()
+	if err != nil {
+		return err
+	}
+	res, err := runSASLExchange(b, mechanism, authSendReceiver)
+	if err != nil {
+		return err
+	}
+
+	var sessionLifetimeMs int64
+	if res != nil {
+		sessionLifetimeMs = res.SessionLifetimeMs
+	}
+	if provider, ok := mechanism.(SASLSessionLifetimeProvider); ok {
+		if lifetimeMs := provider.SessionLifetime().Milliseconds(); lifetimeMs > 0 && (sessionLifetimeMs <= 0 || lifetimeMs < sessionLifetimeMs) {
+			sessionLifetimeMs = lifetimeMs
 		}
-		return b.kerberosAuthenticator.AuthorizeV2(b, authSendReceiver)
-	case SASLTypeOAuth:
-		provider := b.conf.Net.SASL.TokenProvider
-		return b.sendAndReceiveSASLOAuth(authSendReceiver, provider)
-	case SASLTypeSCRAMSHA256, SASLTypeSCRAMSHA512:
-		return b.sendAndReceiveSASLSCRAMv1(authSendReceiver, b.conf.Net.SASL.SCRAMClientGeneratorFunc())
-	default:
-		return b.sendAndReceiveSASLPlainAuthV1(authSendReceiver)
 	}
+	b.computeSaslSessionLifetime(sessionLifetimeMs)
+	return nil
 }
 
 func (b *Broker) sendAndReceiveKerberos() error {
@@ -1411,7 +1702,7 @@ func (b *Broker) sendAndReceiveSASLHandshake(saslType SASLMechanism, version int
 	b.updateOutgoingCommunicationMetrics(bytes)
 	if err != nil {
 		b.addRequestInFlightMetrics(-1)
-		Logger.Printf("Failed to send SASL handshake %s: %s\n", b.addr, err.Error())
+		b.logger().Error("Failed to send SASL handshake", "err", err)
 		return err
 	}
 	b.correlationID++
@@ -1420,7 +1711,7 @@ func (b *Broker) sendAndReceiveSASLHandshake(saslType SASLMechanism, version int
 	_, err = b.readFull(header)
 	if err != nil {
 		b.addRequestInFlightMetrics(-1)
-		Logger.Printf("Failed to read SASL handshake header : %s\n", err.Error())
+		b.logger().Error("Failed to read SASL handshake header", "err", err)
 		return err
 	}
 
@@ -1429,7 +1720,7 @@ func (b *Broker) sendAndReceiveSASLHandshake(saslType SASLMechanism, version int
 	n, err := b.readFull(payload)
 	if err != nil {
 		b.addRequestInFlightMetrics(-1)
-		Logger.Printf("Failed to read SASL handshake payload : %s\n", err.Error())
+		b.logger().Error("Failed to read SASL handshake payload", "err", err)
 		return err
 	}
 
@@ -1438,16 +1729,16 @@ func (b *Broker) sendAndReceiveSASLHandshake(saslType SASLMechanism, version int
 
 	err = versionedDecode(payload, res, 0, b.metricRegistry)
 	if err != nil {
-		Logger.Printf("Failed to parse SASL handshake : %s\n", err.Error())
+		b.logger().Error("Failed to parse SASL handshake", "err", err)
 		return err
 	}
 
 	if !errors.Is(res.Err, ErrNoError) {
-		Logger.Printf("Invalid SASL Mechanism : %s\n", res.Err.Error())
+		b.logger().Error("Invalid SASL Mechanism", "err", res.Err)
 		return res.Err
 	}
 
-	DebugLogger.Print("Completed pre-auth SASL handshake. Available mechanisms: ", res.EnabledMechanisms)
+	b.logger().Debug("Completed pre-auth SASL handshake", "mechanisms", res.EnabledMechanisms)
 	return nil
 }
 
@@ -1478,7 +1769,7 @@ func (b *Broker) sendAndReceiveSASLPlainAuthV0() error {
 	if b.conf.Net.SASL.Handshake {
 		handshakeErr := b.sendAndReceiveSASLHandshake(SASLTypePlaintext, b.conf.Net.SASL.Version)
 		if handshakeErr != nil {
-			Logger.Printf("Error while performing SASL handshake %s: %s\n", b.addr, handshakeErr)
+			b.logger().Error("Error while performing SASL handshake", "err", handshakeErr)
 			return handshakeErr
 		}
 	}
@@ -1495,7 +1786,7 @@ func (b *Broker) sendAndReceiveSASLPlainAuthV0() error {
 	b.updateOutgoingCommunicationMetrics(bytesWritten)
 	if err != nil {
 		b.addRequestInFlightMetrics(-1)
-		Logger.Printf("Failed to write SASL auth header to broker %s: %s\n", b.addr, err.Error())
+		b.logger().Error("Failed to write SASL auth header to broker", "err", err)
 		return err
 	}
 
@@ -1505,53 +1796,18 @@ func (b *Broker) sendAndReceiveSASLPlainAuthV0() error {
 	// If the credentials are valid, we would get a 4 byte response filled with null characters.
 	// Otherwise, the broker closes the connection and we get an EOF
 	if err != nil {
-		Logger.Printf("Failed to read response while authenticating with SASL to broker %s: %s\n", b.addr, err.Error())
+		b.logger().Error("Failed to read response while authenticating with SASL to broker", "err", err)
 		return err
 	}
 
-	DebugLogger.Printf("SASL authentication successful with broker %s:%v - %v\n", b.addr, n, header)
+	b.logger().Debug("SASL authentication successful with broker", "bytes", n, "header", header)
 	return nil
 }
 
-// Kafka 1.x.x onward added a SaslAuthenticate request/response message which
-// wraps the SASL flow in the Kafka protocol, which allows for returning
-// meaningful errors on authentication failure.
-func (b *Broker) sendAndReceiveSASLPlainAuthV1(authSendReceiver func(authBytes []byte) (*SaslAuthenticateResponse, error)) error {
-	authBytes := []byte(b.conf.Net.SASL.AuthIdentity + "\x00" + b.conf.Net.SASL.User + "\x00" + b.conf.Net.SASL.Password)
-	_, err := authSendReceiver(authBytes)
-	return err
-}
-
 func currentUnixMilli() int64 {
 	return time.Now().UnixNano() / int64(time.Millisecond)
 }
 
-// sendAndReceiveSASLOAuth performs the authentication flow as described by KIP-255
-// https://cwiki.apache.org/confluence/pages/viewpage.action?pageId=75968876
-func (b *Broker) sendAndReceiveSASLOAuth(authSendReceiver func(authBytes []byte) (*SaslAuthenticateResponse, error), provider AccessTokenProvider) error {
-	token, err := provider.Token()
-	if err != nil {
-		return err
-	}
-
-	message, err := buildClientFirstMessage(token)
-	if err != nil {
-		return err
-	}
-
-	res, err := authSendReceiver(message)
-	if err != nil {
-		return err
-	}
-	isChallenge := len(res.SaslAuthBytes) > 0
-
-	if isChallenge {
-		// Abort the token exchange. The broker returns the failure code.
-		_, err = authSendReceiver([]byte(`\x01`))
-	}
-	return err
-}
-
 func (b *Broker) sendAndReceiveSASLSCRAMv0() error {
 	if err := b.sendAndReceiveSASLHandshake(b.conf.Net.SASL.Mechanism, SASLHandshakeV0); err != nil {
 		return err
@@ -1579,7 +1835,7 @@ func (b *Broker) sendAndReceiveSASLSCRAMv0() error {
 		b.updateOutgoingCommunicationMetrics(length + 4)
 		if err != nil {
 			b.addRequestInFlightMetrics(-1)
-			Logger.Printf("Failed to write SASL auth header to broker %s: %s\n", b.addr, err.Error())
+			b.logger().Error("Failed to write SASL auth header to broker", "err", err)
 			return err
 		}
 		b.correlationID++
@@ -1587,53 +1843,25 @@ func (b *Broker) sendAndReceiveSASLSCRAMv0() error {
 		_, err = b.readFull(header)
 		if err != nil {
 			b.addRequestInFlightMetrics(-1)
-			Logger.Printf("Failed to read response header while authenticating with SASL to broker %s: %s\n", b.addr, err.Error())
+			b.logger().Error("Failed to read response header while authenticating with SASL to broker", "err", err)
 			return err
 		}
 		payload := make([]byte, int32(binary.BigEndian.Uint32(header)))
 		n, err := b.readFull(payload)
 		if err != nil {
 			b.addRequestInFlightMetrics(-1)
-			Logger.Printf("Failed to read response payload while authenticating with SASL to broker %s: %s\n", b.addr, err.Error())
+			b.logger().Error("Failed to read response payload while authenticating with SASL to broker", "err", err)
 			return err
 		}
 		b.updateIncomingCommunicationMetrics(n+4, time.Since(requestTime))
 		msg, err = scramClient.Step(string(payload))
 		if err != nil {
-			Logger.Println("SASL authentication failed", err)
+			b.logger().Error("SASL authentication failed", "err", err)
 			return err
 		}
 	}
 
-	DebugLogger.Println("SASL authentication succeeded")
-	return nil
-}
-
-func (b *Broker) sendAndReceiveSASLSCRAMv1(authSendReceiver func(authBytes []byte) (*SaslAuthenticateResponse, error), scramClient SCRAMClient) error {
-	if err := scramClient.Begin(b.conf.Net.SASL.User, b.conf.Net.SASL.Password, b.conf.Net.SASL.SCRAMAuthzID); err != nil {
-		return fmt.Errorf("failed to start SCRAM exchange with the server: %w", err)
-	}
-
-	msg, err := scramClient.Step("")
-	if err != nil {
-		return fmt.Errorf("failed to advance the SCRAM exchange: %w", err)
-	}
-
-	for !scramClient.Done() {
-		res, err := authSendReceiver([]byte(msg))
-		if err != nil {
-			return err
-		}
-
-		msg, err = scramClient.Step(string(res.SaslAuthBytes))
-		if err != nil {
-			Logger.Println("SASL authentication failed", err)
-			return err
-		}
-	}
-
-	DebugLogger.Println("SASL authentication succeeded")
-
+	b.logger().Debug("SASL authentication succeeded")
 	return nil
 }
 
@@ -1681,20 +1909,61 @@ func mapToString(extensions map[string]string, keyValSep string, elemSep string)
 	return strings.Join(buf, elemSep)
 }
 
-func (b *Broker) computeSaslSessionLifetime(res *SaslAuthenticateResponse) {
-	if res.SessionLifetimeMs > 0 {
+func (b *Broker) computeSaslSessionLifetime(sessionLifetimeMs int64) {
+	if sessionLifetimeMs > 0 {
 		// Follows the Java Kafka implementation from SaslClientAuthenticator.ReauthInfo#setAuthenticationEndAndSessionReauthenticationTimes
 		// pick a random percentage between 85% and 95% for session re-authentication
-		positiveSessionLifetimeMs := res.SessionLifetimeMs
+		positiveSessionLifetimeMs := sessionLifetimeMs
 		authenticationEndMs := currentUnixMilli()
 		pctWindowFactorToTakeNetworkLatencyAndClockDriftIntoAccount := 0.85
 		pctWindowJitterToAvoidReauthenticationStormAcrossManyChannelsSimultaneously := 0.10
 		pctToUse := pctWindowFactorToTakeNetworkLatencyAndClockDriftIntoAccount + rand.Float64()*pctWindowJitterToAvoidReauthenticationStormAcrossManyChannelsSimultaneously
 		sessionLifetimeMsToUse := int64(float64(positiveSessionLifetimeMs) * pctToUse)
-		DebugLogger.Printf("Session expiration in %d ms and session re-authentication on or after %d ms", positiveSessionLifetimeMs, sessionLifetimeMsToUse)
+		b.logger().Debug("Scheduled SASL session re-authentication", "session_lifetime_ms", positiveSessionLifetimeMs, "reauthentication_ms", sessionLifetimeMsToUse)
 		b.clientSessionReauthenticationTimeMs = authenticationEndMs + sessionLifetimeMsToUse
+		b.scheduleReauthentication(time.Duration(sessionLifetimeMsToUse) * time.Millisecond)
 	} else {
 		b.clientSessionReauthenticationTimeMs = 0
+		b.stopReauthenticationTimer()
+	}
+}
+
+// scheduleReauthentication arms a timer that re-authenticates the session
+// (KIP-368) on the existing connection once delay has elapsed, so that idle
+// connections are not closed by the broker when the session expires.
+// b.lock must be held by caller
+func (b *Broker) scheduleReauthentication(delay time.Duration) {
+	b.stopReauthenticationTimer()
+	b.reauthenticationTimer = time.AfterFunc(delay, b.reauthenticate)
+}
+
+// b.lock must be held by caller
+func (b *Broker) stopReauthenticationTimer() {
+	if b.reauthenticationTimer != nil {
+		b.reauthenticationTimer.Stop()
+		b.reauthenticationTimer = nil
+	}
+}
+
+// reauthenticate re-runs the SASL exchange on the current connection. It
+// takes b.lock, so it is serialized with requests being sent, and responses to
+// requests already in flight are read before the re-authentication response.
+// A failed attempt is retried before sending the next request.
+func (b *Broker) reauthenticate() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.conn == nil || b.clientSessionReauthenticationTimeMs == 0 {
+		return
+	}
+	if currentUnixMilli() < b.clientSessionReauthenticationTimeMs {
+		// re-authenticated by a request in the meantime
+		return
+	}
+
+	b.logger().Debug("Re-authenticating SASL session with broker")
+	if err := b.authenticateViaSASLv1(); err != nil {
+		b.logger().Error("Error while re-authenticating SASL session with broker", "err", err)
 	}
 }
 
@@ -1757,7 +2026,7 @@ func (b *Broker) updateOutgoingCommunicationMetrics(bytes int) {
 func (b *Broker) updateProtocolMetrics(rb protocolBody) {
 	protocolRequestsRate := b.protocolRequestsRate[rb.key()]
 	if protocolRequestsRate == nil {
-		protocolRequestsRate = metrics.GetOrRegisterMeter(fmt.Sprintf("protocol-requests-rate-%d", rb.key()), b.metricRegistry)
+		protocolRequestsRate = getOrRegisterAggregateMeter(fmt.Sprintf("protocol-requests-rate-%d", rb.key()), b.metricRegistry)
 		b.protocolRequestsRate[rb.key()] = protocolRequestsRate
 	}
 	protocolRequestsRate.Mark(1)
@@ -1765,7 +2034,8 @@ func (b *Broker) updateProtocolMetrics(rb protocolBody) {
 	if b.brokerProtocolRequestsRate != nil {
 		brokerProtocolRequestsRate := b.brokerProtocolRequestsRate[rb.key()]
 		if brokerProtocolRequestsRate == nil {
-			brokerProtocolRequestsRate = b.registerMeter(fmt.Sprintf("protocol-requests-rate-%d", rb.key()))
+			brokerProtocolRequestsRate = getOrRegisterMeter("protocol-requests-rate", b.metricRegistry,
+				apiKeyMetricLabel(rb.key()), brokerMetricLabel(b.id))
 			b.brokerProtocolRequestsRate[rb.key()] = brokerProtocolRequestsRate
 		}
 		brokerProtocolRequestsRate.Mark(1)
@@ -1785,8 +2055,8 @@ func (b *Broker) handleThrottledResponse(resp protocolBody) {
 	if throttleTime == time.Duration(0) {
 		return
 	}
-	DebugLogger.Printf(
-		"broker/%d %T throttled %v\n", b.ID(), resp, throttleTime)
+	b.logger().Debug("Throttled by broker", "response", fmt.Sprintf("%T", resp), "throttle", throttleTime)
+	notifyEvent(b.conf, &Throttled{BrokerID: b.id, Addr: b.addr, APIKey: resp.key(), ThrottleTime: throttleTime})
 	b.setThrottle(throttleTime)
 	b.updateThrottleMetric(throttleTime)
 }
@@ -1807,7 +2077,7 @@ func (b *Broker) waitIfThrottled() {
 	b.throttleTimerLock.Lock()
 	defer b.throttleTimerLock.Unlock()
 	if b.throttleTimer != nil {
-		DebugLogger.Printf("broker/%d waiting for throttle timer\n", b.ID())
+		b.logger().Debug("Waiting for throttle timer")
 		<-b.throttleTimer.C
 		b.throttleTimer = nil
 	}
@@ -1834,19 +2104,184 @@ func (b *Broker) registerMetrics() {
 	b.brokerProtocolRequestsRate = map[int16]metrics.Meter{}
 }
 
+// updateCertificateExpiryMetrics records the expiry of the client
+// certificate used by the latest connection, both for all brokers and for
+// this broker.
+func (b *Broker) updateCertificateExpiryMetrics(expiry time.Time) {
+	getOrRegisterAggregateGauge("tls-certificate-expiry", b.metricRegistry).Update(expiry.Unix())
+	if b.gathersBrokerMetrics() {
+		b.registerGauge("tls-certificate-expiry").Update(expiry.Unix())
+	}
+}
+
+// scheduleTLSRecycle closes conn once delay has elapsed, so that the next
+// request re-opens the connection with the current TLS credentials before
+// the certificate conn was established with expires.
+// b.lock must be held by caller
+func (b *Broker) scheduleTLSRecycle(conn net.Conn, delay time.Duration) {
+	b.tlsRecycleTimer = time.AfterFunc(delay, func() {
+		b.lock.Lock()
+		defer b.lock.Unlock()
+
+		// the connection was closed or replaced in the meantime
+		if b.conn != conn {
+			return
+		}
+		b.logger().Debug("Recycling connection to broker before its TLS certificate expires")
+		b.reap()
+	})
+}
+
+// scheduleIdleCheck schedules the next check of conn for being idle for longer
+// than Net.MaxIdleTime, or needing a health check.
+// b.lock must be held by caller
+func (b *Broker) scheduleIdleCheck(conn net.Conn) {
+	now := time.Now()
+	lastActivity := time.Unix(0, b.lastActivity.Load())
+	next := time.Duration(math.MaxInt64)
+	period := time.Duration(math.MaxInt64)
+	if maxIdle := b.conf.Net.MaxIdleTime; maxIdle > 0 {
+		next = min(next, lastActivity.Add(maxIdle).Sub(now))
+		period = min(period, maxIdle)
+	}
+	if interval := b.conf.Net.HealthCheckInterval; interval > 0 {
+		lastCheck := b.lastHealthCheck
+		if lastActivity.After(lastCheck) {
+			lastCheck = lastActivity
+		}
+		next = min(next, lastCheck.Add(interval).Sub(now))
+		period = min(period, interval)
+	}
+	if b.pendingResponses.Load() > 0 {
+		// the responses being waited for will count as activity
+		next = max(next, period)
+	}
+	b.idleTimer = time.AfterFunc(max(next, 0), func() {
+		b.lock.Lock()
+		defer b.lock.Unlock()
+
+		// the connection was closed or replaced in the meantime
+		if b.conn != conn {
+			return
+		}
+		b.checkIdle(conn)
+	})
+}
+
+// checkIdle reaps the connection if it has been idle for longer than
+// Net.MaxIdleTime or fails a health check, and schedules the next check
+// otherwise. Connections waiting for responses are not idle.
+// b.lock must be held by caller
+func (b *Broker) checkIdle(conn net.Conn) {
+	if b.pendingResponses.Load() > 0 {
+		b.scheduleIdleCheck(conn)
+		return
+	}
+	now := time.Now()
+	idle := now.Sub(time.Unix(0, b.lastActivity.Load()))
+	if maxIdle := b.conf.Net.MaxIdleTime; maxIdle > 0 && idle >= maxIdle {
+		b.logger().Debug("Closing idle connection to broker", "idle", idle.Round(time.Millisecond))
+		b.reap()
+		return
+	}
+
+	if interval := b.conf.Net.HealthCheckInterval; interval > 0 && idle >= interval && now.Sub(b.lastHealthCheck) >= interval {
+		b.lastHealthCheck = now
+		if err := b.healthCheck(); err != nil {
+			b.logger().Warn("Health check of broker failed, closing the connection", "err", err)
+			b.reap()
+			return
+		}
+	}
+	b.scheduleIdleCheck(conn)
+}
+
+// healthCheck sends an ApiVersions request on the connection and waits for
+// the response.
+// b.lock must be held by caller
+func (b *Broker) healthCheck() error {
+	req := &ApiVersionsRequest{}
+	if !b.conf.Version.IsAtLeast(req.requiredVersion()) {
+		// nothing to probe older brokers with
+		return nil
+	}
+	res := new(ApiVersionsResponse)
+	promise := makeResponsePromise(res)
+	promise.healthCheck = true
+	if err := b.sendInternal(req, promise); err != nil {
+		return err
+	}
+	if err := handleResponsePromise(req, res, promise, b.metricRegistry); err != nil {
+		return err
+	}
+	if kerr := KError(res.ErrorCode); kerr != ErrNoError {
+		return kerr
+	}
+	return nil
+}
+
+// reap closes the connection after it was found idle or unhealthy, or before
+// its TLS certificate expires. Unlike a broker closed with Close, it is
+// re-opened by the next request sent.
+// b.lock must be held by caller
+func (b *Broker) reap() {
+	conf := b.conf
+	_ = b.close()
+	b.reopenConf.Store(conf)
+}
+
+// reopenIfReaped re-opens the connection if it was reaped, or if it fails
+// the health check it is due, so that the request is sent on a new
+// connection instead of failing.
+func (b *Broker) reopenIfReaped() {
+	b.checkHealthBeforeSend()
+	if conf := b.reopenConf.Swap(nil); conf != nil {
+		_ = b.Open(conf)
+	}
+}
+
+// checkHealthBeforeSend health checks the connection if it has been idle for
+// longer than Net.HealthCheckInterval since its last health check, rather
+// than waiting for the periodic check, and reaps it if the check fails.
+func (b *Broker) checkHealthBeforeSend() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.conn == nil || b.conf.Net.HealthCheckInterval <= 0 || b.pendingResponses.Load() > 0 {
+		return
+	}
+	now := time.Now()
+	interval := b.conf.Net.HealthCheckInterval
+	if now.Sub(time.Unix(0, b.lastActivity.Load())) < interval || now.Sub(b.lastHealthCheck) < interval {
+		return
+	}
+	b.lastHealthCheck = now
+	if err := b.healthCheck(); err != nil {
+		b.logger().Warn("Health check of broker failed, closing the connection", "err", err)
+		b.reap()
+	}
+}
+
+// gathersBrokerMetrics reports whether the metrics of this broker are
+// gathered on top of the global ones.
+func (b *Broker) gathersBrokerMetrics() bool {
+	return (b.id >= 0 || metricsSinkOf(b.metricRegistry) != nil) && !metrics.UseNilMetrics
+}
+
 func (b *Broker) registerMeter(name string) metrics.Meter {
-	nameForBroker := getMetricNameForBroker(name, b)
-	return metrics.GetOrRegisterMeter(nameForBroker, b.metricRegistry)
+	return getOrRegisterMeter(name, b.metricRegistry, brokerMetricLabel(b.id))
 }
 
 func (b *Broker) registerHistogram(name string) metrics.Histogram {
-	nameForBroker := getMetricNameForBroker(name, b)
-	return getOrRegisterHistogram(nameForBroker, b.metricRegistry)
+	return getOrRegisterHistogram(name, b.metricRegistry, brokerMetricLabel(b.id))
 }
 
 func (b *Broker) registerCounter(name string) metrics.Counter {
-	nameForBroker := getMetricNameForBroker(name, b)
-	return metrics.GetOrRegisterCounter(nameForBroker, b.metricRegistry)
+	return getOrRegisterCounter(name, b.metricRegistry, brokerMetricLabel(b.id))
+}
+
+func (b *Broker) registerGauge(name string) metrics.Gauge {
+	return getOrRegisterGauge(name, b.metricRegistry, brokerMetricLabel(b.id))
 }
 
 func validServerNameTLS(addr string, cfg *tls.Config) *tls.Config {
@@ -0,0 +1,133 @@
+package sarama
+
+import "sync/atomic"
+
+// requestClass groups the requests routed to the same connections of a broker
+// opened with Net.ConnectionsPerBroker > 1.
+type requestClass int
+
+const (
+	adminRequestClass requestClass = iota
+	fetchRequestClass
+	produceRequestClass
+	numRequestClasses
+)
+
+func requestClassOf(rb protocolBody) requestClass {
+	switch rb.key() {
+	case apiKeyFetch:
+		return fetchRequestClass
+	case apiKeyProduce:
+		return produceRequestClass
+	}
+	return adminRequestClass
+}
+
+// connectionIndexes returns the connections (out of n, the first one being
+// the broker's own connection) used for the requests of the class. Fetch and
+// produce requests get their own connection as soon as there are enough of
+// them; any connections beyond the third alternate between admin and fetch
+// requests. Produce requests always use a single connection so that their
+// ordering is preserved.
+func connectionIndexes(class requestClass, n int) []int {
+	switch {
+	case class == fetchRequestClass && n < 2,
+		class == produceRequestClass && n < 3:
+		class = adminRequestClass
+	case class == produceRequestClass:
+		return []int{2}
+	}
+
+	indexes := []int{int(class)}
+	for i := 3; i < n; i++ {
+		if requestClass((i-3)%2) == class {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+// brokerConnections holds the additional connections of a broker. Each of
+// them is a Broker of its own sharing the address, configuration and metrics
+// of the broker it belongs to, and authenticating on its own.
+type brokerConnections struct {
+	conf    *Config
+	conns   []*Broker
+	indexes [numRequestClasses][]int
+	next    [numRequestClasses]atomic.Uint32
+}
+
+func (b *Broker) newBrokerConnections(conf *Config) *brokerConnections {
+	n := conf.Net.ConnectionsPerBroker
+	pool := &brokerConnections{conf: conf, conns: make([]*Broker, n-1)}
+	for i := range pool.conns {
+		pool.conns[i] = &Broker{
+			id:             b.id,
+			addr:           b.addr,
+			rack:           b.rack,
+			metricRegistry: b.metricRegistry,
+			owner:          b,
+		}
+	}
+	for class := range pool.indexes {
+		pool.indexes[class] = connectionIndexes(requestClass(class), n)
+	}
+	return pool
+}
+
+// openConnections opens the additional connections of the broker, if any are
+// configured.
+func (b *Broker) openConnections(conf *Config) {
+	if b.owner != nil || conf.Net.ConnectionsPerBroker <= 1 {
+		return
+	}
+	pool := b.newBrokerConnections(conf)
+	if prev := b.connections.Swap(pool); prev != nil {
+		prev.close()
+	}
+	for _, conn := range pool.conns {
+		_ = conn.Open(conf)
+	}
+}
+
+// closeConnections closes the additional connections of the broker.
+func (b *Broker) closeConnections() {
+	if pool := b.connections.Swap(nil); pool != nil {
+		pool.close()
+	}
+}
+
+func (p *brokerConnections) close() {
+	for _, conn := range p.conns {
+		_ = conn.Close()
+	}
+}
+
+// connectionFor returns the Broker whose connection the request is sent on,
+// which is b itself unless additional connections are configured. Additional
+// connections that have been closed are reopened.
+func (b *Broker) connectionFor(rb protocolBody) *Broker {
+	pool := b.connections.Load()
+	if pool == nil {
+		return b
+	}
+
+	class := requestClassOf(rb)
+	indexes := pool.indexes[class]
+	i := indexes[0]
+	if len(indexes) > 1 {
+		i = indexes[int(pool.next[class].Add(1)-1)%len(indexes)]
+	}
+	if i == 0 {
+		return b
+	}
+
+	conn := pool.conns[i-1]
+	if !conn.opened.Load() {
+		if err := conn.Open(pool.conf); err == nil && b.connections.Load() != pool {
+			// the broker was closed concurrently
+			_ = conn.Close()
+		}
+	}
+	return conn
+}
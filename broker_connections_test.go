@@ -0,0 +1,110 @@
+//go:build !functional
+
+package sarama
+
+import (
+	"net"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+func TestConnectionIndexes(t *testing.T) {
+	tests := []struct {
+		n                     int
+		admin, fetch, produce []int
+	}{
+		{1, []int{0}, []int{0}, []int{0}},
+		{2, []int{0}, []int{1}, []int{0}},
+		{3, []int{0}, []int{1}, []int{2}},
+		{4, []int{0, 3}, []int{1}, []int{2}},
+		{6, []int{0, 3, 5}, []int{1, 4}, []int{2}},
+	}
+	for _, tt := range tests {
+		for class, expected := range map[requestClass][]int{
+			adminRequestClass:   tt.admin,
+			fetchRequestClass:   tt.fetch,
+			produceRequestClass: tt.produce,
+		} {
+			if indexes := connectionIndexes(class, tt.n); !reflect.DeepEqual(indexes, expected) {
+				t.Errorf("class %d with %d connections: expected %v, got %v", class, tt.n, expected, indexes)
+			}
+		}
+	}
+}
+
+type countingListener struct {
+	net.Listener
+	accepted atomic.Int32
+}
+
+func (l *countingListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err == nil {
+		l.accepted.Add(1)
+	}
+	return conn, err
+}
+
+func TestBrokerConnectionsPerBroker(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	counting := &countingListener{Listener: listener}
+	mockBroker := NewMockBrokerListener(t, 0, counting)
+	defer mockBroker.Close()
+	mockBroker.SetHandlerByMap(map[string]MockResponse{
+		"MetadataRequest": NewMockMetadataResponse(t),
+		"FetchRequest":    NewMockFetchResponse(t, 1),
+		"ProduceRequest":  NewMockProduceResponse(t),
+	})
+
+	conf := NewTestConfig()
+	conf.Net.ConnectionsPerBroker = 3
+	broker := NewBroker(mockBroker.Addr())
+	if err := broker.Open(conf); err != nil {
+		t.Fatal(err)
+	}
+
+	for range 2 {
+		if _, err := broker.GetMetadata(&MetadataRequest{}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := broker.Fetch(&FetchRequest{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := broker.Produce(&ProduceRequest{RequiredAcks: WaitForLocal}); err != nil {
+		t.Fatal(err)
+	}
+
+	pool := broker.connections.Load()
+	fetchConn, produceConn := pool.conns[0], pool.conns[1]
+	if broker.correlationID != 2 || fetchConn.correlationID != 2 || produceConn.correlationID != 1 {
+		t.Errorf("unexpected routing: %d admin, %d fetch and %d produce requests",
+			broker.correlationID, fetchConn.correlationID, produceConn.correlationID)
+	}
+	if n := counting.accepted.Load(); n != 3 {
+		t.Errorf("expected 3 connections, got %d", n)
+	}
+
+	// closed additional connections are reopened when used again
+	_ = produceConn.Close()
+	if _, err := broker.Produce(&ProduceRequest{RequiredAcks: WaitForLocal}); err != nil {
+		t.Fatal(err)
+	}
+	if n := counting.accepted.Load(); n != 4 {
+		t.Errorf("expected the produce connection to be reopened, got %d connections", n)
+	}
+
+	if err := broker.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if broker.connections.Load() != nil {
+		t.Error("expected the additional connections to be closed")
+	}
+	if connected, _ := fetchConn.Connected(); connected {
+		t.Error("expected the fetch connection to be closed")
+	}
+}
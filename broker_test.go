@@ -4,10 +4,13 @@ package sarama
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"net"
 	"reflect"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -80,6 +83,45 @@ func TestBrokerAccessors(t *testing.T) {
 	}
 }
 
+func TestBrokerFeatures(t *testing.T) {
+	mb := NewMockBroker(t, 0)
+	defer mb.Close()
+
+	mb.SetHandlerByMap(map[string]MockResponse{
+		"ApiVersionsRequest": NewMockApiVersionsResponse(t).
+			SetFinalizedFeatures(7, []ApiVersionsResponseFinalizedFeatureKey{
+				{Name: MetadataVersionFeature, MinVersionLevel: 1, MaxVersionLevel: 14},
+			}),
+	})
+
+	broker := NewBroker(mb.Addr())
+	if broker.Features() != nil {
+		t.Error("Unopened broker should not have features")
+	}
+
+	conf := NewTestConfig()
+	conf.ApiVersionsRequest = true
+	conf.Version = V3_3_0_0
+	if err := broker.Open(conf); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = broker.Close() }()
+	if _, err := broker.Connected(); err != nil {
+		t.Fatal(err)
+	}
+
+	features := broker.Features()
+	if features == nil {
+		t.Fatal("Expected broker features to be set after ApiVersionsResponse v3")
+	}
+	if features.FinalizedFeaturesEpoch != 7 {
+		t.Errorf("Expected finalized features epoch 7, got %d", features.FinalizedFeaturesEpoch)
+	}
+	if level := features.FinalizedFeatures[MetadataVersionFeature].MaxVersionLevel; level != 14 {
+		t.Errorf("Expected metadata.version level 14, got %d", level)
+	}
+}
+
 type produceResponsePromise struct {
 	c chan produceResOrError
 }
@@ -963,6 +1005,208 @@ loop:
 	mockBroker.Close()
 }
 
+type countingTokenProvider struct {
+	calls atomic.Int32
+}
+
+func (t *countingTokenProvider) Token() (*AccessToken, error) {
+	n := t.calls.Add(1)
+	return &AccessToken{Token: fmt.Sprintf("token-%d", n)}, nil
+}
+
+func TestKip368ProactiveReAuthentication(t *testing.T) {
+	for _, mechanism := range []SASLMechanism{SASLTypePlaintext, SASLTypeOAuth} {
+		t.Run(string(mechanism), func(t *testing.T) {
+			sessionLifetimeMs := int64(100)
+
+			mockBroker := NewMockBroker(t, 0)
+			defer mockBroker.Close()
+
+			saslAuthRequests := func() (requests []*SaslAuthenticateRequest) {
+				for _, rr := range mockBroker.History() {
+					if req, ok := rr.Request.(*SaslAuthenticateRequest); ok {
+						requests = append(requests, req)
+					}
+				}
+				return requests
+			}
+
+			mockBroker.SetHandlerByMap(map[string]MockResponse{
+				"SaslAuthenticateRequest": NewMockSaslAuthenticateResponse(t).
+					SetSessionLifetimeMs(sessionLifetimeMs),
+				"SaslHandshakeRequest": NewMockSaslHandshakeResponse(t).
+					SetEnabledMechanisms([]string{string(mechanism)}),
+				"ApiVersionsRequest": NewMockApiVersionsResponse(t),
+			})
+
+			tokenProvider := &countingTokenProvider{}
+			conf := NewTestConfig()
+			conf.Version = V2_2_0_0
+			conf.Net.SASL.Enable = true
+			conf.Net.SASL.Mechanism = mechanism
+			conf.Net.SASL.Version = SASLHandshakeV1
+			conf.Net.SASL.User = "user"
+			conf.Net.SASL.Password = "password"
+			conf.Net.SASL.TokenProvider = tokenProvider
+
+			broker := NewBroker(mockBroker.Addr())
+			if err := broker.Open(conf); err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { _ = broker.Close() })
+			if connected, err := broker.Connected(); err != nil || !connected {
+				t.Fatal(err)
+			}
+
+			// no traffic is sent, the session must be re-authenticated by the broker itself
+			deadline := time.Now().Add(10 * time.Duration(sessionLifetimeMs) * time.Millisecond)
+			for len(saslAuthRequests()) < 2 {
+				if time.Now().After(deadline) {
+					t.Fatal("sasl reauth has not occurred within expected timeframe")
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+
+			if mechanism == SASLTypeOAuth {
+				requests := saslAuthRequests()
+				if !bytes.Contains(requests[1].SaslAuthBytes, []byte("token-2")) {
+					t.Errorf("expected a fresh token to be used for re-authentication, got %q", requests[1].SaslAuthBytes)
+				}
+			}
+
+			// the connection remains usable after re-authentication
+			if _, err := broker.ApiVersions(&ApiVersionsRequest{}); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+type testSASLMechanismClient struct {
+	steps     int
+	lifetime  time.Duration
+	responses [][]byte
+	closed    atomic.Bool
+}
+
+func (c *testSASLMechanismClient) Start() ([]byte, error) {
+	return []byte("client-first"), nil
+}
+
+func (c *testSASLMechanismClient) Step(challenge []byte) ([]byte, error) {
+	c.responses = append(c.responses, challenge)
+	c.steps++
+	if c.steps == 2 {
+		return nil, nil
+	}
+	return []byte("client-final"), nil
+}
+
+func (c *testSASLMechanismClient) Done() bool {
+	return c.steps == 2
+}
+
+func (c *testSASLMechanismClient) SessionLifetime() time.Duration {
+	return c.lifetime
+}
+
+func (c *testSASLMechanismClient) Close() error {
+	c.closed.Store(true)
+	return nil
+}
+
+func TestSASLCustomMechanism(t *testing.T) {
+	mockBroker := NewMockBroker(t, 0)
+	defer mockBroker.Close()
+
+	mockBroker.SetHandlerByMap(map[string]MockResponse{
+		"SaslAuthenticateRequest": NewMockSaslAuthenticateResponse(t).
+			SetAuthBytes([]byte("challenge")),
+		"SaslHandshakeRequest": NewMockSaslHandshakeResponse(t).
+			SetEnabledMechanisms([]string{"AWS_MSK_IAM"}),
+		"ApiVersionsRequest": NewMockApiVersionsResponse(t),
+	})
+
+	var (
+		lock    sync.Mutex
+		clients []*testSASLMechanismClient
+	)
+	conf := NewTestConfig()
+	conf.Version = V2_2_0_0
+	conf.Net.SASL.Enable = true
+	conf.Net.SASL.Mechanism = "AWS_MSK_IAM"
+	conf.Net.SASL.MechanismClientFunc = func(broker *Broker) (SASLMechanismClient, error) {
+		if broker.Addr() != mockBroker.Addr() {
+			t.Errorf("unexpected broker %s", broker.Addr())
+		}
+		lock.Lock()
+		defer lock.Unlock()
+		client := &testSASLMechanismClient{lifetime: 100 * time.Millisecond}
+		clients = append(clients, client)
+		return client, nil
+	}
+	if err := conf.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	broker := NewBroker(mockBroker.Addr())
+	if err := broker.Open(conf); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = broker.Close() })
+	if connected, err := broker.Connected(); err != nil || !connected {
+		t.Fatal(err)
+	}
+
+	var handshake *SaslHandshakeRequest
+	var authBytes []string
+	for _, rr := range mockBroker.History() {
+		switch req := rr.Request.(type) {
+		case *SaslHandshakeRequest:
+			handshake = req
+		case *SaslAuthenticateRequest:
+			authBytes = append(authBytes, string(req.SaslAuthBytes))
+		}
+	}
+	if handshake == nil || handshake.Mechanism != "AWS_MSK_IAM" {
+		t.Errorf("expected a handshake for AWS_MSK_IAM, got %+v", handshake)
+	}
+	if len(authBytes) < 2 || authBytes[0] != "client-first" || authBytes[1] != "client-final" {
+		t.Errorf("unexpected SASL exchange %q", authBytes)
+	}
+
+	// the broker returns no session lifetime, re-authentication is driven by the mechanism
+	deadline := time.Now().Add(time.Second)
+	for {
+		lock.Lock()
+		n := len(clients)
+		lock.Unlock()
+		if n >= 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("sasl reauth has not occurred within expected timeframe")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	lock.Lock()
+	first := clients[0]
+	lock.Unlock()
+	if !first.closed.Load() {
+		t.Error("expected the mechanism client to be closed after the exchange")
+	}
+	if len(first.responses) != 2 || string(first.responses[0]) != "challenge" {
+		t.Errorf("unexpected challenges %q", first.responses)
+	}
+
+	conf.Net.SASL.Version = SASLHandshakeV0
+	conf.ApiVersionsRequest = false
+	if err := conf.Validate(); err == nil {
+		t.Error("expected custom mechanisms to require SASL v1")
+	}
+}
+
 // We're not testing encoding/decoding here, so most of the requests/responses will be empty for simplicity's sake
 var brokerTestTable = []struct {
 	version  KafkaVersion
@@ -1529,3 +1773,268 @@ func Test_handleThrottledResponse(t *testing.T) {
 		}
 	})
 }
+
+func waitForDisconnect(t *testing.T, broker *Broker) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if connected, _ := broker.Connected(); !connected {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("connection was not closed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestBrokerMaxIdleTime(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	counting := &countingListener{Listener: listener}
+	mockBroker := NewMockBrokerListener(t, 0, counting)
+	defer mockBroker.Close()
+	mockBroker.SetHandlerByMap(map[string]MockResponse{
+		"MetadataRequest": NewMockMetadataResponse(t),
+	})
+
+	conf := NewTestConfig()
+	conf.Net.MaxIdleTime = 100 * time.Millisecond
+	broker := NewBroker(mockBroker.Addr())
+	if err := broker.Open(conf); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = broker.Close() }()
+	if _, err := broker.GetMetadata(&MetadataRequest{}); err != nil {
+		t.Fatal(err)
+	}
+
+	waitForDisconnect(t, broker)
+
+	// the reaped connection is re-opened by the next request
+	if _, err := broker.GetMetadata(&MetadataRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if n := counting.accepted.Load(); n != 2 {
+		t.Errorf("expected the connection to be re-opened, got %d connections", n)
+	}
+
+	// but not after the broker was closed
+	_ = broker.Close()
+	if _, err := broker.GetMetadata(&MetadataRequest{}); !errors.Is(err, ErrNotConnected) {
+		t.Errorf("expected ErrNotConnected, got %v", err)
+	}
+}
+
+func TestBrokerMaxIdleTimePendingResponses(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	counting := &countingListener{Listener: listener}
+	mockBroker := NewMockBrokerListener(t, 0, counting)
+	defer mockBroker.Close()
+	mockBroker.SetHandlerByMap(map[string]MockResponse{
+		"ProduceRequest": NewMockProduceResponse(t),
+	})
+	// the response takes longer than the connection may be idle
+	mockBroker.SetLatency(300 * time.Millisecond)
+
+	conf := NewTestConfig()
+	conf.Net.MaxIdleTime = 100 * time.Millisecond
+	broker := NewBroker(mockBroker.Addr())
+	if err := broker.Open(conf); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = broker.Close() }()
+
+	done := make(chan error, 1)
+	request := &ProduceRequest{RequiredAcks: WaitForLocal}
+	request.AddMessage("my_topic", 0, &Message{Value: []byte("foo")})
+	if err := broker.AsyncProduce(request, func(_ *ProduceResponse, err error) { done <- err }); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("expected the produce to succeed while waiting for its response, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no produce response")
+	}
+	mockBroker.SetLatency(0)
+	if _, err := broker.Produce(request); err != nil {
+		t.Fatal(err)
+	}
+	if n := counting.accepted.Load(); n != 1 {
+		t.Errorf("expected the connection to be kept open, got %d connections", n)
+	}
+}
+
+func TestBrokerHealthCheckBeforeSend(t *testing.T) {
+	mockBroker := NewMockBroker(t, 0)
+	defer mockBroker.Close()
+	mockBroker.SetHandlerByMap(map[string]MockResponse{
+		"MetadataRequest":    NewMockMetadataResponse(t),
+		"ApiVersionsRequest": NewMockApiVersionsResponse(t),
+	})
+
+	conf := NewTestConfig()
+	conf.Version = V1_0_0_0
+	conf.Net.HealthCheckInterval = time.Hour
+	conf.Net.ReadTimeout = 200 * time.Millisecond
+	broker := NewBroker(mockBroker.Addr())
+	if err := broker.Open(conf); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = broker.Close() }()
+	if _, err := broker.GetMetadata(&MetadataRequest{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// pretend the connection has been idle for longer than the interval,
+	// before the periodic health check
+	backdate := func() {
+		broker.lock.Lock()
+		defer broker.lock.Unlock()
+		broker.lastActivity.Store(time.Now().Add(-2 * time.Hour).UnixNano())
+		broker.lastHealthCheck = time.Now().Add(-2 * time.Hour)
+	}
+	backdate()
+	if _, err := broker.GetMetadata(&MetadataRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	history := mockBroker.History()
+	if len(history) != 3 {
+		t.Fatalf("expected 3 requests, got %d", len(history))
+	}
+	if _, ok := history[1].Request.(*ApiVersionsRequest); !ok {
+		t.Errorf("expected the idle connection to be health checked before the request, got %T", history[1].Request)
+	}
+
+	// a connection failing the health check is re-opened for the request
+	mockBroker.SetHandlerByMap(map[string]MockResponse{
+		"MetadataRequest": NewMockMetadataResponse(t),
+	})
+	backdate()
+	if _, err := broker.GetMetadata(&MetadataRequest{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBrokerHealthCheck(t *testing.T) {
+	mockBroker := NewMockBroker(t, 0)
+	defer mockBroker.Close()
+	mockBroker.SetHandlerByMap(map[string]MockResponse{
+		"MetadataRequest":    NewMockMetadataResponse(t),
+		"ApiVersionsRequest": NewMockApiVersionsResponse(t),
+	})
+
+	conf := NewTestConfig()
+	conf.Version = V1_0_0_0
+	conf.Net.HealthCheckInterval = 50 * time.Millisecond
+	conf.Net.ReadTimeout = 200 * time.Millisecond
+	broker := NewBroker(mockBroker.Addr())
+	if err := broker.Open(conf); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = broker.Close() }()
+	if _, err := broker.GetMetadata(&MetadataRequest{}); err != nil {
+		t.Fatal(err)
+	}
+
+	countHealthChecks := func() (n int) {
+		for _, rr := range mockBroker.History() {
+			if _, ok := rr.Request.(*ApiVersionsRequest); ok {
+				n++
+			}
+		}
+		return n
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for countHealthChecks() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("idle connection was not health checked")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if connected, _ := broker.Connected(); !connected {
+		t.Fatal("expected the healthy connection to be kept open")
+	}
+
+	// a broker no longer responding fails the health check
+	mockBroker.SetHandlerByMap(map[string]MockResponse{
+		"MetadataRequest": NewMockMetadataResponse(t),
+	})
+	waitForDisconnect(t, broker)
+
+	if _, err := broker.GetMetadata(&MetadataRequest{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBrokerUseAllDNSIPs(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	movedListener, err := net.Listen("tcp", net.JoinHostPort("127.0.0.3", port))
+	if err != nil {
+		t.Skipf("cannot listen on another loopback address: %s", err)
+	}
+	mockBroker := NewMockBrokerListener(t, 0, listener)
+	defer mockBroker.Close()
+	movedBroker := NewMockBrokerListener(t, 0, movedListener)
+	defer movedBroker.Close()
+	for _, b := range []*MockBroker{mockBroker, movedBroker} {
+		b.SetHandlerByMap(map[string]MockResponse{
+			"MetadataRequest": NewMockMetadataResponse(t),
+		})
+	}
+
+	var lock sync.Mutex
+	resolved := []string{"127.0.0.2", "127.0.0.1"}
+	defaultLookupHost := lookupHost
+	lookupHost = func(ctx context.Context, host string) ([]string, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		if host != "kafka.test" {
+			return nil, fmt.Errorf("unexpected host %s", host)
+		}
+		return resolved, nil
+	}
+	t.Cleanup(func() { lookupHost = defaultLookupHost })
+
+	conf := NewTestConfig()
+	conf.Net.UseAllDNSIPs = true
+	broker := NewBroker(net.JoinHostPort("kafka.test", port))
+	if err := broker.Open(conf); err != nil {
+		t.Fatal(err)
+	}
+	// the first address is unreachable
+	if _, err := broker.GetMetadata(&MetadataRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(mockBroker.History()) != 1 {
+		t.Error("expected the request to be sent to the second address")
+	}
+	_ = broker.Close()
+
+	// the broker moved
+	lock.Lock()
+	resolved = []string{"127.0.0.3"}
+	lock.Unlock()
+	if err := broker.Open(conf); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := broker.GetMetadata(&MetadataRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(movedBroker.History()) != 1 {
+		t.Error("expected the host name to be resolved again")
+	}
+	_ = broker.Close()
+}
@@ -0,0 +1,209 @@
+package sarama
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// captureMagic starts the capture files, followed by the capture records.
+var captureMagic = []byte("SRMCAP01")
+
+// CaptureRecord is a request written to or a response read from a broker,
+// as recorded in a capture file (see Config.Net.Capture).
+type CaptureRecord struct {
+	// Time is when the request was written or the response read.
+	Time time.Time
+	// Response is true for responses, false for requests.
+	Response bool
+	// BrokerID is the id of the broker, -1 for seed brokers.
+	BrokerID int32
+	// ConnectionID identifies the broker connection the request was written
+	// to or the response read from, unique within the process that wrote the
+	// capture. Correlation ids are only unique within a connection.
+	ConnectionID int64
+	// APIKey and APIVersion are those of the request, and of the request
+	// the response answers to.
+	APIKey        int16
+	APIVersion    int16
+	CorrelationID int32
+	// Frame is the request or response as sent on the wire, header included,
+	// without its size prefix.
+	Frame []byte
+}
+
+func (r *CaptureRecord) encode(pe packetEncoder) error {
+	pe.putInt64(r.Time.UnixNano())
+	pe.putBool(r.Response)
+	pe.putInt32(r.BrokerID)
+	pe.putInt64(r.ConnectionID)
+	pe.putInt16(r.APIKey)
+	pe.putInt16(r.APIVersion)
+	pe.putInt32(r.CorrelationID)
+	return pe.putBytes(r.Frame)
+}
+
+func (r *CaptureRecord) decode(pd packetDecoder) (err error) {
+	nanos, err := pd.getInt64()
+	if err != nil {
+		return err
+	}
+	r.Time = time.Unix(0, nanos)
+
+	if r.Response, err = pd.getBool(); err != nil {
+		return err
+	}
+	if r.BrokerID, err = pd.getInt32(); err != nil {
+		return err
+	}
+	if r.ConnectionID, err = pd.getInt64(); err != nil {
+		return err
+	}
+	if r.APIKey, err = pd.getInt16(); err != nil {
+		return err
+	}
+	if r.APIVersion, err = pd.getInt16(); err != nil {
+		return err
+	}
+	if r.CorrelationID, err = pd.getInt32(); err != nil {
+		return err
+	}
+	r.Frame, err = pd.getBytes()
+	return err
+}
+
+// Decode decodes the frame of the record, returning the *XxxRequest or
+// *XxxResponse it holds, e.g. a *MetadataRequest or a *MetadataResponse.
+func (r *CaptureRecord) Decode() (interface{}, error) {
+	if !r.Response {
+		req := &request{}
+		if err := decode(r.Frame, req, nil); err != nil {
+			return nil, err
+		}
+		return req.body, nil
+	}
+
+	res := allocateResponseBody(r.APIKey, r.APIVersion)
+	if res == nil {
+		return nil, PacketDecodingError{fmt.Sprintf("unknown response key (%d)", r.APIKey)}
+	}
+	// the frame starts with the response header, without its length
+	headerLength := int(getHeaderLength(res.headerVersion())) - 4
+	if len(r.Frame) < headerLength {
+		return nil, ErrInsufficientData
+	}
+	if err := versionedDecode(r.Frame[headerLength:], res, r.APIVersion, nil); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// CaptureWriter writes the requests written to and the responses read from
+// the brokers to a capture file, to be read with a CaptureReader, e.g. with
+// the tools/kafka-protocol-replay command. It is safe for concurrent use by
+// the brokers it is configured for.
+type CaptureWriter struct {
+	lock sync.Mutex
+	w    io.Writer
+	err  error
+}
+
+// NewCaptureWriter returns a CaptureWriter writing the capture to w.
+func NewCaptureWriter(w io.Writer) (*CaptureWriter, error) {
+	if _, err := w.Write(captureMagic); err != nil {
+		return nil, err
+	}
+	return &CaptureWriter{w: w}, nil
+}
+
+// Write writes record to the capture. Once a write has failed, the error is
+// returned for all subsequent writes.
+func (c *CaptureWriter) Write(record *CaptureRecord) error {
+	buf, err := encode(record, nil)
+	if err != nil {
+		return err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.err != nil {
+		return c.err
+	}
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], uint32(len(buf)))
+	if _, c.err = c.w.Write(size[:]); c.err != nil {
+		return c.err
+	}
+	_, c.err = c.w.Write(buf)
+	return c.err
+}
+
+// CaptureReader reads the records of a capture file written by a
+// CaptureWriter.
+type CaptureReader struct {
+	r *bufio.Reader
+}
+
+// NewCaptureReader returns a CaptureReader reading the capture from r.
+func NewCaptureReader(r io.Reader) (*CaptureReader, error) {
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(captureMagic))
+	if _, err := io.ReadFull(br, magic); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(magic, captureMagic) {
+		return nil, errors.New("kafka: not a capture file")
+	}
+	return &CaptureReader{r: br}, nil
+}
+
+// Next returns the next record of the capture, or io.EOF once all the
+// records have been read.
+func (c *CaptureReader) Next() (*CaptureRecord, error) {
+	var size [4]byte
+	if _, err := io.ReadFull(c.r, size[:]); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, binary.BigEndian.Uint32(size[:]))
+	if _, err := io.ReadFull(c.r, buf); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	record := &CaptureRecord{}
+	if err := decode(buf, record, nil); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// capture records the frame made of header and body to the configured
+// CaptureWriter, if any. The SASL handshake and authenticate requests and
+// their responses are never recorded, as they hold the credentials.
+func (b *Broker) capture(response bool, key, version int16, correlationID int32, header, body []byte) {
+	if b.conf.Net.Capture == nil || key == apiKeySaslHandshake || key == apiKeySASLAuth {
+		return
+	}
+	frame := make([]byte, 0, len(header)+len(body))
+	frame = append(append(frame, header...), body...)
+	err := b.conf.Net.Capture.Write(&CaptureRecord{
+		Time:          time.Now(),
+		Response:      response,
+		BrokerID:      b.id,
+		ConnectionID:  b.connectionID,
+		APIKey:        key,
+		APIVersion:    version,
+		CorrelationID: correlationID,
+		Frame:         frame,
+	})
+	if err != nil {
+		b.logger().Warn("Failed to write to the capture", "err", err)
+	}
+}
@@ -0,0 +1,272 @@
+//go:build !functional
+
+package sarama
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestBrokerCapture(t *testing.T) {
+	seedBroker := NewMockBroker(t, 1)
+	defer seedBroker.Close()
+
+	seedBroker.SetHandlerByMap(map[string]MockResponse{
+		"MetadataRequest": NewMockMetadataResponse(t).
+			SetBroker(seedBroker.Addr(), seedBroker.BrokerID()).
+			SetLeader("my_topic", 0, seedBroker.BrokerID()),
+	})
+
+	var buf bytes.Buffer
+	capture, err := NewCaptureWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := NewTestConfig()
+	conf.Version = V1_0_0_0
+	conf.Net.Capture = capture
+	broker := NewBroker(seedBroker.Addr())
+	if err := broker.Open(conf); err != nil {
+		t.Fatal(err)
+	}
+	expected, err := broker.GetMetadata(&MetadataRequest{Version: 5, Topics: []string{"my_topic"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	safeClose(t, broker)
+
+	reader, err := NewCaptureReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var records []*CaptureRecord
+	for {
+		record, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, record)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Expected a request and a response to be captured, got %d records", len(records))
+	}
+	for i, record := range records {
+		if record.Response != (i == 1) || record.BrokerID != -1 || record.APIKey != apiKeyMetadata ||
+			record.APIVersion != 5 || record.CorrelationID != 0 || record.Time.IsZero() ||
+			record.ConnectionID == 0 || record.ConnectionID != records[0].ConnectionID {
+			t.Errorf("Unexpected record %+v", record)
+		}
+	}
+	request, err := records[0].Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if request, ok := request.(*MetadataRequest); !ok || !reflect.DeepEqual(request.Topics, []string{"my_topic"}) {
+		t.Errorf("Unexpected captured request %#v", request)
+	}
+	response, err := records[1].Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(response, expected) {
+		t.Errorf("Expected captured response %#v, got %#v", expected, response)
+	}
+
+	// replay the captured response to another broker
+	replayBroker := NewMockBroker(t, 2)
+	defer replayBroker.Close()
+	replayBroker.SetHandlerByMap(map[string]MockResponse{
+		"MetadataRequest": NewMockCaptureResponse(t, records),
+	})
+	conf = NewTestConfig()
+	conf.Version = V1_0_0_0
+	broker = NewBroker(replayBroker.Addr())
+	if err := broker.Open(conf); err != nil {
+		t.Fatal(err)
+	}
+	defer safeClose(t, broker)
+	replayed, err := broker.GetMetadata(&MetadataRequest{Version: 5, Topics: []string{"my_topic"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(replayed, expected) {
+		t.Errorf("Expected replayed response %#v, got %#v", expected, replayed)
+	}
+}
+
+func TestBrokerCaptureConnectionID(t *testing.T) {
+	seedBroker := NewMockBroker(t, 1)
+	defer seedBroker.Close()
+
+	seedBroker.SetHandlerByMap(map[string]MockResponse{
+		"MetadataRequest": NewMockMetadataResponse(t).
+			SetBroker(seedBroker.Addr(), seedBroker.BrokerID()),
+	})
+
+	var buf bytes.Buffer
+	capture, err := NewCaptureWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := NewTestConfig()
+	conf.Net.Capture = capture
+	// the correlation ids start over on each connection
+	for range 2 {
+		broker := NewBroker(seedBroker.Addr())
+		if err := broker.Open(conf); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := broker.GetMetadata(&MetadataRequest{}); err != nil {
+			t.Fatal(err)
+		}
+		safeClose(t, broker)
+	}
+
+	reader, err := NewCaptureReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var records []*CaptureRecord
+	for {
+		record, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, record)
+	}
+	if len(records) != 4 {
+		t.Fatalf("Expected 2 requests and responses to be captured, got %d records", len(records))
+	}
+	if records[0].CorrelationID != records[2].CorrelationID {
+		t.Fatalf("Expected the correlation ids to start over, got %d and %d", records[0].CorrelationID, records[2].CorrelationID)
+	}
+	if records[0].ConnectionID != records[1].ConnectionID || records[2].ConnectionID != records[3].ConnectionID ||
+		records[0].ConnectionID == records[2].ConnectionID {
+		t.Errorf("Expected a connection id per connection, got %d, %d, %d and %d",
+			records[0].ConnectionID, records[1].ConnectionID, records[2].ConnectionID, records[3].ConnectionID)
+	}
+}
+
+func TestBrokerCaptureSkipsSASL(t *testing.T) {
+	seedBroker := NewMockBroker(t, 1)
+	defer seedBroker.Close()
+
+	seedBroker.SetHandlerByMap(map[string]MockResponse{
+		"SaslHandshakeRequest": NewMockSaslHandshakeResponse(t).
+			SetEnabledMechanisms([]string{SASLTypePlaintext}),
+		"SaslAuthenticateRequest": NewMockSaslAuthenticateResponse(t),
+		"MetadataRequest": NewMockMetadataResponse(t).
+			SetBroker(seedBroker.Addr(), seedBroker.BrokerID()),
+	})
+
+	var buf bytes.Buffer
+	capture, err := NewCaptureWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := NewTestConfig()
+	conf.Version = V1_0_0_0
+	conf.Net.SASL.Enable = true
+	conf.Net.SASL.Mechanism = SASLTypePlaintext
+	conf.Net.SASL.Version = SASLHandshakeV1
+	conf.Net.SASL.User = "token"
+	conf.Net.SASL.Password = "secret-password"
+	conf.Net.Capture = capture
+	broker := NewBroker(seedBroker.Addr())
+	if err := broker.Open(conf); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := broker.GetMetadata(&MetadataRequest{Version: 5}); err != nil {
+		t.Fatal(err)
+	}
+	safeClose(t, broker)
+
+	if bytes.Contains(buf.Bytes(), []byte(conf.Net.SASL.Password)) {
+		t.Error("Expected the SASL password not to be captured")
+	}
+	reader, err := NewCaptureReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	metadata := 0
+	for {
+		record, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		switch record.APIKey {
+		case apiKeySaslHandshake, apiKeySASLAuth:
+			t.Errorf("Unexpected captured SASL record %+v", record)
+		case apiKeyMetadata:
+			metadata++
+		}
+	}
+	if metadata != 2 {
+		t.Errorf("Expected the metadata request and response to be captured, got %d records", metadata)
+	}
+}
+
+func TestCaptureReaderInvalidFile(t *testing.T) {
+	if _, err := NewCaptureReader(bytes.NewReader([]byte("not a capture"))); err == nil {
+		t.Error("Expected an error reading an invalid capture file")
+	}
+}
+
+// errorRecorder is a TestReporter recording the errors reported with Errorf.
+type errorRecorder struct {
+	*testing.T
+	errors []string
+}
+
+func (r *errorRecorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestMockCaptureResponseMalformedRecords(t *testing.T) {
+	reporter := &errorRecorder{T: t}
+	mock := NewMockCaptureResponse(reporter, []*CaptureRecord{
+		{Response: true, APIKey: apiKeyMetadata, APIVersion: 5, Frame: []byte{0, 0}},
+	})
+
+	if res := mock.For(&MetadataRequest{Version: 5}); res != nil {
+		t.Errorf("Expected no response, got %#v", res)
+	}
+	if len(reporter.errors) != 1 {
+		t.Errorf("Expected the truncated response to be reported, got %v", reporter.errors)
+	}
+}
+
+func TestMockCaptureResponseUnansweredRequests(t *testing.T) {
+	body, err := encode(&ProduceResponse{Version: 3}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reporter := &errorRecorder{T: t}
+	mock := NewMockCaptureResponse(reporter, []*CaptureRecord{
+		// a produce without response, then one with
+		{APIKey: apiKeyProduce, APIVersion: 3, ConnectionID: 1, CorrelationID: 0},
+		{APIKey: apiKeyProduce, APIVersion: 3, ConnectionID: 1, CorrelationID: 1},
+		{Response: true, APIKey: apiKeyProduce, APIVersion: 3, ConnectionID: 1, CorrelationID: 1, Frame: append([]byte{0, 0, 0, 1}, body...)},
+	})
+
+	if res := mock.For(&ProduceRequest{Version: 3, RequiredAcks: NoResponse}); res != nil {
+		t.Errorf("Expected no response to the produce without acks, got %#v", res)
+	}
+	res, ok := mock.For(&ProduceRequest{Version: 3, RequiredAcks: WaitForLocal}).(*capturedResponse)
+	if !ok || !bytes.Equal(res.body, body) {
+		t.Errorf("Expected the captured response, got %#v", res)
+	}
+	if len(reporter.errors) != 0 {
+		t.Errorf("Expected no error, got %v", reporter.errors)
+	}
+}
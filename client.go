@@ -147,8 +147,11 @@ type client struct {
 	updateMetadataMs atomic.Int64
 
 	conf           *Config
+	log            *logger
 	closer, closed chan none // for shutting down background metadata updater
 
+	telemetry *telemetryReporter // nil unless Telemetry.Enable is set
+
 	// the broker addresses given to us through the constructor are not guaranteed to be returned in
 	// the cluster metadata (I *think* it only returns brokers who are currently leading partitions?)
 	// so we store them separately
@@ -175,11 +178,11 @@ type client struct {
 // and uses that broker to automatically fetch metadata on the rest of the kafka cluster. If metadata cannot
 // be retrieved from any of the given broker addresses, the client is not created.
 func NewClient(addrs []string, conf *Config) (Client, error) {
-	DebugLogger.Println("Initializing new client")
-
 	if conf == nil {
 		conf = NewConfig()
 	}
+	log := newLogger(conf)
+	log.Debug("Initializing new client")
 
 	if err := conf.Validate(); err != nil {
 		return nil, err
@@ -191,12 +194,13 @@ func NewClient(addrs []string, conf *Config) (Client, error) {
 
 	if strings.Contains(addrs[0], ".servicebus.windows.net") {
 		if conf.Version.IsAtLeast(V1_1_0_0) || !conf.Version.IsAtLeast(V0_11_0_0) {
-			Logger.Println("Connecting to Azure Event Hubs, forcing version to V1_0_0_0 for compatibility")
+			log.Warn("Connecting to Azure Event Hubs, forcing version to V1_0_0_0 for compatibility")
 			conf.Version = V1_0_0_0
 		}
 	}
 	client := &client{
 		conf:                    conf,
+		log:                     log,
 		closer:                  make(chan none),
 		closed:                  make(chan none),
 		brokers:                 make(map[int32]*Broker),
@@ -235,7 +239,7 @@ func NewClient(addrs []string, conf *Config) (Client, error) {
 		if err == nil {
 		} else if errors.Is(err, ErrLeaderNotAvailable) || errors.Is(err, ErrReplicaNotAvailable) || errors.Is(err, ErrTopicAuthorizationFailed) || errors.Is(err, ErrClusterAuthorizationFailed) {
 			// indicates that maybe part of the cluster is down, but is not fatal to creating the client
-			Logger.Println(err)
+			log.Warn("client/metadata initial fetch failed", "err", err)
 		} else {
 			close(client.closed) // we haven't started the background updater yet, so we have to do this manually
 			_ = client.Close()
@@ -243,8 +247,12 @@ func NewClient(addrs []string, conf *Config) (Client, error) {
 		}
 	}
 	go withRecover(client.backgroundMetadataUpdater)
+	if conf.Telemetry.Enable {
+		client.telemetry = newTelemetryReporter(client)
+		go withRecover(client.telemetry.run)
+	}
 
-	DebugLogger.Println("Successfully initialized new client")
+	log.Debug("Successfully initialized new client")
 
 	return client, nil
 }
@@ -299,7 +307,7 @@ func (client *client) InitProducerID() (*InitProducerIDResponse, error) {
 			return response, nil
 		} else {
 			// some error, remove that broker and try again
-			Logger.Printf("Client got error from broker %d when issuing InitProducerID : %v\n", broker.ID(), err)
+			client.log.Warn("Client got error from broker when issuing InitProducerID", "broker", broker.ID(), "err", err)
 			_ = broker.Close()
 			brokerErrors = append(brokerErrors, err)
 			client.deregisterBroker(broker)
@@ -313,17 +321,21 @@ func (client *client) Close() error {
 	if client.Closed() {
 		// Chances are this is being called from a defer() and the error will go unobserved
 		// so we go ahead and log the event in this case.
-		Logger.Printf("Close() called on already closed client")
+		client.log.Warn("Close() called on already closed client")
 		return ErrClosedClient
 	}
 
-	// shutdown and wait for the background thread before we take the lock, to avoid races
+	// shutdown and wait for the background threads before we take the lock, to avoid races
+	if client.telemetry != nil {
+		close(client.telemetry.closer)
+		<-client.telemetry.closed
+	}
 	close(client.closer)
 	<-client.closed
 
 	client.lock.Lock()
 	defer client.lock.Unlock()
-	DebugLogger.Println("Closing Client")
+	client.log.Debug("Closing Client")
 
 	for _, broker := range client.brokers {
 		safeAsyncClose(broker)
@@ -691,11 +703,11 @@ func (client *client) updateBroker(brokers []*Broker) {
 		currentBroker[broker.ID()] = broker
 		if client.brokers[broker.ID()] == nil { // add new broker
 			client.brokers[broker.ID()] = broker
-			DebugLogger.Printf("client/brokers registered new broker #%d at %s", broker.ID(), broker.Addr())
+			client.log.Debug("client/brokers registered new broker", "broker", broker.ID(), "addr", broker.Addr())
 		} else if broker.Addr() != client.brokers[broker.ID()].Addr() { // replace broker with new address
 			safeAsyncClose(client.brokers[broker.ID()])
 			client.brokers[broker.ID()] = broker
-			Logger.Printf("client/brokers replaced registered broker #%d with %s", broker.ID(), broker.Addr())
+			client.log.Info("client/brokers replaced registered broker", "broker", broker.ID(), "addr", broker.Addr())
 		}
 	}
 
@@ -703,7 +715,7 @@ func (client *client) updateBroker(brokers []*Broker) {
 		if _, exist := currentBroker[id]; !exist { // remove old broker
 			safeAsyncClose(broker)
 			delete(client.brokers, id)
-			Logger.Printf("client/broker remove invalid broker #%d with %s", broker.ID(), broker.Addr())
+			client.log.Info("client/brokers removed invalid broker", "broker", broker.ID(), "addr", broker.Addr())
 		}
 	}
 }
@@ -713,17 +725,17 @@ func (client *client) updateBroker(brokers []*Broker) {
 // or a previously registered Broker instance. You must hold the write lock before calling this function.
 func (client *client) registerBroker(broker *Broker) {
 	if client.brokers == nil {
-		Logger.Printf("cannot register broker #%d at %s, client already closed", broker.ID(), broker.Addr())
+		client.log.Warn("client/brokers cannot register broker, client already closed", "broker", broker.ID(), "addr", broker.Addr())
 		return
 	}
 
 	if client.brokers[broker.ID()] == nil {
 		client.brokers[broker.ID()] = broker
-		DebugLogger.Printf("client/brokers registered new broker #%d at %s", broker.ID(), broker.Addr())
+		client.log.Debug("client/brokers registered new broker", "broker", broker.ID(), "addr", broker.Addr())
 	} else if broker.Addr() != client.brokers[broker.ID()].Addr() {
 		safeAsyncClose(client.brokers[broker.ID()])
 		client.brokers[broker.ID()] = broker
-		Logger.Printf("client/brokers replaced registered broker #%d with %s", broker.ID(), broker.Addr())
+		client.log.Info("client/brokers replaced registered broker", "broker", broker.ID(), "addr", broker.Addr())
 	}
 }
 
@@ -735,7 +747,7 @@ func (client *client) deregisterBroker(broker *Broker) {
 
 	_, ok := client.brokers[broker.ID()]
 	if ok {
-		Logger.Printf("client/brokers deregistered broker #%d at %s", broker.ID(), broker.Addr())
+		client.log.Info("client/brokers deregistered broker", "broker", broker.ID(), "addr", broker.Addr())
 		delete(client.brokers, broker.ID())
 		return
 	}
@@ -749,7 +761,7 @@ func (client *client) resurrectDeadBrokers() {
 	client.lock.Lock()
 	defer client.lock.Unlock()
 
-	Logger.Printf("client/brokers resurrecting %d dead seed brokers", len(client.deadSeeds))
+	client.log.Info("client/brokers resurrecting dead seed brokers", "count", len(client.deadSeeds))
 	client.seedBrokers = append(client.seedBrokers, client.deadSeeds...)
 	client.deadSeeds = nil
 }
@@ -906,7 +918,7 @@ func (client *client) backgroundMetadataUpdater() {
 		select {
 		case <-ticker.C:
 			if err := client.refreshMetadata(); err != nil {
-				Logger.Println("Client background metadata update:", err)
+				client.log.Warn("client/metadata background metadata update failed", "err", err)
 			}
 		case <-client.closer:
 			return
@@ -946,7 +958,7 @@ func (client *client) tryRefreshMetadata(topics []string, attemptsRemaining int,
 		if attemptsRemaining > 0 {
 			backoff := client.computeBackoff(attemptsRemaining)
 			if pastDeadline(backoff) {
-				Logger.Println("client/metadata skipping last retries as we would go past the metadata timeout")
+				client.log.Warn("client/metadata skipping last retries as we would go past the metadata timeout")
 				return err
 			}
 			if backoff > 0 {
@@ -958,7 +970,7 @@ func (client *client) tryRefreshMetadata(topics []string, attemptsRemaining int,
 				return err
 			}
 			attemptsRemaining--
-			Logger.Printf("client/metadata retrying after %dms... (%d attempts remaining)\n", backoff/time.Millisecond, attemptsRemaining)
+			client.log.Info("client/metadata retrying", "backoff", backoff, "attempts_remaining", attemptsRemaining)
 
 			return client.tryRefreshMetadata(topics, attemptsRemaining, deadline)
 		}
@@ -970,10 +982,10 @@ func (client *client) tryRefreshMetadata(topics []string, attemptsRemaining int,
 	for ; broker != nil && !pastDeadline(0); broker = client.LeastLoadedBroker() {
 		allowAutoTopicCreation := client.conf.Metadata.AllowAutoTopicCreation
 		if len(topics) > 0 {
-			DebugLogger.Printf("client/metadata fetching metadata for %v from broker %s\n", topics, broker.addr)
+			client.log.Debug("client/metadata fetching metadata", "topics", topics, "broker", broker.ID(), "addr", broker.addr)
 		} else {
 			allowAutoTopicCreation = false
-			DebugLogger.Printf("client/metadata fetching metadata for all topics from broker %s\n", broker.addr)
+			client.log.Debug("client/metadata fetching metadata for all topics", "broker", broker.ID(), "addr", broker.addr)
 		}
 
 		req := NewMetadataRequest(client.conf.Version, topics)
@@ -986,7 +998,7 @@ func (client *client) tryRefreshMetadata(topics []string, attemptsRemaining int,
 		if err == nil {
 			// When talking to the startup phase of a broker, it is possible to receive an empty metadata set. We should remove that broker and try next broker (https://issues.apache.org/jira/browse/KAFKA-7924).
 			if len(response.Brokers) == 0 {
-				Logger.Printf("client/metadata receiving empty brokers from the metadata response when requesting the broker #%d at %s", broker.ID(), broker.addr)
+				client.log.Warn("client/metadata received empty brokers in the metadata response", "broker", broker.ID(), "addr", broker.addr)
 				_ = broker.Close()
 				client.deregisterBroker(broker)
 				continue
@@ -995,7 +1007,7 @@ func (client *client) tryRefreshMetadata(topics []string, attemptsRemaining int,
 			// valid response, use it
 			shouldRetry, err := client.updateMetadata(response, allKnownMetaData)
 			if shouldRetry {
-				Logger.Println("client/metadata found some partitions to be leaderless")
+				client.log.Info("client/metadata found some partitions to be leaderless")
 				return retry(err) // note: err can be nil
 			}
 			return err
@@ -1005,21 +1017,21 @@ func (client *client) tryRefreshMetadata(topics []string, attemptsRemaining int,
 		} else if errors.As(err, &kerror) {
 			// if SASL auth error return as this _should_ be a non retryable err for all brokers
 			if errors.Is(err, ErrSASLAuthenticationFailed) {
-				Logger.Println("client/metadata failed SASL authentication")
+				client.log.Error("client/metadata failed SASL authentication")
 				return err
 			}
 
 			if errors.Is(err, ErrTopicAuthorizationFailed) {
-				Logger.Println("client is not authorized to access this topic. The topics were: ", topics)
+				client.log.Error("client/metadata not authorized to access the topics", "topics", topics)
 				return err
 			}
 			// else remove that broker and try again
-			Logger.Printf("client/metadata got error from broker %d while fetching metadata: %v\n", broker.ID(), err)
+			client.log.Warn("client/metadata got error from broker while fetching metadata", "broker", broker.ID(), "err", err)
 			_ = broker.Close()
 			client.deregisterBroker(broker)
 		} else {
 			// some other error, remove that broker and try again
-			Logger.Printf("client/metadata got error from broker %d while fetching metadata: %v\n", broker.ID(), err)
+			client.log.Warn("client/metadata got error from broker while fetching metadata", "broker", broker.ID(), "err", err)
 			brokerErrors = append(brokerErrors, err)
 			_ = broker.Close()
 			client.deregisterBroker(broker)
@@ -1028,11 +1040,11 @@ func (client *client) tryRefreshMetadata(topics []string, attemptsRemaining int,
 
 	error := Wrap(ErrOutOfBrokers, brokerErrors...)
 	if broker != nil {
-		Logger.Printf("client/metadata not fetching metadata from broker %s as we would go past the metadata timeout\n", broker.addr)
+		client.log.Warn("client/metadata not fetching metadata from broker as we would go past the metadata timeout", "broker", broker.ID(), "addr", broker.addr)
 		return retry(error)
 	}
 
-	Logger.Println("client/metadata no available broker to send metadata request to")
+	client.log.Warn("client/metadata no available broker to send metadata request to")
 	client.resurrectDeadBrokers()
 	return retry(error)
 }
@@ -1046,6 +1058,12 @@ func (client *client) updateMetadata(data *MetadataResponse, allKnownMetaData bo
 	client.lock.Lock()
 	defer client.lock.Unlock()
 
+	previous := client.metadata
+	topics := make([]string, 0, len(data.Topics))
+	defer func() {
+		notifyEvent(client.conf, &MetadataRefreshed{Topics: topics})
+	}()
+
 	// For all the brokers we received:
 	// - if it is a new ID, save it
 	// - if it is an existing ID, but the address we have is stale, discard the old one and save it
@@ -1067,6 +1085,7 @@ func (client *client) updateMetadata(data *MetadataResponse, allKnownMetaData bo
 		if _, exists := client.metadataTopics[topic.Name]; !exists {
 			client.metadataTopics[topic.Name] = none{}
 		}
+		oldPartitions := previous[topic.Name]
 		delete(client.metadata, topic.Name)
 		delete(client.cachedPartitionsResults, topic.Name)
 
@@ -1083,17 +1102,27 @@ func (client *client) updateMetadata(data *MetadataResponse, allKnownMetaData bo
 		case ErrLeaderNotAvailable: // retry, but store partial partition results
 			retry = true
 		default: // don't retry, don't store partial results
-			Logger.Printf("Unexpected topic-level metadata error: %s", topic.Err)
+			client.log.Error("client/metadata unexpected topic-level metadata error", "topic", topic.Name, "err", topic.Err)
 			err = topic.Err
 			continue
 		}
 
+		topics = append(topics, topic.Name)
 		client.metadata[topic.Name] = make(map[int32]*PartitionMetadata, len(topic.Partitions))
 		for _, partition := range topic.Partitions {
 			client.metadata[topic.Name][partition.ID] = partition
 			if errors.Is(partition.Err, ErrLeaderNotAvailable) {
 				retry = true
 			}
+			if old, ok := oldPartitions[partition.ID]; ok && old.Leader != partition.Leader {
+				notifyEvent(client.conf, &LeaderChanged{
+					Topic:          topic.Name,
+					Partition:      partition.ID,
+					Leader:         partition.Leader,
+					PreviousLeader: old.Leader,
+					LeaderEpoch:    partition.LeaderEpoch,
+				})
+			}
 		}
 
 		var partitionCache [maxPartitionIndex][]int32
@@ -1144,7 +1173,7 @@ func (client *client) findCoordinator(coordinatorKey string, coordinatorType Coo
 		if attemptsRemaining > 0 {
 			backoff := client.computeBackoff(attemptsRemaining)
 			attemptsRemaining--
-			Logger.Printf("client/coordinator retrying after %dms... (%d attempts remaining)\n", backoff/time.Millisecond, attemptsRemaining)
+			client.log.Info("client/coordinator retrying", "backoff", backoff, "attempts_remaining", attemptsRemaining)
 			time.Sleep(backoff)
 			return client.findCoordinator(coordinatorKey, coordinatorType, attemptsRemaining)
 		}
@@ -1153,7 +1182,7 @@ func (client *client) findCoordinator(coordinatorKey string, coordinatorType Coo
 
 	brokerErrors := make([]error, 0)
 	for broker := client.LeastLoadedBroker(); broker != nil; broker = client.LeastLoadedBroker() {
-		DebugLogger.Printf("client/coordinator requesting coordinator for %s from %s\n", coordinatorKey, broker.Addr())
+		client.log.Debug("client/coordinator requesting coordinator", "key", coordinatorKey, "broker", broker.ID(), "addr", broker.Addr())
 
 		request := new(FindCoordinatorRequest)
 		request.CoordinatorKey = coordinatorKey
@@ -1170,7 +1199,7 @@ func (client *client) findCoordinator(coordinatorKey string, coordinatorType Coo
 
 		response, err := broker.FindCoordinator(request)
 		if err != nil {
-			Logger.Printf("client/coordinator request to broker %s failed: %s\n", broker.Addr(), err)
+			client.log.Warn("client/coordinator request to broker failed", "broker", broker.ID(), "addr", broker.Addr(), "err", err)
 
 			var packetEncodingError PacketEncodingError
 			if errors.As(err, &packetEncodingError) {
@@ -1184,35 +1213,35 @@ func (client *client) findCoordinator(coordinatorKey string, coordinatorType Coo
 		}
 
 		if errors.Is(response.Err, ErrNoError) {
-			DebugLogger.Printf("client/coordinator coordinator for %s is #%d (%s)\n", coordinatorKey, response.Coordinator.ID(), response.Coordinator.Addr())
+			client.log.Debug("client/coordinator found coordinator", "key", coordinatorKey, "broker", response.Coordinator.ID(), "addr", response.Coordinator.Addr())
 			return response, nil
 		} else if errors.Is(response.Err, ErrConsumerCoordinatorNotAvailable) {
-			Logger.Printf("client/coordinator coordinator for %s is not available\n", coordinatorKey)
+			client.log.Warn("client/coordinator coordinator is not available", "key", coordinatorKey)
 
 			// This is very ugly, but this scenario will only happen once per cluster.
 			// The __consumer_offsets topic only has to be created one time.
 			// The number of partitions not configurable, but partition 0 should always exist.
 			if _, err := client.Leader("__consumer_offsets", 0); err != nil {
-				Logger.Printf("client/coordinator the __consumer_offsets topic is not initialized completely yet. Waiting 2 seconds...\n")
+				client.log.Info("client/coordinator the __consumer_offsets topic is not initialized completely yet. Waiting 2 seconds...")
 				time.Sleep(2 * time.Second)
 			}
 			if coordinatorType == CoordinatorTransaction {
 				if _, err := client.Leader("__transaction_state", 0); err != nil {
-					Logger.Printf("client/coordinator the __transaction_state topic is not initialized completely yet. Waiting 2 seconds...\n")
+					client.log.Info("client/coordinator the __transaction_state topic is not initialized completely yet. Waiting 2 seconds...")
 					time.Sleep(2 * time.Second)
 				}
 			}
 
 			return retry(ErrConsumerCoordinatorNotAvailable)
 		} else if errors.Is(response.Err, ErrGroupAuthorizationFailed) {
-			Logger.Printf("client was not authorized to access group %s while attempting to find coordinator", coordinatorKey)
+			client.log.Error("client/coordinator not authorized to access group while attempting to find coordinator", "key", coordinatorKey)
 			return retry(ErrGroupAuthorizationFailed)
 		} else {
 			return nil, response.Err
 		}
 	}
 
-	Logger.Println("client/coordinator no available broker to send consumer metadata request to")
+	client.log.Warn("client/coordinator no available broker to send consumer metadata request to")
 	client.resurrectDeadBrokers()
 	return retry(Wrap(ErrOutOfBrokers, brokerErrors...))
 }
@@ -45,6 +45,17 @@ type Config struct {
 		// https://kafka.apache.org/28/documentation.html#producerconfigs_max.in.flight.requests.per.connection
 		MaxOpenRequests int
 
+		// ConnectionsPerBroker is the number of connections opened to each
+		// broker (default 1). Kafka processes the requests of a connection
+		// strictly in order, so additional connections avoid long running
+		// requests blocking others: with 2 connections fetch requests get
+		// their own, with 3 produce requests get their own too, and any
+		// further connections alternate between fetch and all other requests.
+		// Produce requests always use a single connection so that their
+		// ordering is preserved. Each connection authenticates on its own and
+		// is limited to MaxOpenRequests.
+		ConnectionsPerBroker int
+
 		// All three of the below configurations are similar to the
 		// `socket.timeout.ms` setting in JVM kafka. All of them default
 		// to 30 seconds.
@@ -52,6 +63,23 @@ type Config struct {
 		ReadTimeout  time.Duration // How long to wait for a response.
 		WriteTimeout time.Duration // How long to wait for a transmit.
 
+		// MaxIdleTime closes connections on which no request has been sent
+		// and no response received for that long, and which are not waiting
+		// for responses (defaults to 0, disabled). Brokers close idle
+		// connections themselves after `connections.max.idle.ms` (10 minutes
+		// by default), which makes the next request on them fail, so this
+		// should be set lower than that. Connections closed for being idle are
+		// re-opened by the next request.
+		MaxIdleTime time.Duration
+		// HealthCheckInterval sends an ApiVersions request on connections
+		// that have been idle for that long, to detect dead connections before
+		// requests are sent on them (defaults to 0, disabled). Connections are
+		// checked periodically, and before a request is sent on them if they
+		// have not been checked within the interval. Connections failing the
+		// health check are closed, and re-opened by the next request. Health
+		// checks do not count as activity for MaxIdleTime.
+		HealthCheckInterval time.Duration
+
 		// ResolveCanonicalBootstrapServers turns each bootstrap broker address
 		// into a set of IPs, then does a reverse lookup on each one to get its
 		// canonical hostname. This list of hostnames then replaces the
@@ -61,6 +89,15 @@ type Config struct {
 		// hostnames. Defaults to false.
 		ResolveCanonicalBootstrapServers bool
 
+		// UseAllDNSIPs resolves the host name of a broker (bootstrap or
+		// advertised) each time a connection is opened to it, and tries each
+		// of the resolved addresses in turn with the full DialTimeout, like
+		// `client.dns.lookup=use_all_dns_ips` in the JVM client, so that
+		// brokers behind changing DNS records (e.g. in Kubernetes) remain
+		// reachable. The address last connected to is tried first. Cannot be
+		// used with Proxy. Defaults to false.
+		UseAllDNSIPs bool
+
 		TLS struct {
 			// Whether or not to use TLS when connecting to the broker
 			// (defaults to false).
@@ -68,6 +105,17 @@ type Config struct {
 			// The TLS configuration to use for secure connections if
 			// enabled (defaults to nil).
 			Config *tls.Config
+			// CredentialSource, if set, supplies the client certificate and
+			// root CAs whenever a connection is established, replacing
+			// Config.Certificates and Config.RootCAs. Use it to rotate
+			// short-lived certificates without restarting the client, see
+			// FileTLSCredentialSource (defaults to nil).
+			CredentialSource TLSCredentialSource
+			// RecycleBeforeExpiry, if positive, closes broker connections
+			// this long before the client certificate they were established
+			// with expires, so that they are re-established with the current
+			// credentials (defaults to 0, connections are not recycled).
+			RecycleBeforeExpiry time.Duration
 		}
 
 		// SASL based authentication with broker. While there are multiple SASL authentication methods
@@ -77,7 +125,9 @@ type Config struct {
 			// (defaults to false).
 			Enable bool
 			// SASLMechanism is the name of the enabled SASL mechanism.
-			// Possible values: OAUTHBEARER, PLAIN (defaults to PLAIN).
+			// Possible values: OAUTHBEARER, PLAIN, SCRAM-SHA-256, SCRAM-SHA-512,
+			// GSSAPI or any mechanism implemented by MechanismClientFunc
+			// (defaults to PLAIN).
 			Mechanism SASLMechanism
 			// Version is the SASL Protocol Version to use
 			// Kafka > 1.x should use V1, except on Azure EventHub which use V0
@@ -104,8 +154,16 @@ type Config struct {
 			// TokenProvider is a user-defined callback for generating
 			// access tokens for SASL/OAUTHBEARER auth. See the
 			// AccessTokenProvider interface docs for proper implementation
-			// guidelines.
+			// guidelines, or use ClientCredentialsTokenProvider for the OAuth
+			// 2.0 client credentials grant.
 			TokenProvider AccessTokenProvider
+			// MechanismClientFunc, if set, creates the client performing the
+			// exchange with broker instead of the built-in implementation of
+			// Mechanism, which may then name any mechanism enabled on the
+			// broker (for example AWS_MSK_IAM). A new client is created for
+			// every authentication and re-authentication. Custom mechanisms
+			// require SASL v1.
+			MechanismClientFunc func(broker *Broker) (SASLMechanismClient, error)
 
 			GSSAPI GSSAPIConfig
 		}
@@ -121,6 +179,13 @@ type Config struct {
 		// If nil, a local address is automatically chosen.
 		LocalAddr net.Addr
 
+		// Capture, if set, records the requests written to and the responses
+		// read from the brokers, with their correlation ids and timestamps,
+		// e.g. to debug protocol issues with the tools/kafka-protocol-replay
+		// command (defaults to nil). The SASL authentication exchanges are not
+		// recorded.
+		Capture *CaptureWriter
+
 		Proxy struct {
 			// Whether or not to use proxy when connecting to the broker
 			// (defaults to false).
@@ -177,6 +242,16 @@ type Config struct {
 		SingleFlight bool
 	}
 
+	// Telemetry is the namespace for the client metrics pushed to the brokers
+	// (KIP-714), used by the Client.
+	Telemetry struct {
+		// Whether to push the metrics of MetricRegistry requested by the client
+		// metrics subscriptions configured on the brokers (defaults to false).
+		// Requires Version >= V3_7_0_0. The metrics reported to a MetricsSink
+		// are not pushed.
+		Enable bool
+	}
+
 	// Producer is the namespace for configuration related to producing messages,
 	// used by the Producer.
 	Producer struct {
@@ -298,8 +373,17 @@ type Config struct {
 		// possible mutate the message before they are published to Kafka
 		// cluster. *ProducerMessage modified by the first interceptor's
 		// OnSend() is passed to the second interceptor OnSend(), and so on in
-		// the interceptor chain.
+		// the interceptor chain. An interceptor rejecting the message (see
+		// ProducerInterceptorWithError) ends the chain, and the message is
+		// returned on Errors(). Interceptors are notified of acknowledgements
+		// (see ProducerAcknowledgementInterceptor) and closed along with the
+		// producer if they implement io.Closer.
 		Interceptors []ProducerInterceptor
+
+		// Encryptor, if set, encrypts messages after the interceptors have
+		// been applied, see EnvelopeEncryptor. Messages returned on the
+		// Successes and Errors channels contain the encrypted payload.
+		Encryptor MessageEncryptor
 	}
 
 	// Consumer is the namespace for configuration related to consuming messages,
@@ -487,8 +571,24 @@ type Config struct {
 		// mutate the message before they are returned to the client.
 		// *ConsumerMessage modified by the first interceptor's OnConsume() is
 		// passed to the second interceptor OnConsume(), and so on in the
-		// interceptor chain.
+		// interceptor chain. Interceptors are notified of committed offsets
+		// (see ConsumerCommitInterceptor) and closed along with the consumer
+		// or consumer group if they implement io.Closer.
 		Interceptors []ConsumerInterceptor
+
+		// Decryptor, if set, decrypts messages before the interceptors are
+		// applied, see EnvelopeEncryptor. Messages that fail to be decrypted
+		// are not delivered, the error is returned as a ConsumerError instead.
+		Decryptor MessageDecryptor
+
+		// Filter, if set, is called with the headers, key and timestamp of
+		// every fetched record while the fetch response is parsed: the records
+		// it returns false for are dropped before being decrypted, passed to
+		// the interceptors and delivered on Messages(). Consumer groups mark
+		// the offsets of the dropped records once every message delivered
+		// before them has been marked, so that the committed offsets do not lag
+		// behind them.
+		Filter RecordFilter
 	}
 
 	// A user-provided string sent with every request to the brokers for logging,
@@ -522,6 +622,28 @@ type Config struct {
 	// prior to starting Sarama.
 	// See Examples on how to use the metrics registry
 	MetricRegistry metrics.Registry
+	// MetricsSink, if set, is the metrics backend the metrics are reported to
+	// instead of MetricRegistry, with the broker, topic and consumer group
+	// they relate to as labels (defaults to nil, registering the metrics in
+	// MetricRegistry).
+	// See the metrics/prometheus and metrics/otel modules for Prometheus and
+	// OpenTelemetry sinks.
+	MetricsSink MetricsSink
+	// RequestObserver, if set, is notified before every request is sent to
+	// the brokers and once its response is received, with its size, latency,
+	// throttle time and error codes, e.g. for audit logging, custom metrics
+	// or tracing with OpenTelemetry (see the github.com/IBM/sarama/tracing/otel
+	// module). Defaults to nil.
+	RequestObserver RequestObserver
+	// Logger, if set, is the structured logger the client components log
+	// to, with the broker, topic, partition and consumer group they relate
+	// to as fields, e.g. a *slog.Logger. Defaults to nil, logging to the
+	// global Logger and DebugLogger.
+	Logger StructuredLogger
+	// EventListener, if set, is notified of the lifecycle events of the
+	// client and consumer groups, e.g. brokers being connected or partitions
+	// being assigned, see Event. Defaults to nil.
+	EventListener EventListener
 }
 
 // NewConfig returns a new configuration instance with sane defaults.
@@ -533,6 +655,7 @@ func NewConfig() *Config {
 	c.Admin.Timeout = 3 * time.Second
 
 	c.Net.MaxOpenRequests = 5
+	c.Net.ConnectionsPerBroker = 1
 	c.Net.DialTimeout = 30 * time.Second
 	c.Net.ReadTimeout = 30 * time.Second
 	c.Net.WriteTimeout = 30 * time.Second
@@ -594,63 +717,73 @@ func NewConfig() *Config {
 func (c *Config) Validate() error {
 	// some configuration values should be warned on but not fail completely, do those first
 	if !c.Net.TLS.Enable && c.Net.TLS.Config != nil {
-		Logger.Println("Net.TLS is disabled but a non-nil configuration was provided.")
+		c.logger().Warn("Net.TLS is disabled but a non-nil configuration was provided.")
 	}
 	if !c.Net.SASL.Enable {
 		if c.Net.SASL.User != "" {
-			Logger.Println("Net.SASL is disabled but a non-empty username was provided.")
+			c.logger().Warn("Net.SASL is disabled but a non-empty username was provided.")
 		}
 		if c.Net.SASL.Password != "" {
-			Logger.Println("Net.SASL is disabled but a non-empty password was provided.")
+			c.logger().Warn("Net.SASL is disabled but a non-empty password was provided.")
 		}
 	}
 	if c.Producer.RequiredAcks > 1 {
-		Logger.Println("Producer.RequiredAcks > 1 is deprecated and will raise an exception with kafka >= 0.8.2.0.")
+		c.logger().Warn("Producer.RequiredAcks > 1 is deprecated and will raise an exception with kafka >= 0.8.2.0.")
 	}
 	if c.Producer.MaxMessageBytes >= int(MaxRequestSize) {
-		Logger.Println("Producer.MaxMessageBytes must be smaller than MaxRequestSize; it will be ignored.")
+		c.logger().Warn("Producer.MaxMessageBytes must be smaller than MaxRequestSize; it will be ignored.")
 	}
 	if c.Producer.Flush.Bytes >= int(MaxRequestSize) {
-		Logger.Println("Producer.Flush.Bytes must be smaller than MaxRequestSize; it will be ignored.")
+		c.logger().Warn("Producer.Flush.Bytes must be smaller than MaxRequestSize; it will be ignored.")
 	}
 	if (c.Producer.Flush.Bytes > 0 || c.Producer.Flush.Messages > 0) && c.Producer.Flush.Frequency == 0 {
-		Logger.Println("Producer.Flush: Bytes or Messages are set, but Frequency is not; messages may not get flushed.")
+		c.logger().Warn("Producer.Flush: Bytes or Messages are set, but Frequency is not; messages may not get flushed.")
 	}
 	if c.Producer.Timeout%time.Millisecond != 0 {
-		Logger.Println("Producer.Timeout only supports millisecond resolution; nanoseconds will be truncated.")
+		c.logger().Warn("Producer.Timeout only supports millisecond resolution; nanoseconds will be truncated.")
 	}
 	if c.Consumer.MaxWaitTime < 100*time.Millisecond {
-		Logger.Println("Consumer.MaxWaitTime is very low, which can cause high CPU and network usage. See documentation for details.")
+		c.logger().Warn("Consumer.MaxWaitTime is very low, which can cause high CPU and network usage. See documentation for details.")
 	}
 	if c.Consumer.MaxWaitTime%time.Millisecond != 0 {
-		Logger.Println("Consumer.MaxWaitTime only supports millisecond precision; nanoseconds will be truncated.")
+		c.logger().Warn("Consumer.MaxWaitTime only supports millisecond precision; nanoseconds will be truncated.")
 	}
 	if c.Consumer.Offsets.Retention%time.Millisecond != 0 {
-		Logger.Println("Consumer.Offsets.Retention only supports millisecond precision; nanoseconds will be truncated.")
+		c.logger().Warn("Consumer.Offsets.Retention only supports millisecond precision; nanoseconds will be truncated.")
 	}
 	if c.Consumer.Group.Session.Timeout%time.Millisecond != 0 {
-		Logger.Println("Consumer.Group.Session.Timeout only supports millisecond precision; nanoseconds will be truncated.")
+		c.logger().Warn("Consumer.Group.Session.Timeout only supports millisecond precision; nanoseconds will be truncated.")
 	}
 	if c.Consumer.Group.Heartbeat.Interval%time.Millisecond != 0 {
-		Logger.Println("Consumer.Group.Heartbeat.Interval only supports millisecond precision; nanoseconds will be truncated.")
+		c.logger().Warn("Consumer.Group.Heartbeat.Interval only supports millisecond precision; nanoseconds will be truncated.")
 	}
 	if c.Consumer.Group.Rebalance.Timeout%time.Millisecond != 0 {
-		Logger.Println("Consumer.Group.Rebalance.Timeout only supports millisecond precision; nanoseconds will be truncated.")
+		c.logger().Warn("Consumer.Group.Rebalance.Timeout only supports millisecond precision; nanoseconds will be truncated.")
 	}
 	if c.ClientID == defaultClientID {
-		Logger.Println("ClientID is the default of 'sarama', you should consider setting it to something application-specific.")
+		c.logger().Warn("ClientID is the default of 'sarama', you should consider setting it to something application-specific.")
 	}
 
 	// validate Net values
 	switch {
 	case c.Net.MaxOpenRequests <= 0:
 		return ConfigurationError("Net.MaxOpenRequests must be > 0")
+	case c.Net.ConnectionsPerBroker <= 0:
+		return ConfigurationError("Net.ConnectionsPerBroker must be > 0")
 	case c.Net.DialTimeout <= 0:
 		return ConfigurationError("Net.DialTimeout must be > 0")
 	case c.Net.ReadTimeout <= 0:
 		return ConfigurationError("Net.ReadTimeout must be > 0")
 	case c.Net.WriteTimeout <= 0:
 		return ConfigurationError("Net.WriteTimeout must be > 0")
+	case c.Net.UseAllDNSIPs && c.Net.Proxy.Enable:
+		return ConfigurationError("Net.UseAllDNSIPs cannot be used with Net.Proxy")
+	case c.Net.MaxIdleTime < 0:
+		return ConfigurationError("Net.MaxIdleTime must be >= 0")
+	case c.Net.HealthCheckInterval < 0:
+		return ConfigurationError("Net.HealthCheckInterval must be >= 0")
+	case c.Net.TLS.RecycleBeforeExpiry < 0:
+		return ConfigurationError("Net.TLS.RecycleBeforeExpiry must be >= 0")
 	case c.Net.SASL.Enable:
 		if c.Net.SASL.Mechanism == "" {
 			c.Net.SASL.Mechanism = SASLTypePlaintext
@@ -658,6 +791,12 @@ func (c *Config) Validate() error {
 		if c.Net.SASL.Version == SASLHandshakeV0 && c.ApiVersionsRequest {
 			return ConfigurationError("ApiVersionsRequest must be disabled when SASL v0 is enabled")
 		}
+		if c.Net.SASL.MechanismClientFunc != nil {
+			if c.Net.SASL.Version == SASLHandshakeV0 {
+				return ConfigurationError("Net.SASL.MechanismClientFunc requires Net.SASL.Version to be SASLHandshakeV1")
+			}
+			break
+		}
 		switch c.Net.SASL.Mechanism {
 		case SASLTypePlaintext:
 			if c.Net.SASL.User == "" {
@@ -823,14 +962,18 @@ func (c *Config) Validate() error {
 	}
 
 	if c.Consumer.Offsets.CommitInterval != 0 {
-		Logger.Println("Deprecation warning: Consumer.Offsets.CommitInterval exists for historical compatibility" +
+		c.logger().Warn("Deprecation warning: Consumer.Offsets.CommitInterval exists for historical compatibility" +
 			" and should not be used. Please use Consumer.Offsets.AutoCommit, the current value will be ignored")
 	}
 	if c.Consumer.Group.Rebalance.Strategy != nil {
-		Logger.Println("Deprecation warning: Consumer.Group.Rebalance.Strategy exists for historical compatibility" +
+		c.logger().Warn("Deprecation warning: Consumer.Group.Rebalance.Strategy exists for historical compatibility" +
 			" and should not be used. Please use Consumer.Group.Rebalance.GroupStrategies")
 	}
 
+	if c.Telemetry.Enable && !c.Version.IsAtLeast(V3_7_0_0) {
+		return ConfigurationError("Telemetry.Enable requires Version >= V3_7_0_0")
+	}
+
 	// validate IsolationLevel
 	if c.Consumer.IsolationLevel == ReadCommitted && !c.Version.IsAtLeast(V0_11_0_0) {
 		return ConfigurationError("ReadCommitted requires Version >= V0_11_0_0")
@@ -883,9 +1026,13 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+func (c *Config) logger() *logger {
+	return newLogger(c)
+}
+
 func (c *Config) getDialer() proxy.Dialer {
 	if c.Net.Proxy.Enable {
-		Logger.Println("using proxy")
+		c.logger().Info("using proxy")
 		return c.Net.Proxy.Dialer
 	} else {
 		return &net.Dialer{
@@ -74,6 +74,28 @@ func TestNetConfigValidates(t *testing.T) {
 			},
 			"Net.MaxOpenRequests must be > 0",
 		},
+		{
+			"ConnectionsPerBroker",
+			func(cfg *Config) {
+				cfg.Net.ConnectionsPerBroker = 0
+			},
+			"Net.ConnectionsPerBroker must be > 0",
+		},
+		{
+			"UseAllDNSIPs with Proxy",
+			func(cfg *Config) {
+				cfg.Net.UseAllDNSIPs = true
+				cfg.Net.Proxy.Enable = true
+			},
+			"Net.UseAllDNSIPs cannot be used with Net.Proxy",
+		},
+		{
+			"MaxIdleTime",
+			func(cfg *Config) {
+				cfg.Net.MaxIdleTime = -1
+			},
+			"Net.MaxIdleTime must be >= 0",
+		},
 		{
 			"DialTimeout",
 			func(cfg *Config) {
@@ -502,6 +524,20 @@ func TestConsumerConfigValidates(t *testing.T) {
 	}
 }
 
+func TestTelemetryConfigValidation(t *testing.T) {
+	config := NewTestConfig()
+	config.Telemetry.Enable = true
+	err := config.Validate()
+	var target ConfigurationError
+	if !errors.As(err, &target) || string(target) != "Telemetry.Enable requires Version >= V3_7_0_0" {
+		t.Error("Expected invalid telemetry version error, got ", err)
+	}
+	config.Version = V3_7_0_0
+	if err := config.Validate(); err != nil {
+		t.Error("Expected no error, got ", err)
+	}
+}
+
 func TestLZ4ConfigValidation(t *testing.T) {
 	config := NewTestConfig()
 	config.Producer.Compression = CompressionLZ4
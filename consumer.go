@@ -23,6 +23,22 @@ type ConsumerMessage struct {
 	Offset     int64
 }
 
+// FetchedRecord is a record being parsed out of a fetch response, passed to
+// the Consumer.Filter to decide whether it is delivered. Its key and headers
+// must not be modified.
+type FetchedRecord struct {
+	Headers   []*RecordHeader // only set if kafka is version 0.11+
+	Timestamp time.Time       // only set if kafka is version 0.10+
+	Key       []byte
+	Topic     string
+	Partition int32
+	Offset    int64
+}
+
+// RecordFilter returns whether a fetched record is delivered by the consumer,
+// see Config.Consumer.Filter.
+type RecordFilter func(record FetchedRecord) bool
+
 // ConsumerError is what is provided to the user when an error occurs.
 // It wraps an error and includes the topic and partition.
 type ConsumerError struct {
@@ -105,6 +121,7 @@ type consumer struct {
 	brokerConsumers map[*Broker]*brokerConsumer
 	client          Client
 	metricRegistry  metrics.Registry
+	log             *logger
 	lock            sync.Mutex
 }
 
@@ -137,13 +154,17 @@ func newConsumer(client Client) (Consumer, error) {
 		conf:            client.Config(),
 		children:        make(map[string]map[int32]*partitionConsumer),
 		brokerConsumers: make(map[*Broker]*brokerConsumer),
-		metricRegistry:  newCleanupRegistry(client.Config().MetricRegistry),
+		metricRegistry:  newMetricRegistry(client.Config()),
+		log:             newLogger(client.Config()),
 	}
 
 	return c, nil
 }
 
 func (c *consumer) Close() error {
+	for _, interceptor := range c.conf.Consumer.Interceptors {
+		safelyCloseInterceptor(interceptor, c.log)
+	}
 	c.metricRegistry.UnregisterAll()
 	return c.client.Close()
 }
@@ -157,6 +178,12 @@ func (c *consumer) Partitions(topic string) ([]int32, error) {
 }
 
 func (c *consumer) ConsumePartition(topic string, partition int32, offset int64) (PartitionConsumer, error) {
+	return c.consumePartition(topic, partition, offset, nil)
+}
+
+// consumePartition is ConsumePartition notifying skipped of the offsets of
+// the records dropped by the Consumer.Filter, see partitionConsumer.skipped.
+func (c *consumer) consumePartition(topic string, partition int32, offset int64, skipped func(from, to int64)) (PartitionConsumer, error) {
 	child := &partitionConsumer{
 		consumer:             c,
 		conf:                 c.conf,
@@ -170,7 +197,12 @@ func (c *consumer) ConsumePartition(topic string, partition int32, offset int64)
 		trigger:              make(chan none, 1),
 		dying:                make(chan none),
 		fetchSize:            c.conf.Consumer.Fetch.Default,
+		log:                  c.log.with("topic", topic, "partition", partition),
+		skipped:              skipped,
+		delivered:            -1,
 	}
+	child.lag.Store(-1)
+	child.lead.Store(-1)
 
 	if err := child.chooseStartingOffset(offset); err != nil {
 		return nil, err
@@ -231,9 +263,40 @@ func (c *consumer) addChild(child *partitionConsumer) error {
 
 func (c *consumer) removeChild(child *partitionConsumer) {
 	c.lock.Lock()
-	defer c.lock.Unlock()
-
 	delete(c.children[child.topic], child.partition)
+	c.lock.Unlock()
+
+	labels := []metricLabel{topicMetricLabel(child.topic), partitionMetricLabel(child.partition)}
+	unregisterMetric("records-lag", c.metricRegistry, labels...)
+	unregisterMetric("records-lead", c.metricRegistry, labels...)
+	if child.paused.Swap(false) {
+		getOrRegisterCounter("consumer-paused-partitions", c.metricRegistry).Dec(1)
+	}
+	c.updatePartitionMetrics()
+}
+
+// updatePartitionMetrics updates the metrics summarizing those of the
+// partitions being consumed.
+func (c *consumer) updatePartitionMetrics() {
+	var lagMax, leadMin int64
+	// a lead of 0 is valid, the partitions without known lead are skipped
+	leadFound := false
+	c.lock.Lock()
+	for _, partitions := range c.children {
+		for _, child := range partitions {
+			if lag := child.lag.Load(); lag > lagMax {
+				lagMax = lag
+			}
+			if lead := child.lead.Load(); lead >= 0 && (!leadFound || lead < leadMin) {
+				leadMin = lead
+				leadFound = true
+			}
+		}
+	}
+	c.lock.Unlock()
+
+	getOrRegisterGauge("records-lag-max", c.metricRegistry).Update(lagMax)
+	getOrRegisterGauge("records-lead-min", c.metricRegistry).Update(leadMin)
 }
 
 func (c *consumer) refBrokerConsumer(broker *Broker) *brokerConsumer {
@@ -400,6 +463,7 @@ type partitionConsumer struct {
 	messages chan *ConsumerMessage
 	errors   chan *ConsumerError
 	feeder   chan *FetchResponse
+	log      *logger
 
 	leaderEpoch          int32
 	preferredReadReplica int32
@@ -414,6 +478,17 @@ type partitionConsumer struct {
 	retries        atomic.Int32
 
 	paused atomic.Bool // accessed atomically, 0 = not paused, 1 = paused
+
+	// records lag and lead of the last fetch, -1 until known
+	lag, lead atomic.Int64
+
+	// skipped, if set, is called with the offsets of the records dropped by
+	// the Consumer.Filter after the last message delivered, whose offset + 1
+	// is delivered (-1 until a message is delivered), up to filteredTo, the
+	// offset following the last dropped record.
+	skipped    func(from, to int64)
+	delivered  int64
+	filteredTo int64
 }
 
 var errTimedOut = errors.New("timed out feeding messages to the user") // not user-facing
@@ -428,7 +503,7 @@ func (child *partitionConsumer) sendError(err error) {
 	if child.conf.Consumer.Return.Errors {
 		child.errors <- cErr
 	} else {
-		Logger.Println(cErr)
+		child.log.Error("consumer failed to consume partition", "err", err)
 	}
 }
 
@@ -471,9 +546,8 @@ func (child *partitionConsumer) preferredBroker() (*Broker, int32, error) {
 		if err == nil {
 			return broker, child.leaderEpoch, nil
 		}
-		Logger.Printf(
-			"consumer/%s/%d failed to find active broker for preferred read replica %d - will fallback to leader",
-			child.topic, child.partition, child.preferredReadReplica)
+		child.log.Warn("consumer failed to find active broker for preferred read replica - will fallback to leader",
+			"replica", child.preferredReadReplica)
 
 		// if we couldn't find it, discard the replica preference and trigger a
 		// metadata refresh whilst falling back to consuming from the leader again
@@ -578,6 +652,10 @@ feederLoop:
 			child.retries.Store(0)
 		}
 
+		if child.conf.Consumer.Decryptor != nil {
+			msgs = child.decrypt(msgs)
+		}
+
 		for i, msg := range msgs {
 			child.interceptors(msg)
 		messageSelect:
@@ -600,6 +678,7 @@ feederLoop:
 							break remainingLoop
 						}
 					}
+					child.reportSkipped(msgs)
 					child.broker.input <- child
 					continue feederLoop
 				} else {
@@ -611,6 +690,7 @@ feederLoop:
 			}
 		}
 
+		child.reportSkipped(msgs)
 		child.broker.acks.Done()
 	}
 
@@ -621,6 +701,7 @@ feederLoop:
 
 func (child *partitionConsumer) parseMessages(msgSet *MessageSet) ([]*ConsumerMessage, error) {
 	var messages []*ConsumerMessage
+	filtered := false
 	for _, msgBlock := range msgSet.Messages {
 		for _, msg := range msgBlock.Messages() {
 			offset := msg.Offset
@@ -635,6 +716,18 @@ func (child *partitionConsumer) parseMessages(msgSet *MessageSet) ([]*ConsumerMe
 			if offset < child.offset {
 				continue
 			}
+			if child.conf.Consumer.Filter != nil && !child.conf.Consumer.Filter(FetchedRecord{
+				Topic:     child.topic,
+				Partition: child.partition,
+				Offset:    offset,
+				Key:       msg.Msg.Key,
+				Timestamp: timestamp,
+			}) {
+				child.offset = offset + 1
+				child.filteredTo = child.offset
+				filtered = true
+				continue
+			}
 			messages = append(messages, &ConsumerMessage{
 				Topic:          child.topic,
 				Partition:      child.partition,
@@ -647,7 +740,7 @@ func (child *partitionConsumer) parseMessages(msgSet *MessageSet) ([]*ConsumerMe
 			child.offset = offset + 1
 		}
 	}
-	if len(messages) == 0 {
+	if len(messages) == 0 && !filtered {
 		child.offset++
 	}
 	return messages, nil
@@ -655,6 +748,7 @@ func (child *partitionConsumer) parseMessages(msgSet *MessageSet) ([]*ConsumerMe
 
 func (child *partitionConsumer) parseRecords(batch *RecordBatch) ([]*ConsumerMessage, error) {
 	messages := make([]*ConsumerMessage, 0, len(batch.Records))
+	filtered := false
 
 	for _, rec := range batch.Records {
 		offset := batch.FirstOffset + rec.OffsetDelta
@@ -665,6 +759,19 @@ func (child *partitionConsumer) parseRecords(batch *RecordBatch) ([]*ConsumerMes
 		if batch.LogAppendTime {
 			timestamp = batch.MaxTimestamp
 		}
+		if child.conf.Consumer.Filter != nil && !child.conf.Consumer.Filter(FetchedRecord{
+			Topic:     child.topic,
+			Partition: child.partition,
+			Offset:    offset,
+			Key:       rec.Key,
+			Headers:   rec.Headers,
+			Timestamp: timestamp,
+		}) {
+			child.offset = offset + 1
+			child.filteredTo = child.offset
+			filtered = true
+			continue
+		}
 		messages = append(messages, &ConsumerMessage{
 			Topic:     child.topic,
 			Partition: child.partition,
@@ -676,12 +783,26 @@ func (child *partitionConsumer) parseRecords(batch *RecordBatch) ([]*ConsumerMes
 		})
 		child.offset = offset + 1
 	}
-	if len(messages) == 0 {
+	if len(messages) == 0 && !filtered {
 		child.offset++
 	}
 	return messages, nil
 }
 
+// reportSkipped notifies the skipped callback, if any, of the records dropped
+// by the Consumer.Filter after msgs, the messages just delivered.
+func (child *partitionConsumer) reportSkipped(msgs []*ConsumerMessage) {
+	if child.skipped == nil {
+		return
+	}
+	if len(msgs) > 0 {
+		child.delivered = msgs[len(msgs)-1].Offset + 1
+	}
+	if child.filteredTo > child.delivered {
+		child.skipped(child.delivered, child.filteredTo)
+	}
+}
+
 func (child *partitionConsumer) parseResponse(response *FetchResponse) ([]*ConsumerMessage, error) {
 	var consumerBatchSizeMetric metrics.Histogram
 	if child.consumer != nil && child.consumer.metricRegistry != nil {
@@ -690,9 +811,8 @@ func (child *partitionConsumer) parseResponse(response *FetchResponse) ([]*Consu
 
 	// If request was throttled and empty we log and return without error
 	if response.ThrottleTime != time.Duration(0) && len(response.Blocks) == 0 {
-		Logger.Printf(
-			"consumer/broker/%d FetchResponse throttled %v\n",
-			child.broker.broker.ID(), response.ThrottleTime)
+		child.log.Info("consumer/broker FetchResponse throttled",
+			"broker", child.broker.broker.ID(), "throttle", response.ThrottleTime)
 		return nil, nil
 	}
 
@@ -742,10 +862,12 @@ func (child *partitionConsumer) parseResponse(response *FetchResponse) ([]*Consu
 			}
 		} else if block.recordsNextOffset != nil && *block.recordsNextOffset <= block.HighWaterMarkOffset {
 			// check last record next offset to avoid stuck if high watermark was not reached
-			Logger.Printf("consumer/broker/%d received batch with zero records but high watermark was not reached, topic %s, partition %d, next offset %d\n", child.broker.broker.ID(), child.topic, child.partition, *block.recordsNextOffset)
+			child.log.Info("consumer/broker received batch with zero records but high watermark was not reached",
+				"broker", child.broker.broker.ID(), "next_offset", *block.recordsNextOffset)
 			child.offset = *block.recordsNextOffset
 		}
 
+		child.updateFetchMetrics(response, block, nil)
 		return nil, nil
 	}
 
@@ -826,23 +948,83 @@ func (child *partitionConsumer) parseResponse(response *FetchResponse) ([]*Consu
 		}
 	}
 
+	child.updateFetchMetrics(response, block, messages)
 	return messages, nil
 }
 
+// updateFetchMetrics updates the lag and lead of the partition once block has
+// been fetched, as well as the bytes consumed by messages. The metrics
+// summarizing all the partitions are updated once per fetch response by the
+// brokerConsumer.
+func (child *partitionConsumer) updateFetchMetrics(response *FetchResponse, block *FetchResponseBlock, messages []*ConsumerMessage) {
+	if child.consumer == nil || child.consumer.metricRegistry == nil {
+		return
+	}
+	metricRegistry := child.consumer.metricRegistry
+	labels := []metricLabel{topicMetricLabel(child.topic), partitionMetricLabel(child.partition)}
+
+	// read committed consumers can't read beyond the last stable offset
+	endOffset := block.HighWaterMarkOffset
+	if child.conf.Consumer.IsolationLevel == ReadCommitted && response.Version >= 4 {
+		endOffset = block.LastStableOffset
+	}
+	lag := max(endOffset-child.offset, 0)
+	child.lag.Store(lag)
+	getOrRegisterGauge("records-lag", metricRegistry, labels...).Update(lag)
+
+	// the log start offset was added in version 5
+	if response.Version >= 5 {
+		lead := max(child.offset-block.LogStartOffset, 0)
+		child.lead.Store(lead)
+		getOrRegisterGauge("records-lead", metricRegistry, labels...).Update(lead)
+	}
+
+	var bytes int64
+	for _, msg := range messages {
+		bytes += int64(len(msg.Key) + len(msg.Value))
+	}
+	getOrRegisterAggregateMeter("bytes-consumed-rate", metricRegistry).Mark(bytes)
+	getOrRegisterTopicMeter("bytes-consumed-rate", child.topic, metricRegistry).Mark(bytes)
+}
+
+// decrypt decrypts msgs in place, dropping the messages that fail to be
+// decrypted after returning their error.
+func (child *partitionConsumer) decrypt(msgs []*ConsumerMessage) []*ConsumerMessage {
+	decrypted := msgs[:0]
+	for _, msg := range msgs {
+		if err := child.conf.Consumer.Decryptor.Decrypt(msg); err != nil {
+			child.sendError(fmt.Errorf("%w at offset %d: %w", ErrPayloadDecryption, msg.Offset, err))
+			continue
+		}
+		decrypted = append(decrypted, msg)
+	}
+	return decrypted
+}
+
 func (child *partitionConsumer) interceptors(msg *ConsumerMessage) {
 	for _, interceptor := range child.conf.Consumer.Interceptors {
-		msg.safelyApplyInterceptor(interceptor)
+		msg.safelyApplyInterceptor(interceptor, child.log)
 	}
 }
 
 // Pause implements PartitionConsumer.
 func (child *partitionConsumer) Pause() {
-	child.paused.Store(true)
+	if !child.paused.Swap(true) {
+		child.updatePausedMetric(1)
+	}
 }
 
 // Resume implements PartitionConsumer.
 func (child *partitionConsumer) Resume() {
-	child.paused.Store(false)
+	if child.paused.Swap(false) {
+		child.updatePausedMetric(-1)
+	}
+}
+
+func (child *partitionConsumer) updatePausedMetric(delta int64) {
+	if child.consumer != nil && child.consumer.metricRegistry != nil {
+		getOrRegisterCounter("consumer-paused-partitions", child.consumer.metricRegistry).Inc(delta)
+	}
 }
 
 // IsPaused implements PartitionConsumer.
@@ -858,6 +1040,7 @@ type brokerConsumer struct {
 	subscriptions    map[*partitionConsumer]none
 	acks             sync.WaitGroup
 	refs             int
+	log              *logger
 }
 
 func (c *consumer) newBrokerConsumer(broker *Broker) *brokerConsumer {
@@ -868,6 +1051,7 @@ func (c *consumer) newBrokerConsumer(broker *Broker) *brokerConsumer {
 		newSubscriptions: make(chan []*partitionConsumer),
 		subscriptions:    make(map[*partitionConsumer]none),
 		refs:             0,
+		log:              c.log.with("broker", broker.ID()),
 	}
 
 	go withRecover(bc.subscriptionManager)
@@ -911,9 +1095,7 @@ func (bc *brokerConsumer) subscriptionManager() {
 		}
 		timer.Stop()
 
-		Logger.Printf(
-			"consumer/broker/%d accumulated %d new subscriptions\n",
-			bc.broker.ID(), len(partitionConsumers))
+		bc.log.Info("consumer/broker accumulated new subscriptions", "count", len(partitionConsumers))
 
 		bc.newSubscriptions <- partitionConsumers
 	}
@@ -934,7 +1116,7 @@ func (bc *brokerConsumer) subscriptionConsumer() {
 
 		response, err := bc.fetchNewMessages()
 		if err != nil {
-			Logger.Printf("consumer/broker/%d disconnecting due to error processing FetchRequest: %s\n", bc.broker.ID(), err)
+			bc.log.Warn("consumer/broker disconnecting due to error processing FetchRequest", "err", err)
 			bc.abort(err)
 			return
 		}
@@ -961,6 +1143,7 @@ func (bc *brokerConsumer) subscriptionConsumer() {
 			child.feeder <- response
 		}
 		bc.acks.Wait()
+		bc.consumer.updatePartitionMetrics()
 		bc.handleResponses()
 	}
 }
@@ -968,13 +1151,13 @@ func (bc *brokerConsumer) subscriptionConsumer() {
 func (bc *brokerConsumer) updateSubscriptions(newSubscriptions []*partitionConsumer) {
 	for _, child := range newSubscriptions {
 		bc.subscriptions[child] = none{}
-		Logger.Printf("consumer/broker/%d added subscription to %s/%d\n", bc.broker.ID(), child.topic, child.partition)
+		bc.log.Info("consumer/broker added subscription", "topic", child.topic, "partition", child.partition)
 	}
 
 	for child := range bc.subscriptions {
 		select {
 		case <-child.dying:
-			Logger.Printf("consumer/broker/%d closed dead subscription to %s/%d\n", bc.broker.ID(), child.topic, child.partition)
+			bc.log.Info("consumer/broker closed dead subscription", "topic", child.topic, "partition", child.partition)
 			close(child.trigger)
 			delete(bc.subscriptions, child)
 		default:
@@ -993,9 +1176,8 @@ func (bc *brokerConsumer) handleResponses() {
 			if preferredBroker, _, err := child.preferredBroker(); err == nil {
 				if bc.broker.ID() != preferredBroker.ID() {
 					// not an error but needs redispatching to consume from preferred replica
-					Logger.Printf(
-						"consumer/broker/%d abandoned in favor of preferred replica broker/%d\n",
-						bc.broker.ID(), preferredBroker.ID())
+					bc.log.Info("consumer/broker abandoned in favor of preferred replica",
+						"topic", child.topic, "partition", child.partition, "replica", preferredBroker.ID())
 					child.trigger <- none{}
 					delete(bc.subscriptions, child)
 				}
@@ -1007,14 +1189,14 @@ func (bc *brokerConsumer) handleResponses() {
 		child.preferredReadReplica = invalidPreferredReplicaID
 
 		if errors.Is(result, errTimedOut) {
-			Logger.Printf("consumer/broker/%d abandoned subscription to %s/%d because consuming was taking too long\n",
-				bc.broker.ID(), child.topic, child.partition)
+			bc.log.Info("consumer/broker abandoned subscription because consuming was taking too long",
+				"topic", child.topic, "partition", child.partition)
 			delete(bc.subscriptions, child)
 		} else if errors.Is(result, ErrOffsetOutOfRange) {
 			// there's no point in retrying this it will just fail the same way again
 			// shut it down and force the user to choose what to do
 			child.sendError(result)
-			Logger.Printf("consumer/%s/%d shutting down because %s\n", child.topic, child.partition, result)
+			child.log.Warn("consumer shutting down", "err", result)
 			close(child.trigger)
 			delete(bc.subscriptions, child)
 		} else if errors.Is(result, ErrUnknownTopicOrPartition) ||
@@ -1024,15 +1206,15 @@ func (bc *brokerConsumer) handleResponses() {
 			errors.Is(result, ErrFencedLeaderEpoch) ||
 			errors.Is(result, ErrUnknownLeaderEpoch) {
 			// not an error, but does need redispatching
-			Logger.Printf("consumer/broker/%d abandoned subscription to %s/%d because %s\n",
-				bc.broker.ID(), child.topic, child.partition, result)
+			bc.log.Info("consumer/broker abandoned subscription",
+				"topic", child.topic, "partition", child.partition, "err", result)
 			child.trigger <- none{}
 			delete(bc.subscriptions, child)
 		} else {
 			// dunno, tell the user and try redispatching
 			child.sendError(result)
-			Logger.Printf("consumer/broker/%d abandoned subscription to %s/%d because %s\n",
-				bc.broker.ID(), child.topic, child.partition, result)
+			bc.log.Info("consumer/broker abandoned subscription",
+				"topic", child.topic, "partition", child.partition, "err", result)
 			child.trigger <- none{}
 			delete(bc.subscriptions, child)
 		}
@@ -1132,5 +1314,12 @@ func (bc *brokerConsumer) fetchNewMessages() (*FetchResponse, error) {
 		return nil, nil
 	}
 
-	return bc.broker.Fetch(request)
+	requestTime := time.Now()
+	response, err := bc.broker.Fetch(request)
+	if err == nil {
+		fetchLatency := int64(time.Since(requestTime) / time.Millisecond)
+		getOrRegisterAggregateHistogram("fetch-latency-in-ms", bc.consumer.metricRegistry).Update(fetchLatency)
+		getOrRegisterHistogram("fetch-latency-in-ms", bc.consumer.metricRegistry, brokerMetricLabel(bc.broker.ID())).Update(fetchLatency)
+	}
+	return response, err
 }
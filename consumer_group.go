@@ -94,6 +94,7 @@ type consumerGroup struct {
 	userData []byte
 
 	metricRegistry metrics.Registry
+	log            *logger
 }
 
 // NewConsumerGroup creates a new consumer group the given broker addresses and configuration.
@@ -129,20 +130,23 @@ func newConsumerGroup(groupID string, client Client) (ConsumerGroup, error) {
 		return nil, ConfigurationError("consumer groups require Version to be >= V0_10_2_0")
 	}
 
-	consumer, err := newConsumer(client)
+	c, err := newConsumer(client)
 	if err != nil {
 		return nil, err
 	}
+	log := newLogger(config, "group", groupID)
+	c.(*consumer).log = log
 
 	cg := &consumerGroup{
 		client:         client,
-		consumer:       consumer,
+		consumer:       c,
 		config:         config,
 		groupID:        groupID,
 		errors:         make(chan error, config.ChannelBufferSize),
 		closed:         make(chan none),
 		userData:       config.Consumer.Group.Member.UserData,
-		metricRegistry: newCleanupRegistry(config.MetricRegistry),
+		metricRegistry: newMetricRegistry(config),
+		log:            log,
 	}
 	if config.Consumer.Group.InstanceId != "" && config.Version.IsAtLeast(V2_3_0_0) {
 		cg.groupInstanceId = &config.Consumer.Group.InstanceId
@@ -174,6 +178,10 @@ func (c *consumerGroup) Close() (err error) {
 			err = e
 		}
 
+		for _, interceptor := range c.config.Consumer.Interceptors {
+			safelyCloseInterceptor(interceptor, c.log)
+		}
+
 		if e := c.client.Close(); e != nil {
 			err = e
 		}
@@ -206,6 +214,7 @@ func (c *consumerGroup) Consume(ctx context.Context, topics []string, handler Co
 	}
 
 	// Init session
+	notifyEvent(c.config, &RebalanceStarted{GroupID: c.groupID, Topics: topics})
 	sess, err := c.newSession(ctx, topics, handler, c.config.Consumer.Group.Rebalance.Retry.Max)
 	if errors.Is(err, ErrClosedClient) {
 		return ErrClosedConsumerGroup
@@ -287,10 +296,11 @@ func (c *consumerGroup) newSession(ctx context.Context, topics []string, handler
 	)
 
 	if metricRegistry != nil {
-		consumerGroupJoinTotal = metrics.GetOrRegisterCounter(fmt.Sprintf("consumer-group-join-total-%s", c.groupID), metricRegistry)
-		consumerGroupJoinFailed = metrics.GetOrRegisterCounter(fmt.Sprintf("consumer-group-join-failed-%s", c.groupID), metricRegistry)
-		consumerGroupSyncTotal = metrics.GetOrRegisterCounter(fmt.Sprintf("consumer-group-sync-total-%s", c.groupID), metricRegistry)
-		consumerGroupSyncFailed = metrics.GetOrRegisterCounter(fmt.Sprintf("consumer-group-sync-failed-%s", c.groupID), metricRegistry)
+		group := groupMetricLabel(c.groupID)
+		consumerGroupJoinTotal = getOrRegisterCounter("consumer-group-join-total", metricRegistry, group)
+		consumerGroupJoinFailed = getOrRegisterCounter("consumer-group-join-failed", metricRegistry, group)
+		consumerGroupSyncTotal = getOrRegisterCounter("consumer-group-sync-total", metricRegistry, group)
+		consumerGroupSyncFailed = getOrRegisterCounter("consumer-group-sync-failed", metricRegistry, group)
 	}
 
 	// Join consumer group
@@ -332,7 +342,7 @@ func (c *consumerGroup) newSession(ctx context.Context, topics []string, handler
 		return c.newSession(ctx, topics, handler, retries)
 	case ErrFencedInstancedId:
 		if c.groupInstanceId != nil {
-			Logger.Printf("JoinGroup failed: group instance id %s has been fenced\n", *c.groupInstanceId)
+			c.log.Error("JoinGroup failed: group instance id has been fenced", "instance_id", *c.groupInstanceId)
 		}
 		return nil, join.Err
 	default:
@@ -399,7 +409,7 @@ func (c *consumerGroup) newSession(ctx context.Context, topics []string, handler
 		return c.retryNewSession(ctx, topics, handler, retries, true)
 	case ErrFencedInstancedId:
 		if c.groupInstanceId != nil {
-			Logger.Printf("JoinGroup failed: group instance id %s has been fenced\n", *c.groupInstanceId)
+			c.log.Error("JoinGroup failed: group instance id has been fenced", "instance_id", *c.groupInstanceId)
 		}
 		return nil, syncGroupResponse.Err
 	default:
@@ -681,7 +691,7 @@ func (c *consumerGroup) handleError(err error, topic string, partition int32) {
 	}
 
 	if !c.config.Consumer.Return.Errors {
-		Logger.Println(err)
+		c.log.Error("consumergroup error", "err", err)
 		return
 	}
 
@@ -721,9 +731,8 @@ func (c *consumerGroup) loopCheckPartitionNumbers(allSubscribedTopicPartitions m
 		} else {
 			for topic, num := range oldTopicToPartitionNum {
 				if newTopicToPartitionNum[topic] != num {
-					Logger.Printf(
-						"consumergroup/%s loop check partition number goroutine find partitions in topics %s changed from %d to %d\n",
-						c.groupID, topics, num, newTopicToPartitionNum[topic])
+					c.log.Info("consumergroup loop check partition number goroutine found partitions changed",
+						"topic", topic, "from", num, "to", newTopicToPartitionNum[topic])
 					return // trigger the end of the session on exit
 				}
 			}
@@ -731,9 +740,7 @@ func (c *consumerGroup) loopCheckPartitionNumbers(allSubscribedTopicPartitions m
 		select {
 		case <-pause.C:
 		case <-session.ctx.Done():
-			Logger.Printf(
-				"consumergroup/%s loop check partition number goroutine will exit, topics %s\n",
-				c.groupID, topics)
+			c.log.Info("consumergroup loop check partition number goroutine will exit", "topics", topics)
 			// if session closed by other, should be exited
 			return
 		case <-c.closed:
@@ -746,9 +753,7 @@ func (c *consumerGroup) topicToPartitionNumbers(topics []string) (map[string]int
 	topicToPartitionNum := make(map[string]int, len(topics))
 	for _, topic := range topics {
 		if partitionNum, err := c.client.Partitions(topic); err != nil {
-			Logger.Printf(
-				"consumergroup/%s topic %s get partition number failed due to '%v'\n",
-				c.groupID, topic, err)
+			c.log.Warn("consumergroup get partition number failed", "topic", topic, "err", err)
 			return nil, err
 		} else {
 			topicToPartitionNum[topic] = len(partitionNum)
@@ -818,6 +823,8 @@ type consumerGroupSession struct {
 	waitGroup       sync.WaitGroup
 	releaseOnce     sync.Once
 	hbDying, hbDead chan none
+
+	log *logger
 }
 
 func newConsumerGroupSession(ctx context.Context, parent *consumerGroup, claims map[string][]int32, memberID string, generationID int32, handler ConsumerGroupHandler) (*consumerGroupSession, error) {
@@ -842,7 +849,14 @@ func newConsumerGroupSession(ctx context.Context, parent *consumerGroup, claims
 		cancel:       cancel,
 		hbDying:      make(chan none),
 		hbDead:       make(chan none),
+		log:          parent.log.with("member", memberID, "generation", generationID),
 	}
+	notifyEvent(parent.config, &PartitionsAssigned{
+		GroupID:      parent.groupID,
+		MemberID:     memberID,
+		GenerationID: generationID,
+		Claims:       claims,
+	})
 
 	// start heartbeat loop
 	go sess.heartbeatLoop()
@@ -984,6 +998,21 @@ func (s *consumerGroupSession) consume(topic string, partition int32) {
 	}
 }
 
+// consumePartition consumes the partition, marking the offsets of the
+// records dropped by the Consumer.Filter once the messages delivered before
+// them have been marked.
+func (s *consumerGroupSession) consumePartition(topic string, partition int32, offset int64) (PartitionConsumer, error) {
+	c, ok := s.parent.consumer.(*consumer)
+	if !ok || s.parent.config.Consumer.Filter == nil {
+		return s.parent.consumer.ConsumePartition(topic, partition, offset)
+	}
+	return c.consumePartition(topic, partition, offset, func(from, to int64) {
+		if pom := s.offsets.findPOM(topic, partition); pom != nil {
+			pom.skip(from, to)
+		}
+	})
+}
+
 func (s *consumerGroupSession) release(withCleanup bool) (err error) {
 	// signal release, stop heartbeat
 	s.cancel()
@@ -1006,11 +1035,16 @@ func (s *consumerGroupSession) release(withCleanup bool) (err error) {
 
 		close(s.hbDying)
 		<-s.hbDead
+
+		notifyEvent(s.parent.config, &PartitionsRevoked{
+			GroupID:      s.parent.groupID,
+			MemberID:     s.memberID,
+			GenerationID: s.generationID,
+			Claims:       s.claims,
+		})
 	})
 
-	Logger.Printf(
-		"consumergroup/session/%s/%d released\n",
-		s.MemberID(), s.GenerationID())
+	s.log.Info("consumergroup/session released")
 
 	return
 }
@@ -1019,9 +1053,7 @@ func (s *consumerGroupSession) heartbeatLoop() {
 	defer close(s.hbDead)
 	defer s.cancel() // trigger the end of the session on exit
 	defer func() {
-		Logger.Printf(
-			"consumergroup/session/%s/%d heartbeat loop stopped\n",
-			s.MemberID(), s.GenerationID())
+		s.log.Info("consumergroup/session heartbeat loop stopped")
 	}()
 
 	pause := time.NewTicker(s.parent.config.Consumer.Group.Heartbeat.Interval)
@@ -1071,7 +1103,7 @@ func (s *consumerGroupSession) heartbeatLoop() {
 			return
 		case ErrFencedInstancedId:
 			if s.parent.groupInstanceId != nil {
-				Logger.Printf("JoinGroup failed: group instance id %s has been fenced\n", *s.parent.groupInstanceId)
+				s.log.Error("JoinGroup failed: group instance id has been fenced", "instance_id", *s.parent.groupInstanceId)
 			}
 			s.parent.handleError(resp.Err, "", -1)
 			return
@@ -1142,11 +1174,11 @@ type consumerGroupClaim struct {
 }
 
 func newConsumerGroupClaim(sess *consumerGroupSession, topic string, partition int32, offset int64) (*consumerGroupClaim, error) {
-	pcm, err := sess.parent.consumer.ConsumePartition(topic, partition, offset)
+	pcm, err := sess.consumePartition(topic, partition, offset)
 
 	if errors.Is(err, ErrOffsetOutOfRange) && sess.parent.config.Consumer.Group.ResetInvalidOffsets {
 		offset = sess.parent.config.Consumer.Offsets.Initial
-		pcm, err = sess.parent.consumer.ConsumePartition(topic, partition, offset)
+		pcm, err = sess.consumePartition(topic, partition, offset)
 	}
 	if err != nil {
 		return nil, err
@@ -0,0 +1,65 @@
+package sarama
+
+// ConsumerGroupDescribeRequest describes consumer groups using the consumer
+// rebalance protocol introduced by KIP-848.
+type ConsumerGroupDescribeRequest struct {
+	// Version defines the protocol version to use for encode and decode
+	Version int16
+	// GroupIds contains the IDs of the groups to describe.
+	GroupIds []string
+	// IncludeAuthorizedOperations contains whether to include authorized operations.
+	IncludeAuthorizedOperations bool
+}
+
+func (r *ConsumerGroupDescribeRequest) setVersion(v int16) {
+	r.Version = v
+}
+
+func (r *ConsumerGroupDescribeRequest) encode(pe packetEncoder) error {
+	if err := pe.putStringArray(r.GroupIds); err != nil {
+		return err
+	}
+	pe.putBool(r.IncludeAuthorizedOperations)
+	pe.putEmptyTaggedFieldArray()
+	return nil
+}
+
+func (r *ConsumerGroupDescribeRequest) decode(pd packetDecoder, version int16) (err error) {
+	r.Version = version
+	if r.GroupIds, err = pd.getStringArray(); err != nil {
+		return err
+	}
+	if r.IncludeAuthorizedOperations, err = pd.getBool(); err != nil {
+		return err
+	}
+	_, err = pd.getEmptyTaggedFieldArray()
+	return err
+}
+
+func (r *ConsumerGroupDescribeRequest) key() int16 {
+	return apiKeyConsumerGroupDescribe
+}
+
+func (r *ConsumerGroupDescribeRequest) version() int16 {
+	return r.Version
+}
+
+func (r *ConsumerGroupDescribeRequest) headerVersion() int16 {
+	return 2
+}
+
+func (r *ConsumerGroupDescribeRequest) isValidVersion() bool {
+	return r.Version == 0
+}
+
+func (r *ConsumerGroupDescribeRequest) isFlexible() bool {
+	return r.isFlexibleVersion(r.Version)
+}
+
+func (r *ConsumerGroupDescribeRequest) isFlexibleVersion(version int16) bool {
+	return version >= 0
+}
+
+func (r *ConsumerGroupDescribeRequest) requiredVersion() KafkaVersion {
+	return V4_0_0_0
+}
@@ -0,0 +1,22 @@
+//go:build !functional
+
+package sarama
+
+import "testing"
+
+var consumerGroupDescribeRequestV0 = []byte{
+	3, // GroupIds array, length 2
+	4, 'f', 'o', 'o',
+	4, 'b', 'a', 'r',
+	1, // IncludeAuthorizedOperations
+	0, // empty tagged fields
+}
+
+func TestConsumerGroupDescribeRequest(t *testing.T) {
+	request := &ConsumerGroupDescribeRequest{
+		Version:                     0,
+		GroupIds:                    []string{"foo", "bar"},
+		IncludeAuthorizedOperations: true,
+	}
+	testRequest(t, "V0", request, consumerGroupDescribeRequestV0)
+}
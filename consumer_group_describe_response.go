@@ -0,0 +1,349 @@
+package sarama
+
+import "time"
+
+// ConsumerGroupDescribeTopicPartitions contains the partitions of a single
+// topic of a ConsumerGroupDescribeAssignment.
+type ConsumerGroupDescribeTopicPartitions struct {
+	// TopicID contains the topic ID.
+	TopicID Uuid
+	// TopicName contains the topic name.
+	TopicName string
+	// Partitions contains the partition indexes.
+	Partitions []int32
+}
+
+func (t *ConsumerGroupDescribeTopicPartitions) encode(pe packetEncoder) error {
+	if err := pe.putRawBytes(t.TopicID[:]); err != nil {
+		return err
+	}
+	if err := pe.putString(t.TopicName); err != nil {
+		return err
+	}
+	if err := pe.putInt32Array(t.Partitions); err != nil {
+		return err
+	}
+	pe.putEmptyTaggedFieldArray()
+	return nil
+}
+
+func (t *ConsumerGroupDescribeTopicPartitions) decode(pd packetDecoder) (err error) {
+	id, err := pd.getRawBytes(16)
+	if err != nil {
+		return err
+	}
+	copy(t.TopicID[:], id)
+	if t.TopicName, err = pd.getString(); err != nil {
+		return err
+	}
+	if t.Partitions, err = pd.getInt32Array(); err != nil {
+		return err
+	}
+	_, err = pd.getEmptyTaggedFieldArray()
+	return err
+}
+
+// ConsumerGroupDescribeAssignment contains the partitions assigned to a member.
+type ConsumerGroupDescribeAssignment struct {
+	// TopicPartitions contains the assigned topic partitions.
+	TopicPartitions []ConsumerGroupDescribeTopicPartitions
+}
+
+func (a *ConsumerGroupDescribeAssignment) encode(pe packetEncoder) error {
+	if err := pe.putArrayLength(len(a.TopicPartitions)); err != nil {
+		return err
+	}
+	for i := range a.TopicPartitions {
+		if err := a.TopicPartitions[i].encode(pe); err != nil {
+			return err
+		}
+	}
+	pe.putEmptyTaggedFieldArray()
+	return nil
+}
+
+func (a *ConsumerGroupDescribeAssignment) decode(pd packetDecoder) error {
+	n, err := pd.getArrayLength()
+	if err != nil {
+		return err
+	}
+	a.TopicPartitions = make([]ConsumerGroupDescribeTopicPartitions, n)
+	for i := range a.TopicPartitions {
+		if err := a.TopicPartitions[i].decode(pd); err != nil {
+			return err
+		}
+	}
+	_, err = pd.getEmptyTaggedFieldArray()
+	return err
+}
+
+// Topics returns the assignment as a map of topic names to partitions.
+func (a *ConsumerGroupDescribeAssignment) Topics() map[string][]int32 {
+	topics := make(map[string][]int32, len(a.TopicPartitions))
+	for _, tp := range a.TopicPartitions {
+		topics[tp.TopicName] = append(topics[tp.TopicName], tp.Partitions...)
+	}
+	return topics
+}
+
+// ConsumerGroupDescribeMember describes a single member of a consumer group.
+type ConsumerGroupDescribeMember struct {
+	// MemberId contains the member ID.
+	MemberId string
+	// InstanceId contains the member instance ID for static members.
+	InstanceId *string
+	// RackId contains the member rack ID.
+	RackId *string
+	// MemberEpoch contains the current member epoch.
+	MemberEpoch int32
+	// ClientId contains the client ID.
+	ClientId string
+	// ClientHost contains the client host.
+	ClientHost string
+	// SubscribedTopicNames contains the subscribed topic names.
+	SubscribedTopicNames []string
+	// SubscribedTopicRegex contains the subscribed topic regex, or nil.
+	SubscribedTopicRegex *string
+	// Assignment contains the current assignment.
+	Assignment ConsumerGroupDescribeAssignment
+	// TargetAssignment contains the target assignment.
+	TargetAssignment ConsumerGroupDescribeAssignment
+}
+
+func (m *ConsumerGroupDescribeMember) encode(pe packetEncoder) error {
+	if err := pe.putString(m.MemberId); err != nil {
+		return err
+	}
+	if err := pe.putNullableString(m.InstanceId); err != nil {
+		return err
+	}
+	if err := pe.putNullableString(m.RackId); err != nil {
+		return err
+	}
+	pe.putInt32(m.MemberEpoch)
+	if err := pe.putString(m.ClientId); err != nil {
+		return err
+	}
+	if err := pe.putString(m.ClientHost); err != nil {
+		return err
+	}
+	if err := pe.putStringArray(m.SubscribedTopicNames); err != nil {
+		return err
+	}
+	if err := pe.putNullableString(m.SubscribedTopicRegex); err != nil {
+		return err
+	}
+	if err := m.Assignment.encode(pe); err != nil {
+		return err
+	}
+	if err := m.TargetAssignment.encode(pe); err != nil {
+		return err
+	}
+	pe.putEmptyTaggedFieldArray()
+	return nil
+}
+
+func (m *ConsumerGroupDescribeMember) decode(pd packetDecoder) (err error) {
+	if m.MemberId, err = pd.getString(); err != nil {
+		return err
+	}
+	if m.InstanceId, err = pd.getNullableString(); err != nil {
+		return err
+	}
+	if m.RackId, err = pd.getNullableString(); err != nil {
+		return err
+	}
+	if m.MemberEpoch, err = pd.getInt32(); err != nil {
+		return err
+	}
+	if m.ClientId, err = pd.getString(); err != nil {
+		return err
+	}
+	if m.ClientHost, err = pd.getString(); err != nil {
+		return err
+	}
+	if m.SubscribedTopicNames, err = pd.getStringArray(); err != nil {
+		return err
+	}
+	if m.SubscribedTopicRegex, err = pd.getNullableString(); err != nil {
+		return err
+	}
+	if err = m.Assignment.decode(pd); err != nil {
+		return err
+	}
+	if err = m.TargetAssignment.decode(pd); err != nil {
+		return err
+	}
+	_, err = pd.getEmptyTaggedFieldArray()
+	return err
+}
+
+// ConsumerGroupDescribeGroup describes a single consumer group.
+type ConsumerGroupDescribeGroup struct {
+	// ErrorCode contains the describe error, or ErrNoError.
+	ErrorCode KError
+	// ErrorMessage contains the top-level error message, or nil.
+	ErrorMessage *string
+	// GroupId contains the group ID string.
+	GroupId string
+	// GroupState contains the group state string, or the empty string.
+	GroupState string
+	// GroupEpoch contains the group epoch.
+	GroupEpoch int32
+	// AssignmentEpoch contains the assignment epoch.
+	AssignmentEpoch int32
+	// AssignorName contains the selected assignor.
+	AssignorName string
+	// Members contains the members.
+	Members []ConsumerGroupDescribeMember
+	// AuthorizedOperations contains a 32-bit bitfield to represent authorized
+	// operations for this group.
+	AuthorizedOperations int32
+}
+
+func (g *ConsumerGroupDescribeGroup) encode(pe packetEncoder) error {
+	pe.putKError(g.ErrorCode)
+	if err := pe.putNullableString(g.ErrorMessage); err != nil {
+		return err
+	}
+	if err := pe.putString(g.GroupId); err != nil {
+		return err
+	}
+	if err := pe.putString(g.GroupState); err != nil {
+		return err
+	}
+	pe.putInt32(g.GroupEpoch)
+	pe.putInt32(g.AssignmentEpoch)
+	if err := pe.putString(g.AssignorName); err != nil {
+		return err
+	}
+	if err := pe.putArrayLength(len(g.Members)); err != nil {
+		return err
+	}
+	for i := range g.Members {
+		if err := g.Members[i].encode(pe); err != nil {
+			return err
+		}
+	}
+	pe.putInt32(g.AuthorizedOperations)
+	pe.putEmptyTaggedFieldArray()
+	return nil
+}
+
+func (g *ConsumerGroupDescribeGroup) decode(pd packetDecoder) (err error) {
+	if g.ErrorCode, err = pd.getKError(); err != nil {
+		return err
+	}
+	if g.ErrorMessage, err = pd.getNullableString(); err != nil {
+		return err
+	}
+	if g.GroupId, err = pd.getString(); err != nil {
+		return err
+	}
+	if g.GroupState, err = pd.getString(); err != nil {
+		return err
+	}
+	if g.GroupEpoch, err = pd.getInt32(); err != nil {
+		return err
+	}
+	if g.AssignmentEpoch, err = pd.getInt32(); err != nil {
+		return err
+	}
+	if g.AssignorName, err = pd.getString(); err != nil {
+		return err
+	}
+	n, err := pd.getArrayLength()
+	if err != nil {
+		return err
+	}
+	g.Members = make([]ConsumerGroupDescribeMember, n)
+	for i := range g.Members {
+		if err := g.Members[i].decode(pd); err != nil {
+			return err
+		}
+	}
+	if g.AuthorizedOperations, err = pd.getInt32(); err != nil {
+		return err
+	}
+	_, err = pd.getEmptyTaggedFieldArray()
+	return err
+}
+
+type ConsumerGroupDescribeResponse struct {
+	// Version defines the protocol version to use for encode and decode
+	Version int16
+	// ThrottleTime contains the duration for which the request was throttled due
+	// to a quota violation, or zero if the request did not violate any quota.
+	ThrottleTime time.Duration
+	// Groups contains each described group.
+	Groups []ConsumerGroupDescribeGroup
+}
+
+func (r *ConsumerGroupDescribeResponse) setVersion(v int16) {
+	r.Version = v
+}
+
+func (r *ConsumerGroupDescribeResponse) encode(pe packetEncoder) error {
+	pe.putDurationMs(r.ThrottleTime)
+	if err := pe.putArrayLength(len(r.Groups)); err != nil {
+		return err
+	}
+	for i := range r.Groups {
+		if err := r.Groups[i].encode(pe); err != nil {
+			return err
+		}
+	}
+	pe.putEmptyTaggedFieldArray()
+	return nil
+}
+
+func (r *ConsumerGroupDescribeResponse) decode(pd packetDecoder, version int16) (err error) {
+	r.Version = version
+	if r.ThrottleTime, err = pd.getDurationMs(); err != nil {
+		return err
+	}
+	n, err := pd.getArrayLength()
+	if err != nil {
+		return err
+	}
+	r.Groups = make([]ConsumerGroupDescribeGroup, n)
+	for i := range r.Groups {
+		if err := r.Groups[i].decode(pd); err != nil {
+			return err
+		}
+	}
+	_, err = pd.getEmptyTaggedFieldArray()
+	return err
+}
+
+func (r *ConsumerGroupDescribeResponse) key() int16 {
+	return apiKeyConsumerGroupDescribe
+}
+
+func (r *ConsumerGroupDescribeResponse) version() int16 {
+	return r.Version
+}
+
+func (r *ConsumerGroupDescribeResponse) headerVersion() int16 {
+	return 1
+}
+
+func (r *ConsumerGroupDescribeResponse) isValidVersion() bool {
+	return r.Version == 0
+}
+
+func (r *ConsumerGroupDescribeResponse) isFlexible() bool {
+	return r.isFlexibleVersion(r.Version)
+}
+
+func (r *ConsumerGroupDescribeResponse) isFlexibleVersion(version int16) bool {
+	return version >= 0
+}
+
+func (r *ConsumerGroupDescribeResponse) requiredVersion() KafkaVersion {
+	return V4_0_0_0
+}
+
+func (r *ConsumerGroupDescribeResponse) throttleTime() time.Duration {
+	return r.ThrottleTime
+}
@@ -0,0 +1,103 @@
+//go:build !functional
+
+package sarama
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var consumerGroupDescribeResponseV0 = []byte{
+	0, 0, 0, 0, // ThrottleTimeMs
+	3,    // Groups array, length 2
+	0, 0, // ErrorCode
+	0,                // ErrorMessage, null
+	4, 'f', 'o', 'o', // GroupId
+	7, 'S', 't', 'a', 'b', 'l', 'e', // GroupState
+	0, 0, 0, 3, // GroupEpoch
+	0, 0, 0, 3, // AssignmentEpoch
+	8, 'u', 'n', 'i', 'f', 'o', 'r', 'm', // AssignorName
+	2,           // Members array, length 1
+	3, 'm', '1', // MemberId
+	3, 'i', '1', // InstanceId
+	0,          // RackId, null
+	0, 0, 0, 2, // MemberEpoch
+	3, 'c', '1', // ClientId
+	3, 'h', '1', // ClientHost
+	2, 2, 't', // SubscribedTopicNames array, length 1
+	0, // SubscribedTopicRegex, null
+	// Assignment
+	2,                                              // TopicPartitions array, length 1
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, // TopicId
+	2, 't', // TopicName
+	3, 0, 0, 0, 0, 0, 0, 0, 1, // Partitions
+	0, // empty tagged fields
+	0, // empty tagged fields
+	// TargetAssignment
+	1,             // TopicPartitions array, length 0
+	0,             // empty tagged fields
+	0,             // empty tagged fields
+	0x80, 0, 0, 0, // AuthorizedOperations
+	0,     // empty tagged fields
+	0, 69, // ErrorCode
+	10, 'n', 'o', 't', ' ', 'f', 'o', 'u', 'n', 'd', // ErrorMessage
+	4, 'b', 'a', 'r', // GroupId
+	1,          // GroupState
+	0, 0, 0, 0, // GroupEpoch
+	0, 0, 0, 0, // AssignmentEpoch
+	1,             // AssignorName
+	1,             // Members array, length 0
+	0x80, 0, 0, 0, // AuthorizedOperations
+	0, // empty tagged fields
+	0, // empty tagged fields
+}
+
+func TestConsumerGroupDescribeResponse(t *testing.T) {
+	instanceID := "i1"
+	notFound := "not found"
+	response := &ConsumerGroupDescribeResponse{
+		Version: 0,
+		Groups: []ConsumerGroupDescribeGroup{
+			{
+				ErrorCode:       ErrNoError,
+				GroupId:         "foo",
+				GroupState:      "Stable",
+				GroupEpoch:      3,
+				AssignmentEpoch: 3,
+				AssignorName:    "uniform",
+				Members: []ConsumerGroupDescribeMember{{
+					MemberId:             "m1",
+					InstanceId:           &instanceID,
+					MemberEpoch:          2,
+					ClientId:             "c1",
+					ClientHost:           "h1",
+					SubscribedTopicNames: []string{"t"},
+					Assignment: ConsumerGroupDescribeAssignment{
+						TopicPartitions: []ConsumerGroupDescribeTopicPartitions{{
+							TopicID:    Uuid{15: 1},
+							TopicName:  "t",
+							Partitions: []int32{0, 1},
+						}},
+					},
+					TargetAssignment: ConsumerGroupDescribeAssignment{
+						TopicPartitions: []ConsumerGroupDescribeTopicPartitions{},
+					},
+				}},
+				AuthorizedOperations: -2147483648,
+			},
+			{
+				ErrorCode:            ErrGroupIDNotFound,
+				ErrorMessage:         &notFound,
+				GroupId:              "bar",
+				Members:              []ConsumerGroupDescribeMember{},
+				AuthorizedOperations: -2147483648,
+			},
+		},
+	}
+	testResponse(t, "V0", response, consumerGroupDescribeResponseV0)
+
+	decoded := new(ConsumerGroupDescribeResponse)
+	testVersionDecodable(t, "V0", decoded, consumerGroupDescribeResponseV0, 0)
+	require.Equal(t, map[string][]int32{"t": {0, 1}}, decoded.Groups[0].Members[0].Assignment.Topics())
+}
@@ -0,0 +1,191 @@
+package sarama
+
+import "errors"
+
+// ConsumerProtocolType is the protocol type of groups formed by consumers
+// using the classic rebalance protocol.
+const ConsumerProtocolType = "consumer"
+
+const (
+	// ConsumerGroupTypeClassic identifies groups using the classic rebalance
+	// protocol (JoinGroup/SyncGroup).
+	ConsumerGroupTypeClassic = "classic"
+	// ConsumerGroupTypeConsumer identifies groups using the consumer rebalance
+	// protocol introduced by KIP-848.
+	ConsumerGroupTypeConsumer = "consumer"
+)
+
+// ConsumerGroupMemberDescription is the decoded description of a single
+// consumer group member, independent of the rebalance protocol of its group.
+type ConsumerGroupMemberDescription struct {
+	// MemberId contains the member ID assigned by the group coordinator.
+	MemberId string
+	// GroupInstanceId contains the instance ID of static members, or nil.
+	GroupInstanceId *string
+	// ClientId contains the client ID of the member.
+	ClientId string
+	// ClientHost contains the client host of the member.
+	ClientHost string
+	// RackId contains the rack of the member, or nil.
+	RackId *string
+	// Topics contains the topics the member subscribed to.
+	Topics []string
+	// TopicRegex contains the regex the member subscribed to, or nil. Only set
+	// for groups using the consumer rebalance protocol.
+	TopicRegex *string
+	// OwnedPartitions contains the partitions the member reported as owned in
+	// its subscription. Only set for the classic rebalance protocol.
+	OwnedPartitions map[string][]int32
+	// UserData contains the user data of the subscription. Only set for the
+	// classic rebalance protocol.
+	UserData []byte
+	// Assignment contains the partitions currently assigned to the member.
+	Assignment map[string][]int32
+	// AssignmentUserData contains the user data the group leader attached to
+	// the assignment. Only set for the classic rebalance protocol.
+	AssignmentUserData []byte
+	// MemberEpoch contains the current member epoch, or -1 for the classic
+	// rebalance protocol.
+	MemberEpoch int32
+	// TargetAssignment contains the partitions the member is converging to.
+	// Only set for groups using the consumer rebalance protocol.
+	TargetAssignment map[string][]int32
+}
+
+// ConsumerGroupDescription is the decoded description of a consumer group,
+// independent of its rebalance protocol.
+type ConsumerGroupDescription struct {
+	// GroupId contains the group ID.
+	GroupId string
+	// Type contains the rebalance protocol of the group, either
+	// ConsumerGroupTypeClassic or ConsumerGroupTypeConsumer.
+	Type string
+	// State contains the group state.
+	State string
+	// Assignor contains the name of the partition assignor in use.
+	Assignor string
+	// GroupEpoch contains the group epoch, or -1 for the classic rebalance
+	// protocol.
+	GroupEpoch int32
+	// AssignmentEpoch contains the assignment epoch, or -1 for the classic
+	// rebalance protocol.
+	AssignmentEpoch int32
+	// Members contains the decoded group members keyed by member ID.
+	Members map[string]*ConsumerGroupMemberDescription
+	// AuthorizedOperations contains a 32-bit bitfield to represent authorized
+	// operations for this group.
+	AuthorizedOperations int32
+	// Err contains the error returned for the group, or nil.
+	Err error
+}
+
+// ConsumerMember decodes the metadata and assignment of a member of a group
+// using the classic consumer protocol.
+func (gmd *GroupMemberDescription) ConsumerMember() (*ConsumerGroupMemberDescription, error) {
+	member := &ConsumerGroupMemberDescription{
+		MemberId:        gmd.MemberId,
+		GroupInstanceId: gmd.GroupInstanceId,
+		ClientId:        gmd.ClientId,
+		ClientHost:      gmd.ClientHost,
+		MemberEpoch:     -1,
+	}
+
+	metadata, err := gmd.GetMemberMetadata()
+	if err != nil {
+		return nil, err
+	}
+	if metadata != nil {
+		member.Topics = metadata.Topics
+		member.UserData = metadata.UserData
+		member.RackId = metadata.RackID
+		if len(metadata.OwnedPartitions) > 0 {
+			member.OwnedPartitions = make(map[string][]int32, len(metadata.OwnedPartitions))
+			for _, owned := range metadata.OwnedPartitions {
+				member.OwnedPartitions[owned.Topic] = append(member.OwnedPartitions[owned.Topic], owned.Partitions...)
+			}
+		}
+	}
+
+	assignment, err := gmd.GetMemberAssignment()
+	if err != nil {
+		return nil, err
+	}
+	if assignment != nil {
+		member.Assignment = assignment.Topics
+		member.AssignmentUserData = assignment.UserData
+	}
+
+	return member, nil
+}
+
+// ConsumerMembers decodes all members of a group using the classic consumer
+// protocol. It returns ErrNotConsumerProtocolGroup for groups of other
+// protocol types, such as Kafka Connect workers.
+func (gd *GroupDescription) ConsumerMembers() (map[string]*ConsumerGroupMemberDescription, error) {
+	if gd.ProtocolType != ConsumerProtocolType && len(gd.Members) > 0 {
+		return nil, ErrNotConsumerProtocolGroup
+	}
+	members := make(map[string]*ConsumerGroupMemberDescription, len(gd.Members))
+	for id, gmd := range gd.Members {
+		member, err := gmd.ConsumerMember()
+		if err != nil {
+			return nil, err
+		}
+		members[id] = member
+	}
+	return members, nil
+}
+
+func newClassicConsumerGroupDescription(gd *GroupDescription) *ConsumerGroupDescription {
+	d := &ConsumerGroupDescription{
+		GroupId:              gd.GroupId,
+		Type:                 ConsumerGroupTypeClassic,
+		State:                gd.State,
+		Assignor:             gd.Protocol,
+		GroupEpoch:           -1,
+		AssignmentEpoch:      -1,
+		AuthorizedOperations: gd.AuthorizedOperations,
+	}
+	if !errors.Is(gd.Err, ErrNoError) {
+		d.Err = gd.Err
+		return d
+	}
+	d.Members, d.Err = gd.ConsumerMembers()
+	return d
+}
+
+func newConsumerGroupDescription(g *ConsumerGroupDescribeGroup) *ConsumerGroupDescription {
+	d := &ConsumerGroupDescription{
+		GroupId:              g.GroupId,
+		Type:                 ConsumerGroupTypeConsumer,
+		State:                g.GroupState,
+		Assignor:             g.AssignorName,
+		GroupEpoch:           g.GroupEpoch,
+		AssignmentEpoch:      g.AssignmentEpoch,
+		AuthorizedOperations: g.AuthorizedOperations,
+		Members:              make(map[string]*ConsumerGroupMemberDescription, len(g.Members)),
+	}
+	if !errors.Is(g.ErrorCode, ErrNoError) {
+		d.Err = g.ErrorCode
+		if g.ErrorMessage != nil && *g.ErrorMessage != "" {
+			d.Err = Wrap(g.ErrorCode, errors.New(*g.ErrorMessage))
+		}
+		return d
+	}
+	for i := range g.Members {
+		m := &g.Members[i]
+		d.Members[m.MemberId] = &ConsumerGroupMemberDescription{
+			MemberId:         m.MemberId,
+			GroupInstanceId:  m.InstanceId,
+			ClientId:         m.ClientId,
+			ClientHost:       m.ClientHost,
+			RackId:           m.RackId,
+			Topics:           m.SubscribedTopicNames,
+			TopicRegex:       m.SubscribedTopicRegex,
+			Assignment:       m.Assignment.Topics(),
+			MemberEpoch:      m.MemberEpoch,
+			TargetAssignment: m.TargetAssignment.Topics(),
+		}
+	}
+	return d
+}
@@ -13,6 +13,8 @@ import (
 	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/rcrowley/go-metrics"
 )
 
 var (
@@ -697,6 +699,100 @@ func TestConsumerExtraOffsets(t *testing.T) {
 	}
 }
 
+func TestConsumerFilter(t *testing.T) {
+	// Given
+	legacyFetchResponse := &FetchResponse{}
+	newFetchResponse := &FetchResponse{Version: 5}
+	for offset, tenant := range map[int64]string{1: "b", 2: "a", 3: "b", 4: "b"} {
+		legacyFetchResponse.AddMessage("my_topic", 0, StringEncoder(tenant), testMsg, offset)
+	}
+	for offset, tenant := range []string{"b", "a", "b", "b"} {
+		newFetchResponse.AddRecord("my_topic", 0, nil, testMsg, int64(offset+1))
+		record := newFetchResponse.GetBlock("my_topic", 0).RecordsSet[0].RecordBatch.Records[offset]
+		record.Headers = []*RecordHeader{{Key: []byte("tenant"), Value: []byte(tenant)}}
+	}
+	newFetchResponse.SetLastOffsetDelta("my_topic", 0, 4)
+	newFetchResponse.SetLastStableOffset("my_topic", 0, 4)
+	for _, fetchResponse1 := range []*FetchResponse{legacyFetchResponse, newFetchResponse} {
+		cfg := NewTestConfig()
+		cfg.Consumer.Return.Errors = true
+		if fetchResponse1.Version >= 5 {
+			cfg.Version = V0_11_0_0
+		}
+		cfg.Consumer.Filter = func(record FetchedRecord) bool {
+			if record.Topic != "my_topic" || record.Partition != 0 {
+				t.Errorf("Unexpected record of %s/%d", record.Topic, record.Partition)
+			}
+			for _, header := range record.Headers {
+				if string(header.Key) == "tenant" {
+					return string(header.Value) == "a"
+				}
+			}
+			return string(record.Key) == "a"
+		}
+
+		broker0 := NewMockBroker(t, 0)
+		fetchResponse2 := &FetchResponse{}
+		fetchResponse2.Version = fetchResponse1.Version
+		fetchResponse2.AddError("my_topic", 0, ErrNoError)
+		broker0.SetHandlerByMap(map[string]MockResponse{
+			"MetadataRequest": NewMockMetadataResponse(t).
+				SetBroker(broker0.Addr(), broker0.BrokerID()).
+				SetLeader("my_topic", 0, broker0.BrokerID()),
+			"OffsetRequest": NewMockOffsetResponse(t).
+				SetOffset("my_topic", 0, OffsetNewest, 1234).
+				SetOffset("my_topic", 0, OffsetOldest, 0),
+			"FetchRequest": NewMockSequence(fetchResponse1, fetchResponse2),
+		})
+
+		master, err := NewConsumer([]string{broker0.Addr()}, cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// When
+		consumer, err := master.ConsumePartition("my_topic", 0, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Then: only the message with offset 2 is returned
+		select {
+		case msg := <-consumer.Messages():
+			assertMessageOffset(t, msg, 2)
+		case err := <-consumer.Errors():
+			t.Fatal(err)
+		}
+
+		// and the next fetch starts after the dropped records
+		fetchedFrom := func() int64 {
+			var offset int64
+			for _, rr := range broker0.History() {
+				if req, ok := rr.Request.(*FetchRequest); ok {
+					offset = req.blocks["my_topic"][0].fetchOffset
+				}
+			}
+			return offset
+		}
+		deadline := time.Now().Add(5 * time.Second)
+		for fetchedFrom() != 5 && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		if offset := fetchedFrom(); offset != 5 {
+			t.Errorf("Expected fetching from offset 5, got %d", offset)
+		}
+		select {
+		case msg := <-consumer.Messages():
+			t.Errorf("Unexpected message with offset %d", msg.Offset)
+		default:
+		}
+
+		safeClose(t, consumer)
+		safeClose(t, master)
+		broker0.Close()
+	}
+}
+
 // In some situations broker may return a block containing only
 // messages older then requested, even though there would be
 // more messages if higher offset was requested.
@@ -2143,3 +2239,108 @@ func TestConsumerError(t *testing.T) {
 		t.Error("unexpected errors.Is")
 	}
 }
+
+func TestConsumerPartitionMetricsLeadMin(t *testing.T) {
+	c := &consumer{
+		children:       map[string]map[int32]*partitionConsumer{"my_topic": {}},
+		metricRegistry: metrics.NewRegistry(),
+	}
+	for partition, lead := range []int64{0, 5, -1} {
+		child := &partitionConsumer{}
+		child.lead.Store(lead)
+		c.children["my_topic"][int32(partition)] = child
+	}
+
+	c.updatePartitionMetrics()
+	// the partition at the log start offset has the minimum lead
+	if gauge := c.metricRegistry.Get("records-lead-min").(metrics.Gauge); gauge.Value() != 0 {
+		t.Errorf("Expected records-lead-min to be 0, got %d", gauge.Value())
+	}
+
+	c.children["my_topic"][0].lead.Store(-1)
+	c.updatePartitionMetrics()
+	if gauge := c.metricRegistry.Get("records-lead-min").(metrics.Gauge); gauge.Value() != 5 {
+		t.Errorf("Expected records-lead-min to be 5, got %d", gauge.Value())
+	}
+}
+
+func TestConsumerFetchMetrics(t *testing.T) {
+	fetchResponse := &FetchResponse{Version: 5}
+	for offset := int64(1); offset <= 3; offset++ {
+		fetchResponse.AddRecord("my_topic", 0, nil, testMsg, offset)
+	}
+	block := fetchResponse.GetBlock("my_topic", 0)
+	block.HighWaterMarkOffset = 10
+	block.LogStartOffset = 1
+
+	broker0 := NewMockBroker(t, 0)
+	defer broker0.Close()
+	broker0.SetHandlerByMap(map[string]MockResponse{
+		"MetadataRequest": NewMockMetadataResponse(t).
+			SetBroker(broker0.Addr(), broker0.BrokerID()).
+			SetLeader("my_topic", 0, broker0.BrokerID()),
+		"OffsetRequest": NewMockOffsetResponse(t).
+			SetOffset("my_topic", 0, OffsetNewest, 10).
+			SetOffset("my_topic", 0, OffsetOldest, 1),
+		"FetchRequest": NewMockSequence(fetchResponse, &FetchResponse{Version: 5}),
+	})
+
+	config := NewTestConfig()
+	config.Version = V0_11_0_0
+	master, err := NewConsumer([]string{broker0.Addr()}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer safeClose(t, master)
+
+	consumer, err := master.ConsumePartition("my_topic", 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 3 {
+		<-consumer.Messages()
+	}
+	consumer.Pause()
+
+	metricRegistry := config.MetricRegistry
+	// the metrics summarizing the partitions are updated once the whole fetch
+	// response has been handled
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if gauge, ok := metricRegistry.Get("records-lead-min").(metrics.Gauge); ok && gauge.Value() != 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	for name, expected := range map[string]int64{
+		"records-lag-for-topic-my_topic-for-partition-0":  6,
+		"records-lead-for-topic-my_topic-for-partition-0": 3,
+		"records-lag-max":  6,
+		"records-lead-min": 3,
+	} {
+		if gauge, ok := metricRegistry.Get(name).(metrics.Gauge); !ok || gauge.Value() != expected {
+			t.Errorf("Expected %s to be %d, got %v", name, expected, metricRegistry.Get(name))
+		}
+	}
+	if counter, ok := metricRegistry.Get("consumer-paused-partitions").(metrics.Counter); !ok || counter.Count() != 1 {
+		t.Errorf("Expected one paused partition, got %v", metricRegistry.Get("consumer-paused-partitions"))
+	}
+	if meter, ok := metricRegistry.Get("bytes-consumed-rate-for-topic-my_topic").(metrics.Meter); !ok || meter.Count() != 3*int64(len(testMsg)) {
+		t.Errorf("Expected %d bytes consumed, got %v", 3*len(testMsg), metricRegistry.Get("bytes-consumed-rate-for-topic-my_topic"))
+	}
+	if histogram, ok := metricRegistry.Get("fetch-latency-in-ms-for-broker-0").(metrics.Histogram); !ok || histogram.Count() == 0 {
+		t.Error("Expected the fetch latency to be recorded")
+	}
+
+	// the metrics of the partition are removed once it is no longer consumed
+	safeClose(t, consumer)
+	if metric := metricRegistry.Get("records-lag-for-topic-my_topic-for-partition-0"); metric != nil {
+		t.Error("Expected the records-lag of the partition to be unregistered")
+	}
+	if counter := metricRegistry.Get("consumer-paused-partitions").(metrics.Counter); counter.Count() != 0 {
+		t.Errorf("Expected no paused partition, got %d", counter.Count())
+	}
+	if gauge := metricRegistry.Get("records-lag-max").(metrics.Gauge); gauge.Value() != 0 {
+		t.Errorf("Expected records-lag-max to be reset, got %d", gauge.Value())
+	}
+}
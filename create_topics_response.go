@@ -1,6 +1,7 @@
 package sarama
 
 import (
+	"errors"
 	"fmt"
 	"time"
 )
@@ -318,3 +319,39 @@ func (c *CreatableTopicConfigs) decode(pd packetDecoder, version int16) (err err
 	}
 	return nil
 }
+
+// CreateTopicResult contains the outcome of the creation of a single topic
+// through ClusterAdmin.CreateTopics.
+type CreateTopicResult struct {
+	// Err is the error returned for the topic, or nil if it was created (or
+	// validated) successfully.
+	Err error
+	// NumPartitions is the number of partitions of the topic, or -1 if the
+	// broker did not report it (CreateTopicsResponse v5+).
+	NumPartitions int32
+	// ReplicationFactor is the replication factor of the topic, or -1 if the
+	// broker did not report it (CreateTopicsResponse v5+).
+	ReplicationFactor int16
+	// Configs contains the configuration of the topic as reported by the broker
+	// (CreateTopicsResponse v5+).
+	Configs map[string]*CreatableTopicConfigs
+	// ConfigErr is the error that prevented the broker from returning the topic
+	// configs even though the topic was created, or nil.
+	ConfigErr error
+}
+
+func newCreateTopicResult(topicErr *TopicError, result *CreatableTopicResult) *CreateTopicResult {
+	r := &CreateTopicResult{NumPartitions: -1, ReplicationFactor: -1}
+	if topicErr != nil && !errors.Is(topicErr.Err, ErrNoError) {
+		r.Err = topicErr
+	}
+	if result != nil {
+		r.NumPartitions = result.NumPartitions
+		r.ReplicationFactor = result.ReplicationFactor
+		r.Configs = result.Configs
+		if !errors.Is(result.TopicConfigErrorCode, ErrNoError) {
+			r.ConfigErr = result.TopicConfigErrorCode
+		}
+	}
+	return r
+}
@@ -0,0 +1,91 @@
+package sarama
+
+// DescribeClusterEndpointType selects which endpoints are returned by a
+// DescribeClusterRequest (KIP-919).
+type DescribeClusterEndpointType int8
+
+const (
+	// DescribeClusterEndpointBrokers returns the broker endpoints
+	DescribeClusterEndpointBrokers DescribeClusterEndpointType = 1
+	// DescribeClusterEndpointControllers returns the KRaft controller endpoints
+	DescribeClusterEndpointControllers DescribeClusterEndpointType = 2
+)
+
+type DescribeClusterRequest struct {
+	// Version defines the protocol version to use for encode and decode
+	Version int16
+	// IncludeClusterAuthorizedOperations contains whether to include cluster authorized operations.
+	IncludeClusterAuthorizedOperations bool
+	// EndpointType contains the endpoint type to describe (v1+), defaulting to brokers.
+	EndpointType DescribeClusterEndpointType
+}
+
+func NewDescribeClusterRequest(version KafkaVersion) *DescribeClusterRequest {
+	r := &DescribeClusterRequest{EndpointType: DescribeClusterEndpointBrokers}
+	if version.IsAtLeast(V3_7_0_0) {
+		r.Version = 1
+	}
+	return r
+}
+
+func (r *DescribeClusterRequest) setVersion(v int16) {
+	r.Version = v
+}
+
+func (r *DescribeClusterRequest) encode(pe packetEncoder) error {
+	pe.putBool(r.IncludeClusterAuthorizedOperations)
+	if r.Version >= 1 {
+		pe.putInt8(int8(r.EndpointType))
+	}
+	pe.putEmptyTaggedFieldArray()
+	return nil
+}
+
+func (r *DescribeClusterRequest) decode(pd packetDecoder, version int16) (err error) {
+	r.Version = version
+	if r.IncludeClusterAuthorizedOperations, err = pd.getBool(); err != nil {
+		return err
+	}
+	if r.Version >= 1 {
+		endpointType, err := pd.getInt8()
+		if err != nil {
+			return err
+		}
+		r.EndpointType = DescribeClusterEndpointType(endpointType)
+	}
+	_, err = pd.getEmptyTaggedFieldArray()
+	return err
+}
+
+func (r *DescribeClusterRequest) key() int16 {
+	return apiKeyDescribeCluster
+}
+
+func (r *DescribeClusterRequest) version() int16 {
+	return r.Version
+}
+
+func (r *DescribeClusterRequest) headerVersion() int16 {
+	return 2
+}
+
+func (r *DescribeClusterRequest) isValidVersion() bool {
+	return r.Version >= 0 && r.Version <= 1
+}
+
+func (r *DescribeClusterRequest) isFlexible() bool {
+	return r.isFlexibleVersion(r.Version)
+}
+
+func (r *DescribeClusterRequest) isFlexibleVersion(version int16) bool {
+	return version >= 0
+}
+
+func (r *DescribeClusterRequest) requiredVersion() KafkaVersion {
+	switch r.Version {
+	case 1:
+		return V3_7_0_0
+	default:
+		return V2_8_0_0
+	}
+}
@@ -0,0 +1,32 @@
+//go:build !functional
+
+package sarama
+
+import "testing"
+
+var (
+	describeClusterRequestV0 = []byte{
+		1, // IncludeClusterAuthorizedOperations
+		0, // empty tagged fields
+	}
+
+	describeClusterRequestV1 = []byte{
+		0, // IncludeClusterAuthorizedOperations
+		1, // EndpointType brokers
+		0, // empty tagged fields
+	}
+)
+
+func TestDescribeClusterRequest(t *testing.T) {
+	request := &DescribeClusterRequest{
+		Version:                            0,
+		IncludeClusterAuthorizedOperations: true,
+	}
+	testRequest(t, "V0", request, describeClusterRequestV0)
+
+	request = &DescribeClusterRequest{
+		Version:      1,
+		EndpointType: DescribeClusterEndpointBrokers,
+	}
+	testRequest(t, "V1", request, describeClusterRequestV1)
+}
@@ -0,0 +1,141 @@
+package sarama
+
+import "time"
+
+type DescribeClusterResponse struct {
+	// Version defines the protocol version to use for encode and decode
+	Version int16
+	// ThrottleTime contains the duration for which the request was throttled due
+	// to a quota violation, or zero if the request did not violate any quota.
+	ThrottleTime time.Duration
+	// ErrorCode contains the top-level error code, or 0 if there was no error
+	ErrorCode KError
+	// ErrorMessage contains the top-level error message, or null if there was no error.
+	ErrorMessage *string
+	// EndpointType contains the endpoint type that was described (v1+).
+	EndpointType DescribeClusterEndpointType
+	// ClusterID contains the cluster ID that responding broker belongs to.
+	ClusterID string
+	// ControllerID contains the ID of the controller broker, or -1 if it is
+	// unknown. When describing controller endpoints this is a random controller.
+	ControllerID int32
+	// Brokers contains each broker in the response.
+	Brokers []*Broker
+	// ClusterAuthorizedOperations contains a 32-bit bitfield to represent authorized operations for this cluster.
+	ClusterAuthorizedOperations int32
+}
+
+func (r *DescribeClusterResponse) setVersion(v int16) {
+	r.Version = v
+}
+
+func (r *DescribeClusterResponse) encode(pe packetEncoder) error {
+	pe.putDurationMs(r.ThrottleTime)
+	pe.putKError(r.ErrorCode)
+	if err := pe.putNullableString(r.ErrorMessage); err != nil {
+		return err
+	}
+	if r.Version >= 1 {
+		pe.putInt8(int8(r.EndpointType))
+	}
+	if err := pe.putString(r.ClusterID); err != nil {
+		return err
+	}
+	pe.putInt32(r.ControllerID)
+
+	if err := pe.putArrayLength(len(r.Brokers)); err != nil {
+		return err
+	}
+	for _, broker := range r.Brokers {
+		// the broker layout matches the v1+ MetadataResponse one
+		if err := broker.encode(pe, 1); err != nil {
+			return err
+		}
+	}
+
+	pe.putInt32(r.ClusterAuthorizedOperations)
+	pe.putEmptyTaggedFieldArray()
+	return nil
+}
+
+func (r *DescribeClusterResponse) decode(pd packetDecoder, version int16) (err error) {
+	r.Version = version
+	if r.ThrottleTime, err = pd.getDurationMs(); err != nil {
+		return err
+	}
+	if r.ErrorCode, err = pd.getKError(); err != nil {
+		return err
+	}
+	if r.ErrorMessage, err = pd.getNullableString(); err != nil {
+		return err
+	}
+	if r.Version >= 1 {
+		endpointType, err := pd.getInt8()
+		if err != nil {
+			return err
+		}
+		r.EndpointType = DescribeClusterEndpointType(endpointType)
+	}
+	if r.ClusterID, err = pd.getString(); err != nil {
+		return err
+	}
+	if r.ControllerID, err = pd.getInt32(); err != nil {
+		return err
+	}
+
+	n, err := pd.getArrayLength()
+	if err != nil {
+		return err
+	}
+	r.Brokers = make([]*Broker, n)
+	for i := 0; i < n; i++ {
+		r.Brokers[i] = new(Broker)
+		if err := r.Brokers[i].decode(pd, 1); err != nil {
+			return err
+		}
+	}
+
+	if r.ClusterAuthorizedOperations, err = pd.getInt32(); err != nil {
+		return err
+	}
+
+	_, err = pd.getEmptyTaggedFieldArray()
+	return err
+}
+
+func (r *DescribeClusterResponse) key() int16 {
+	return apiKeyDescribeCluster
+}
+
+func (r *DescribeClusterResponse) version() int16 {
+	return r.Version
+}
+
+func (r *DescribeClusterResponse) headerVersion() int16 {
+	return 1
+}
+
+func (r *DescribeClusterResponse) isValidVersion() bool {
+	return r.Version >= 0 && r.Version <= 1
+}
+
+func (r *DescribeClusterResponse) isFlexible() bool {
+	return r.isFlexibleVersion(r.Version)
+}
+
+func (r *DescribeClusterResponse) isFlexibleVersion(version int16) bool {
+	return version >= 0
+}
+
+func (r *DescribeClusterResponse) requiredVersion() KafkaVersion {
+	switch r.Version {
+	case 1:
+		return V3_7_0_0
+	default:
+		return V2_8_0_0
+	}
+}
+
+func (r *DescribeClusterResponse) throttleTime() time.Duration {
+	return r.ThrottleTime
+}
@@ -0,0 +1,54 @@
+//go:build !functional
+
+package sarama
+
+import "testing"
+
+var (
+	describeClusterResponseV0 = []byte{
+		0, 0, 0, 0, // ThrottleTimeMs
+		0, 0, // ErrorCode
+		0,           // ErrorMessage null
+		3, 'c', '1', // ClusterID
+		0, 0, 0, 1, // ControllerID
+		2,          // Brokers array, length 1
+		0, 0, 0, 1, // BrokerID
+		2, 'h', // Host
+		0, 0, 0x23, 0x84, // Port 9092
+		0,          // Rack null
+		0,          // empty tagged fields
+		0, 0, 0, 0, // ClusterAuthorizedOperations
+		0, // empty tagged fields
+	}
+
+	describeClusterResponseV1 = []byte{
+		0, 0, 0, 0, // ThrottleTimeMs
+		0, 0, // ErrorCode
+		0,           // ErrorMessage null
+		1,           // EndpointType brokers
+		3, 'c', '1', // ClusterID
+		0xff, 0xff, 0xff, 0xff, // ControllerID -1
+		1,          // Brokers array, length 0
+		0, 0, 0, 0, // ClusterAuthorizedOperations
+		0, // empty tagged fields
+	}
+)
+
+func TestDescribeClusterResponse(t *testing.T) {
+	response := &DescribeClusterResponse{
+		Version:      0,
+		ClusterID:    "c1",
+		ControllerID: 1,
+		Brokers:      []*Broker{{id: 1, addr: "h:9092"}},
+	}
+	testResponse(t, "V0", response, describeClusterResponseV0)
+
+	response = &DescribeClusterResponse{
+		Version:      1,
+		EndpointType: DescribeClusterEndpointBrokers,
+		ClusterID:    "c1",
+		ControllerID: -1,
+		Brokers:      []*Broker{},
+	}
+	testResponse(t, "V1", response, describeClusterResponseV1)
+}
@@ -0,0 +1,137 @@
+package sarama
+
+// clusterMetadataTopic is the name of the internal topic holding the KRaft
+// metadata log, whose single partition is replicated by the controller quorum.
+const clusterMetadataTopic = "__cluster_metadata"
+
+// DescribeQuorumRequestTopic contains a topic to describe the quorum of.
+type DescribeQuorumRequestTopic struct {
+	// TopicName contains the topic name.
+	TopicName string
+	// Partitions contains the partition indexes.
+	Partitions []int32
+}
+
+func (t *DescribeQuorumRequestTopic) encode(pe packetEncoder) error {
+	if err := pe.putString(t.TopicName); err != nil {
+		return err
+	}
+	if err := pe.putArrayLength(len(t.Partitions)); err != nil {
+		return err
+	}
+	for _, partition := range t.Partitions {
+		pe.putInt32(partition)
+		pe.putEmptyTaggedFieldArray()
+	}
+	pe.putEmptyTaggedFieldArray()
+	return nil
+}
+
+func (t *DescribeQuorumRequestTopic) decode(pd packetDecoder) (err error) {
+	if t.TopicName, err = pd.getString(); err != nil {
+		return err
+	}
+	n, err := pd.getArrayLength()
+	if err != nil {
+		return err
+	}
+	t.Partitions = make([]int32, n)
+	for i := 0; i < n; i++ {
+		if t.Partitions[i], err = pd.getInt32(); err != nil {
+			return err
+		}
+		if _, err = pd.getEmptyTaggedFieldArray(); err != nil {
+			return err
+		}
+	}
+	_, err = pd.getEmptyTaggedFieldArray()
+	return err
+}
+
+// DescribeQuorumRequest is a request to describe the state of a KRaft quorum (KIP-595, KIP-836).
+type DescribeQuorumRequest struct {
+	// Version defines the protocol version to use for encode and decode
+	Version int16
+	// Topics contains the topics to describe.
+	Topics []DescribeQuorumRequestTopic
+}
+
+// NewDescribeQuorumRequest returns a request describing the KRaft metadata
+// quorum, i.e. partition 0 of the __cluster_metadata topic.
+func NewDescribeQuorumRequest(version KafkaVersion) *DescribeQuorumRequest {
+	r := &DescribeQuorumRequest{
+		Topics: []DescribeQuorumRequestTopic{
+			{TopicName: clusterMetadataTopic, Partitions: []int32{0}},
+		},
+	}
+	if version.IsAtLeast(V3_3_0_0) {
+		r.Version = 1
+	}
+	return r
+}
+
+func (r *DescribeQuorumRequest) setVersion(v int16) {
+	r.Version = v
+}
+
+func (r *DescribeQuorumRequest) encode(pe packetEncoder) error {
+	if err := pe.putArrayLength(len(r.Topics)); err != nil {
+		return err
+	}
+	for i := range r.Topics {
+		if err := r.Topics[i].encode(pe); err != nil {
+			return err
+		}
+	}
+	pe.putEmptyTaggedFieldArray()
+	return nil
+}
+
+func (r *DescribeQuorumRequest) decode(pd packetDecoder, version int16) error {
+	r.Version = version
+	n, err := pd.getArrayLength()
+	if err != nil {
+		return err
+	}
+	r.Topics = make([]DescribeQuorumRequestTopic, n)
+	for i := 0; i < n; i++ {
+		if err := r.Topics[i].decode(pd); err != nil {
+			return err
+		}
+	}
+	_, err = pd.getEmptyTaggedFieldArray()
+	return err
+}
+
+func (r *DescribeQuorumRequest) key() int16 {
+	return apiKeyDescribeQuorum
+}
+
+func (r *DescribeQuorumRequest) version() int16 {
+	return r.Version
+}
+
+func (r *DescribeQuorumRequest) headerVersion() int16 {
+	return 2
+}
+
+func (r *DescribeQuorumRequest) isValidVersion() bool {
+	return r.Version >= 0 && r.Version <= 1
+}
+
+func (r *DescribeQuorumRequest) isFlexible() bool {
+	return r.isFlexibleVersion(r.Version)
+}
+
+func (r *DescribeQuorumRequest) isFlexibleVersion(version int16) bool {
+	return version >= 0
+}
+
+func (r *DescribeQuorumRequest) requiredVersion() KafkaVersion {
+	switch r.Version {
+	case 1:
+		return V3_3_0_0
+	default:
+		return V2_8_0_0
+	}
+}
@@ -0,0 +1,26 @@
+//go:build !functional
+
+package sarama
+
+import "testing"
+
+var describeQuorumRequestV0 = []byte{
+	2, // Topics array, length 1
+	19, '_', '_', 'c', 'l', 'u', 's', 't', 'e', 'r', '_', 'm', 'e', 't', 'a', 'd', 'a', 't', 'a',
+	2,          // Partitions array, length 1
+	0, 0, 0, 0, // PartitionIndex
+	0, // empty tagged fields
+	0, // empty tagged fields
+	0, // empty tagged fields
+}
+
+func TestDescribeQuorumRequest(t *testing.T) {
+	request := NewDescribeQuorumRequest(V2_8_0_0)
+	testRequest(t, "V0", request, describeQuorumRequestV0)
+
+	request = NewDescribeQuorumRequest(V3_3_0_0)
+	if request.Version != 1 {
+		t.Errorf("Expected version 1 for Kafka 3.3.0, got %d", request.Version)
+	}
+	testRequest(t, "V1", request, describeQuorumRequestV0)
+}
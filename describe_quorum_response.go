@@ -0,0 +1,314 @@
+package sarama
+
+// DescribeQuorumReplicaState contains the replication state of a single
+// member of the quorum.
+type DescribeQuorumReplicaState struct {
+	// ReplicaID contains the ID of the replica.
+	ReplicaID int32
+	// LogEndOffset contains the last known log end offset of the follower or -1 if it is unknown.
+	LogEndOffset int64
+	// LastFetchTimestamp contains the last known leader wall clock time time
+	// when a follower fetched from the leader, or -1 if unknown (v1+).
+	LastFetchTimestamp int64
+	// LastCaughtUpTimestamp contains the leader wall clock append time of the
+	// offset for which the follower made the most recent fetch request, or -1 if
+	// unknown (v1+).
+	LastCaughtUpTimestamp int64
+}
+
+func (s *DescribeQuorumReplicaState) encode(pe packetEncoder, version int16) error {
+	pe.putInt32(s.ReplicaID)
+	pe.putInt64(s.LogEndOffset)
+	if version >= 1 {
+		pe.putInt64(s.LastFetchTimestamp)
+		pe.putInt64(s.LastCaughtUpTimestamp)
+	}
+	pe.putEmptyTaggedFieldArray()
+	return nil
+}
+
+func (s *DescribeQuorumReplicaState) decode(pd packetDecoder, version int16) (err error) {
+	if s.ReplicaID, err = pd.getInt32(); err != nil {
+		return err
+	}
+	if s.LogEndOffset, err = pd.getInt64(); err != nil {
+		return err
+	}
+	s.LastFetchTimestamp, s.LastCaughtUpTimestamp = -1, -1
+	if version >= 1 {
+		if s.LastFetchTimestamp, err = pd.getInt64(); err != nil {
+			return err
+		}
+		if s.LastCaughtUpTimestamp, err = pd.getInt64(); err != nil {
+			return err
+		}
+	}
+	_, err = pd.getEmptyTaggedFieldArray()
+	return err
+}
+
+// DescribeQuorumPartitionData contains the quorum state of a single partition.
+type DescribeQuorumPartitionData struct {
+	// PartitionIndex contains the partition index.
+	PartitionIndex int32
+	// ErrorCode contains the partition error code.
+	ErrorCode KError
+	// LeaderID contains the ID of the current leader or -1 if the leader is unknown.
+	LeaderID int32
+	// LeaderEpoch contains the latest known leader epoch
+	LeaderEpoch int32
+	// HighWatermark contains the high water mark of the partition.
+	HighWatermark int64
+	// CurrentVoters contains the replication state of the voters.
+	CurrentVoters []DescribeQuorumReplicaState
+	// Observers contains the replication state of the observers.
+	Observers []DescribeQuorumReplicaState
+}
+
+func (p *DescribeQuorumPartitionData) encode(pe packetEncoder, version int16) error {
+	pe.putInt32(p.PartitionIndex)
+	pe.putKError(p.ErrorCode)
+	pe.putInt32(p.LeaderID)
+	pe.putInt32(p.LeaderEpoch)
+	pe.putInt64(p.HighWatermark)
+	for _, replicas := range [][]DescribeQuorumReplicaState{p.CurrentVoters, p.Observers} {
+		if err := pe.putArrayLength(len(replicas)); err != nil {
+			return err
+		}
+		for i := range replicas {
+			if err := replicas[i].encode(pe, version); err != nil {
+				return err
+			}
+		}
+	}
+	pe.putEmptyTaggedFieldArray()
+	return nil
+}
+
+func (p *DescribeQuorumPartitionData) decode(pd packetDecoder, version int16) (err error) {
+	if p.PartitionIndex, err = pd.getInt32(); err != nil {
+		return err
+	}
+	if p.ErrorCode, err = pd.getKError(); err != nil {
+		return err
+	}
+	if p.LeaderID, err = pd.getInt32(); err != nil {
+		return err
+	}
+	if p.LeaderEpoch, err = pd.getInt32(); err != nil {
+		return err
+	}
+	if p.HighWatermark, err = pd.getInt64(); err != nil {
+		return err
+	}
+	if p.CurrentVoters, err = decodeQuorumReplicaStates(pd, version); err != nil {
+		return err
+	}
+	if p.Observers, err = decodeQuorumReplicaStates(pd, version); err != nil {
+		return err
+	}
+	_, err = pd.getEmptyTaggedFieldArray()
+	return err
+}
+
+func decodeQuorumReplicaStates(pd packetDecoder, version int16) ([]DescribeQuorumReplicaState, error) {
+	n, err := pd.getArrayLength()
+	if err != nil {
+		return nil, err
+	}
+	replicas := make([]DescribeQuorumReplicaState, n)
+	for i := 0; i < n; i++ {
+		if err := replicas[i].decode(pd, version); err != nil {
+			return nil, err
+		}
+	}
+	return replicas, nil
+}
+
+// DescribeQuorumTopicData contains the quorum state of the partitions of a topic.
+type DescribeQuorumTopicData struct {
+	// TopicName contains the topic name.
+	TopicName string
+	// Partitions contains the quorum state of each partition.
+	Partitions []DescribeQuorumPartitionData
+}
+
+func (t *DescribeQuorumTopicData) encode(pe packetEncoder, version int16) error {
+	if err := pe.putString(t.TopicName); err != nil {
+		return err
+	}
+	if err := pe.putArrayLength(len(t.Partitions)); err != nil {
+		return err
+	}
+	for i := range t.Partitions {
+		if err := t.Partitions[i].encode(pe, version); err != nil {
+			return err
+		}
+	}
+	pe.putEmptyTaggedFieldArray()
+	return nil
+}
+
+func (t *DescribeQuorumTopicData) decode(pd packetDecoder, version int16) (err error) {
+	if t.TopicName, err = pd.getString(); err != nil {
+		return err
+	}
+	n, err := pd.getArrayLength()
+	if err != nil {
+		return err
+	}
+	t.Partitions = make([]DescribeQuorumPartitionData, n)
+	for i := 0; i < n; i++ {
+		if err := t.Partitions[i].decode(pd, version); err != nil {
+			return err
+		}
+	}
+	_, err = pd.getEmptyTaggedFieldArray()
+	return err
+}
+
+type DescribeQuorumResponse struct {
+	// Version defines the protocol version to use for encode and decode
+	Version int16
+	// ErrorCode contains the top level error code.
+	ErrorCode KError
+	// Topics contains the quorum state of each requested topic.
+	Topics []DescribeQuorumTopicData
+}
+
+func (r *DescribeQuorumResponse) setVersion(v int16) {
+	r.Version = v
+}
+
+func (r *DescribeQuorumResponse) encode(pe packetEncoder) error {
+	pe.putKError(r.ErrorCode)
+	if err := pe.putArrayLength(len(r.Topics)); err != nil {
+		return err
+	}
+	for i := range r.Topics {
+		if err := r.Topics[i].encode(pe, r.Version); err != nil {
+			return err
+		}
+	}
+	pe.putEmptyTaggedFieldArray()
+	return nil
+}
+
+func (r *DescribeQuorumResponse) decode(pd packetDecoder, version int16) (err error) {
+	r.Version = version
+	if r.ErrorCode, err = pd.getKError(); err != nil {
+		return err
+	}
+	n, err := pd.getArrayLength()
+	if err != nil {
+		return err
+	}
+	r.Topics = make([]DescribeQuorumTopicData, n)
+	for i := 0; i < n; i++ {
+		if err := r.Topics[i].decode(pd, version); err != nil {
+			return err
+		}
+	}
+	_, err = pd.getEmptyTaggedFieldArray()
+	return err
+}
+
+func (r *DescribeQuorumResponse) key() int16 {
+	return apiKeyDescribeQuorum
+}
+
+func (r *DescribeQuorumResponse) version() int16 {
+	return r.Version
+}
+
+func (r *DescribeQuorumResponse) headerVersion() int16 {
+	return 1
+}
+
+func (r *DescribeQuorumResponse) isValidVersion() bool {
+	return r.Version >= 0 && r.Version <= 1
+}
+
+func (r *DescribeQuorumResponse) isFlexible() bool {
+	return r.isFlexibleVersion(r.Version)
+}
+
+func (r *DescribeQuorumResponse) isFlexibleVersion(version int16) bool {
+	return version >= 0
+}
+
+func (r *DescribeQuorumResponse) requiredVersion() KafkaVersion {
+	switch r.Version {
+	case 1:
+		return V3_3_0_0
+	default:
+		return V2_8_0_0
+	}
+}
+
+// QuorumReplicaInfo contains the replication state of a member of the KRaft quorum.
+type QuorumReplicaInfo struct {
+	// ReplicaID is the node ID of the replica.
+	ReplicaID int32
+	// LogEndOffset is the last known log end offset of the replica, or -1 if unknown.
+	LogEndOffset int64
+	// Lag is the number of records the replica is behind the leader's log end
+	// offset, or -1 if unknown.
+	Lag int64
+	// LastFetchTimestamp is the leader wall clock time in milliseconds of the
+	// last fetch from this replica, or -1 if unknown.
+	LastFetchTimestamp int64
+	// LastCaughtUpTimestamp is the leader wall clock time in milliseconds at
+	// which this replica was last caught up, or -1 if unknown.
+	LastCaughtUpTimestamp int64
+}
+
+// QuorumInfo describes the state of the KRaft metadata quorum.
+type QuorumInfo struct {
+	// LeaderID is the node ID of the active controller, or -1 if unknown.
+	LeaderID int32
+	// LeaderEpoch is the latest known leader epoch.
+	LeaderEpoch int32
+	// HighWatermark is the high watermark of the metadata log.
+	HighWatermark int64
+	// Voters contains the replication state of the voting controllers.
+	Voters []QuorumReplicaInfo
+	// Observers contains the replication state of the observers, such as brokers.
+	Observers []QuorumReplicaInfo
+}
+
+func newQuorumInfo(p *DescribeQuorumPartitionData) *QuorumInfo {
+	leaderEndOffset := int64(-1)
+	for _, voter := range p.CurrentVoters {
+		if voter.ReplicaID == p.LeaderID {
+			leaderEndOffset = voter.LogEndOffset
+			break
+		}
+	}
+
+	replicaInfos := func(states []DescribeQuorumReplicaState) []QuorumReplicaInfo {
+		infos := make([]QuorumReplicaInfo, len(states))
+		for i, state := range states {
+			lag := int64(-1)
+			if leaderEndOffset >= 0 && state.LogEndOffset >= 0 {
+				lag = max(leaderEndOffset-state.LogEndOffset, 0)
+			}
+			infos[i] = QuorumReplicaInfo{
+				ReplicaID:             state.ReplicaID,
+				LogEndOffset:          state.LogEndOffset,
+				Lag:                   lag,
+				LastFetchTimestamp:    state.LastFetchTimestamp,
+				LastCaughtUpTimestamp: state.LastCaughtUpTimestamp,
+			}
+		}
+		return infos
+	}
+
+	return &QuorumInfo{
+		LeaderID:      p.LeaderID,
+		LeaderEpoch:   p.LeaderEpoch,
+		HighWatermark: p.HighWatermark,
+		Voters:        replicaInfos(p.CurrentVoters),
+		Observers:     replicaInfos(p.Observers),
+	}
+}
@@ -0,0 +1,102 @@
+//go:build !functional
+
+package sarama
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	describeQuorumResponseV0 = []byte{
+		0, 0, // ErrorCode
+		2, // Topics array, length 1
+		19, '_', '_', 'c', 'l', 'u', 's', 't', 'e', 'r', '_', 'm', 'e', 't', 'a', 'd', 'a', 't', 'a',
+		2,          // Partitions array, length 1
+		0, 0, 0, 0, // PartitionIndex
+		0, 0, // ErrorCode
+		0, 0, 0, 1, // LeaderId
+		0, 0, 0, 5, // LeaderEpoch
+		0, 0, 0, 0, 0, 0, 0, 100, // HighWatermark
+		2,          // CurrentVoters array, length 1
+		0, 0, 0, 1, // ReplicaId
+		0, 0, 0, 0, 0, 0, 0, 100, // LogEndOffset
+		0, // empty tagged fields
+		1, // Observers array, length 0
+		0, // empty tagged fields
+		0, // empty tagged fields
+		0, // empty tagged fields
+	}
+
+	describeQuorumResponseV1 = []byte{
+		0, 0, // ErrorCode
+		2, // Topics array, length 1
+		19, '_', '_', 'c', 'l', 'u', 's', 't', 'e', 'r', '_', 'm', 'e', 't', 'a', 'd', 'a', 't', 'a',
+		2,          // Partitions array, length 1
+		0, 0, 0, 0, // PartitionIndex
+		0, 0, // ErrorCode
+		0, 0, 0, 1, // LeaderId
+		0, 0, 0, 5, // LeaderEpoch
+		0, 0, 0, 0, 0, 0, 0, 100, // HighWatermark
+		2,          // CurrentVoters array, length 1
+		0, 0, 0, 1, // ReplicaId
+		0, 0, 0, 0, 0, 0, 0, 100, // LogEndOffset
+		0, 0, 0, 0, 0, 0, 0, 10, // LastFetchTimestamp
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, // LastCaughtUpTimestamp
+		0, // empty tagged fields
+		1, // Observers array, length 0
+		0, // empty tagged fields
+		0, // empty tagged fields
+		0, // empty tagged fields
+	}
+)
+
+func TestDescribeQuorumResponse(t *testing.T) {
+	response := &DescribeQuorumResponse{
+		Version: 0,
+		Topics: []DescribeQuorumTopicData{{
+			TopicName: clusterMetadataTopic,
+			Partitions: []DescribeQuorumPartitionData{{
+				LeaderID:      1,
+				LeaderEpoch:   5,
+				HighWatermark: 100,
+				CurrentVoters: []DescribeQuorumReplicaState{
+					{ReplicaID: 1, LogEndOffset: 100, LastFetchTimestamp: -1, LastCaughtUpTimestamp: -1},
+				},
+				Observers: []DescribeQuorumReplicaState{},
+			}},
+		}},
+	}
+	testResponse(t, "V0", response, describeQuorumResponseV0)
+
+	response.Version = 1
+	response.Topics[0].Partitions[0].CurrentVoters[0].LastFetchTimestamp = 10
+	testResponse(t, "V1", response, describeQuorumResponseV1)
+}
+
+func TestNewQuorumInfo(t *testing.T) {
+	info := newQuorumInfo(&DescribeQuorumPartitionData{
+		LeaderID:      2,
+		LeaderEpoch:   7,
+		HighWatermark: 90,
+		CurrentVoters: []DescribeQuorumReplicaState{
+			{ReplicaID: 1, LogEndOffset: 80},
+			{ReplicaID: 2, LogEndOffset: 100},
+			{ReplicaID: 3, LogEndOffset: -1},
+		},
+		Observers: []DescribeQuorumReplicaState{
+			{ReplicaID: 4, LogEndOffset: 95},
+		},
+	})
+
+	require.Equal(t, int32(2), info.LeaderID)
+	require.Equal(t, int32(7), info.LeaderEpoch)
+	require.Equal(t, int64(90), info.HighWatermark)
+	require.Len(t, info.Voters, 3)
+	require.Equal(t, int64(20), info.Voters[0].Lag)
+	require.Equal(t, int64(0), info.Voters[1].Lag)
+	require.Equal(t, int64(-1), info.Voters[2].Lag)
+	require.Len(t, info.Observers, 1)
+	require.Equal(t, int64(5), info.Observers[0].Lag)
+}
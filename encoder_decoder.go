@@ -2,6 +2,7 @@ package sarama
 
 import (
 	"fmt"
+	"slices"
 
 	"github.com/rcrowley/go-metrics"
 )
@@ -45,6 +46,44 @@ func encode(e encoder, metricRegistry metrics.Registry) ([]byte, error) {
 	return realEnc.raw, nil
 }
 
+// putTaggedFields writes the given tagged fields in ascending tag order. Each
+// value is serialized with the flexible encoding rules and prefixed by its tag
+// and length, so it can be skipped by decoders that don't know about it.
+func putTaggedFields(pe packetEncoder, fields taggedFieldEncoders) error {
+	tags := make([]uint64, 0, len(fields))
+	for tag := range fields {
+		tags = append(tags, tag)
+	}
+	slices.Sort(tags)
+
+	pe.putUVarint(uint64(len(tags)))
+	for _, tag := range tags {
+		value, err := encodeTaggedFieldValue(fields[tag])
+		if err != nil {
+			return err
+		}
+		pe.putUVarint(tag)
+		pe.putUVarint(uint64(len(value)))
+		if err := pe.putRawBytes(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func encodeTaggedFieldValue(fn taggedFieldEncoderFunc) ([]byte, error) {
+	prepEnc := &prepFlexibleEncoder{&prepEncoder{}}
+	if err := fn(prepEnc); err != nil {
+		return nil, err
+	}
+
+	realEnc := &realFlexibleEncoder{&realEncoder{raw: make([]byte, prepEnc.length)}}
+	if err := fn(realEnc); err != nil {
+		return nil, err
+	}
+	return realEnc.raw, nil
+}
+
 // decoder is the interface that wraps the basic Decode method.
 // Anything implementing Decoder can be extracted from bytes using Kafka's encoding rules.
 type decoder interface {
@@ -0,0 +1,405 @@
+package sarama
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrPayloadDecryption is returned, wrapped in a ConsumerError, when a
+// consumed message cannot be decrypted.
+var ErrPayloadDecryption = errors.New("kafka: failed to decrypt message payload")
+
+// Headers recording the key metadata of messages encrypted by
+// EnvelopeEncryptor.
+const (
+	// EncryptionHeaderKeyID contains the ID of the KMS key that wrapped the
+	// data key.
+	EncryptionHeaderKeyID = "sarama.encryption.key_id"
+	// EncryptionHeaderDataKey contains the wrapped data key.
+	EncryptionHeaderDataKey = "sarama.encryption.data_key"
+	// EncryptionHeaderHeaders contains the comma separated keys of the
+	// encrypted headers.
+	EncryptionHeaderHeaders = "sarama.encryption.headers"
+)
+
+// MessageEncryptor encrypts messages before they are produced, after the
+// producer interceptors have been applied. A message failing to be encrypted
+// is returned on the Errors channel of the producer instead of being sent.
+type MessageEncryptor interface {
+	Encrypt(msg *ProducerMessage) error
+}
+
+// MessageDecryptor decrypts consumed messages before they are passed to the
+// consumer interceptors. A message failing to be decrypted is not delivered,
+// instead the error is returned as a ConsumerError wrapping
+// ErrPayloadDecryption.
+type MessageDecryptor interface {
+	Decrypt(msg *ConsumerMessage) error
+}
+
+// KMS wraps and unwraps data keys with key encryption keys that never leave
+// the key management system.
+type KMS interface {
+	// WrapKey encrypts dataKey with the key encryption key keyID.
+	WrapKey(keyID string, dataKey []byte) ([]byte, error)
+	// UnwrapKey decrypts a data key previously wrapped with keyID.
+	UnwrapKey(keyID string, wrappedKey []byte) ([]byte, error)
+}
+
+// EnvelopeEncryptionConfig configures an EnvelopeEncryptor.
+type EnvelopeEncryptionConfig struct {
+	// KMS wraps the data keys.
+	KMS KMS
+	// TopicKeys maps topics to the ID of the KMS key wrapping their data
+	// keys. Messages of other topics use DefaultKeyID, or are not encrypted
+	// if it is empty.
+	TopicKeys    map[string]string
+	DefaultKeyID string
+	// Headers contains the keys of the headers to encrypt along with the
+	// value. Message keys are never encrypted, as they are used for
+	// partitioning and compaction.
+	Headers []string
+	// DataKeyLifetime is how long the data key of a topic is used before a
+	// new one is generated (defaults to 1 hour).
+	DataKeyLifetime time.Duration
+	// RejectPlaintext makes Decrypt fail for messages that are not encrypted
+	// although their topic has a key.
+	RejectPlaintext bool
+}
+
+// EnvelopeEncryptor is a MessageEncryptor and MessageDecryptor using
+// envelope encryption: the value and selected headers of each message are
+// encrypted with AES-256-GCM using a per-topic data key, and the data key,
+// wrapped by the KMS, is recorded in the message headers. The ciphertexts are
+// bound to the topic and key of their message, so they fail to decrypt if
+// copied to another message. Assign it to both
+// Producer.Encryptor and Consumer.Decryptor.
+type EnvelopeEncryptor struct {
+	conf    EnvelopeEncryptionConfig
+	headers map[string]bool
+
+	lock       sync.Mutex
+	dataKeys   map[string]*envelopeDataKey // by topic
+	unwrapped  map[string][]byte           // by key ID and wrapped key
+	maxUnwraps int
+}
+
+type envelopeDataKey struct {
+	keyID   string
+	aead    cipher.AEAD
+	wrapped []byte
+	expires time.Time
+}
+
+// NewEnvelopeEncryptor creates an EnvelopeEncryptor from conf.
+func NewEnvelopeEncryptor(conf EnvelopeEncryptionConfig) (*EnvelopeEncryptor, error) {
+	if conf.KMS == nil {
+		return nil, ConfigurationError("EnvelopeEncryptionConfig.KMS must not be nil")
+	}
+	if conf.DataKeyLifetime <= 0 {
+		conf.DataKeyLifetime = time.Hour
+	}
+	headers := make(map[string]bool, len(conf.Headers))
+	for _, key := range conf.Headers {
+		if key == "" || strings.Contains(key, ",") || strings.HasPrefix(key, "sarama.encryption.") {
+			return nil, ConfigurationError(fmt.Sprintf("invalid header key to encrypt %q", key))
+		}
+		headers[key] = true
+	}
+	return &EnvelopeEncryptor{
+		conf:       conf,
+		headers:    headers,
+		dataKeys:   make(map[string]*envelopeDataKey),
+		unwrapped:  make(map[string][]byte),
+		maxUnwraps: 1024,
+	}, nil
+}
+
+func (e *EnvelopeEncryptor) keyID(topic string) string {
+	if keyID, ok := e.conf.TopicKeys[topic]; ok {
+		return keyID
+	}
+	return e.conf.DefaultKeyID
+}
+
+// Encrypt encrypts the value and the configured headers of msg in place.
+// Messages of topics without a key and tombstones are left untouched.
+func (e *EnvelopeEncryptor) Encrypt(msg *ProducerMessage) error {
+	keyID := e.keyID(msg.Topic)
+	if keyID == "" || msg.Value == nil {
+		return nil
+	}
+	for _, h := range msg.Headers {
+		if string(h.Key) == EncryptionHeaderDataKey {
+			return errors.New("message is already encrypted")
+		}
+	}
+
+	dataKey, err := e.dataKey(msg.Topic, keyID)
+	if err != nil {
+		return err
+	}
+	aead := dataKey.aead
+
+	var key []byte
+	if msg.Key != nil {
+		if key, err = msg.Key.Encode(); err != nil {
+			return err
+		}
+	}
+	value, err := msg.Value.Encode()
+	if err != nil {
+		return err
+	}
+	if value, err = sealPayload(aead, value, associatedData(msg.Topic, key, []byte("value"))); err != nil {
+		return err
+	}
+
+	headers := make([]RecordHeader, 0, len(msg.Headers)+3)
+	var encrypted []string
+	for _, h := range msg.Headers {
+		if e.headers[string(h.Key)] {
+			v, err := sealPayload(aead, h.Value, associatedData(msg.Topic, key, h.Key))
+			if err != nil {
+				return err
+			}
+			h.Value = v
+			encrypted = append(encrypted, string(h.Key))
+		}
+		headers = append(headers, h)
+	}
+	headers = append(headers,
+		RecordHeader{Key: []byte(EncryptionHeaderKeyID), Value: []byte(dataKey.keyID)},
+		RecordHeader{Key: []byte(EncryptionHeaderDataKey), Value: dataKey.wrapped},
+	)
+	if len(encrypted) > 0 {
+		headers = append(headers, RecordHeader{Key: []byte(EncryptionHeaderHeaders), Value: []byte(strings.Join(encrypted, ","))})
+	}
+
+	msg.Value = ByteEncoder(value)
+	msg.Headers = headers
+	return nil
+}
+
+// dataKey returns the current data key of topic, generating and wrapping a
+// new one if there is none or it expired.
+func (e *EnvelopeEncryptor) dataKey(topic, keyID string) (*envelopeDataKey, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if dk, ok := e.dataKeys[topic]; ok && dk.keyID == keyID && time.Now().Before(dk.expires) {
+		return dk, nil
+	}
+
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	wrapped, err := e.conf.KMS.WrapKey(keyID, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to wrap data key with %s: %w", keyID, err)
+	}
+	dk := &envelopeDataKey{keyID: keyID, aead: aead, wrapped: wrapped, expires: time.Now().Add(e.conf.DataKeyLifetime)}
+	e.dataKeys[topic] = dk
+	return dk, nil
+}
+
+// Decrypt decrypts the value and headers of msg in place and removes the
+// encryption headers. Messages without encryption headers are left
+// untouched, unless RejectPlaintext is set and their topic has a key.
+func (e *EnvelopeEncryptor) Decrypt(msg *ConsumerMessage) error {
+	var keyID, wrapped, encryptedHeaders []byte
+	headers := make([]*RecordHeader, 0, len(msg.Headers))
+	for _, h := range msg.Headers {
+		switch string(h.Key) {
+		case EncryptionHeaderKeyID:
+			keyID = h.Value
+		case EncryptionHeaderDataKey:
+			wrapped = h.Value
+		case EncryptionHeaderHeaders:
+			encryptedHeaders = h.Value
+		default:
+			headers = append(headers, h)
+		}
+	}
+
+	if wrapped == nil {
+		if e.conf.RejectPlaintext && msg.Value != nil && e.keyID(msg.Topic) != "" {
+			return errors.New("message is not encrypted")
+		}
+		return nil
+	}
+	if len(keyID) == 0 {
+		return errors.New("missing " + EncryptionHeaderKeyID + " header")
+	}
+
+	key, err := e.unwrap(string(keyID), wrapped)
+	if err != nil {
+		return err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return err
+	}
+
+	value, err := openPayload(aead, msg.Value, associatedData(msg.Topic, msg.Key, []byte("value")))
+	if err != nil {
+		return err
+	}
+	if len(encryptedHeaders) > 0 {
+		encrypted := make(map[string]bool)
+		for _, k := range strings.Split(string(encryptedHeaders), ",") {
+			encrypted[k] = true
+		}
+		for i, h := range headers {
+			if !encrypted[string(h.Key)] {
+				continue
+			}
+			v, err := openPayload(aead, h.Value, associatedData(msg.Topic, msg.Key, h.Key))
+			if err != nil {
+				return fmt.Errorf("header %s: %w", h.Key, err)
+			}
+			headers[i] = &RecordHeader{Key: h.Key, Value: v}
+		}
+	}
+
+	msg.Value = value
+	msg.Headers = headers
+	return nil
+}
+
+// unwrap returns the data key wrapped with keyID, caching unwrapped keys so
+// the KMS is only called once per data key.
+func (e *EnvelopeEncryptor) unwrap(keyID string, wrapped []byte) ([]byte, error) {
+	cacheKey := keyID + "\x00" + string(wrapped)
+
+	e.lock.Lock()
+	key, ok := e.unwrapped[cacheKey]
+	e.lock.Unlock()
+	if ok {
+		return key, nil
+	}
+
+	key, err := e.conf.KMS.UnwrapKey(keyID, wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key with %s: %w", keyID, err)
+	}
+
+	e.lock.Lock()
+	if len(e.unwrapped) >= e.maxUnwraps {
+		clear(e.unwrapped)
+	}
+	e.unwrapped[cacheKey] = key
+	e.lock.Unlock()
+	return key, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// associatedData returns the additional data authenticated with the value
+// or header named field of the message with topic and key, each prefixed with
+// its length so that they cannot be told apart from other combinations.
+func associatedData(topic string, key, field []byte) []byte {
+	ad := make([]byte, 0, 3*binary.MaxVarintLen64+len(topic)+len(key)+len(field))
+	for _, part := range [][]byte{[]byte(topic), key, field} {
+		ad = binary.AppendUvarint(ad, uint64(len(part)))
+		ad = append(ad, part...)
+	}
+	return ad
+}
+
+// sealPayload returns the nonce followed by the ciphertext of plaintext.
+func sealPayload(aead cipher.AEAD, plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func openPayload(aead cipher.AEAD, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, ciphertext := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, additionalData)
+}
+
+// LocalFileKMS is a KMS using key encryption keys read from a local file,
+// intended for tests and development. Each line of the file has the form
+// `<key id>=<base64 encoded 16, 24 or 32 byte AES key>`; empty lines and
+// lines starting with # are ignored.
+type LocalFileKMS struct {
+	keys map[string]cipher.AEAD
+}
+
+// NewLocalFileKMS loads the key encryption keys from path.
+func NewLocalFileKMS(path string) (*LocalFileKMS, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	kms := &LocalFileKMS{keys: make(map[string]cipher.AEAD)}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		keyID, encoded, ok := strings.Cut(text, "=")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: expected <key id>=<base64 key>", path, line)
+		}
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		kms.keys[strings.TrimSpace(keyID)] = aead
+	}
+	return kms, scanner.Err()
+}
+
+// WrapKey implements KMS.
+func (k *LocalFileKMS) WrapKey(keyID string, dataKey []byte) ([]byte, error) {
+	aead, ok := k.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("unknown key %s", keyID)
+	}
+	return sealPayload(aead, dataKey, []byte(keyID))
+}
+
+// UnwrapKey implements KMS.
+func (k *LocalFileKMS) UnwrapKey(keyID string, wrappedKey []byte) ([]byte, error) {
+	aead, ok := k.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("unknown key %s", keyID)
+	}
+	return openPayload(aead, wrappedKey, []byte(keyID))
+}
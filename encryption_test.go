@@ -0,0 +1,300 @@
+//go:build !functional
+
+package sarama
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+type countingKMS struct {
+	KMS
+	wraps, unwraps atomic.Int32
+}
+
+func (k *countingKMS) WrapKey(keyID string, dataKey []byte) ([]byte, error) {
+	k.wraps.Add(1)
+	return k.KMS.WrapKey(keyID, dataKey)
+}
+
+func (k *countingKMS) UnwrapKey(keyID string, wrappedKey []byte) ([]byte, error) {
+	k.unwraps.Add(1)
+	return k.KMS.UnwrapKey(keyID, wrappedKey)
+}
+
+func newTestKMS(t *testing.T) *countingKMS {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "keys")
+	content := "# test keys\n" +
+		"orders=" + base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32)) + "\n" +
+		"default=" + base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{2}, 16)) + "\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	kms, err := NewLocalFileKMS(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &countingKMS{KMS: kms}
+}
+
+func newTestEnvelopeEncryptor(t *testing.T, kms KMS, rejectPlaintext bool) *EnvelopeEncryptor {
+	t.Helper()
+	encryptor, err := NewEnvelopeEncryptor(EnvelopeEncryptionConfig{
+		KMS:             kms,
+		TopicKeys:       map[string]string{"orders": "orders", "public": ""},
+		DefaultKeyID:    "default",
+		Headers:         []string{"customer"},
+		RejectPlaintext: rejectPlaintext,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return encryptor
+}
+
+// consumed converts a produced message to the message a consumer receives.
+func consumed(t *testing.T, msg *ProducerMessage) *ConsumerMessage {
+	t.Helper()
+	cm := &ConsumerMessage{Topic: msg.Topic}
+	if msg.Key != nil {
+		key, err := msg.Key.Encode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		cm.Key = key
+	}
+	if msg.Value != nil {
+		value, err := msg.Value.Encode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		cm.Value = value
+	}
+	for i := range msg.Headers {
+		cm.Headers = append(cm.Headers, &msg.Headers[i])
+	}
+	return cm
+}
+
+func TestEnvelopeEncryptorRoundTrip(t *testing.T) {
+	kms := newTestKMS(t)
+	encryptor := newTestEnvelopeEncryptor(t, kms, false)
+
+	for _, topic := range []string{"orders", "other"} {
+		msg := &ProducerMessage{
+			Topic: topic,
+			Value: StringEncoder("secret value"),
+			Headers: []RecordHeader{
+				{Key: []byte("customer"), Value: []byte("alice")},
+				{Key: []byte("trace"), Value: []byte("abc")},
+			},
+		}
+		if err := encryptor.Encrypt(msg); err != nil {
+			t.Fatal(err)
+		}
+
+		value, _ := msg.Value.Encode()
+		if bytes.Contains(value, []byte("secret value")) || bytes.Contains(value, []byte("alice")) {
+			t.Errorf("%s: payload was not encrypted", topic)
+		}
+		if len(msg.Headers) != 5 || string(msg.Headers[1].Value) != "abc" {
+			t.Errorf("%s: unexpected headers %v", topic, msg.Headers)
+		}
+
+		cm := consumed(t, msg)
+		if err := encryptor.Decrypt(cm); err != nil {
+			t.Fatal(err)
+		}
+		if string(cm.Value) != "secret value" {
+			t.Errorf("%s: unexpected value %q", topic, cm.Value)
+		}
+		if len(cm.Headers) != 2 || string(cm.Headers[0].Value) != "alice" || string(cm.Headers[1].Value) != "abc" {
+			t.Errorf("%s: unexpected headers %v", topic, cm.Headers)
+		}
+	}
+
+	// messages of the same topic share a data key, which is unwrapped once
+	for range 3 {
+		msg := &ProducerMessage{Topic: "orders", Value: StringEncoder("v")}
+		if err := encryptor.Encrypt(msg); err != nil {
+			t.Fatal(err)
+		}
+		if err := encryptor.Decrypt(consumed(t, msg)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := kms.wraps.Load(); n != 2 {
+		t.Errorf("expected one data key per topic, got %d", n)
+	}
+	if n := kms.unwraps.Load(); n != 2 {
+		t.Errorf("expected unwrapped data keys to be cached, got %d unwraps", n)
+	}
+}
+
+func TestEnvelopeEncryptorSkipsUnencryptedTopicsAndTombstones(t *testing.T) {
+	encryptor := newTestEnvelopeEncryptor(t, newTestKMS(t), true)
+
+	for _, msg := range []*ProducerMessage{
+		{Topic: "public", Value: StringEncoder("plain")},
+		{Topic: "orders"},
+	} {
+		if err := encryptor.Encrypt(msg); err != nil {
+			t.Fatal(err)
+		}
+		if len(msg.Headers) != 0 {
+			t.Errorf("expected %s message not to be encrypted", msg.Topic)
+		}
+		if err := encryptor.Decrypt(consumed(t, msg)); err != nil {
+			t.Error(err)
+		}
+	}
+
+	if err := encryptor.Decrypt(&ConsumerMessage{Topic: "orders", Value: []byte("plain")}); err == nil {
+		t.Error("expected plaintext to be rejected")
+	}
+}
+
+func TestEnvelopeEncryptorDetectsTampering(t *testing.T) {
+	encryptor := newTestEnvelopeEncryptor(t, newTestKMS(t), false)
+
+	msg := &ProducerMessage{Topic: "orders", Value: StringEncoder("secret")}
+	if err := encryptor.Encrypt(msg); err != nil {
+		t.Fatal(err)
+	}
+	cm := consumed(t, msg)
+	cm.Value[len(cm.Value)-1] ^= 0xff
+	if err := encryptor.Decrypt(cm); err == nil {
+		t.Error("expected tampered value to fail decryption")
+	}
+
+	cm = consumed(t, msg)
+	for _, h := range cm.Headers {
+		if string(h.Key) == EncryptionHeaderKeyID {
+			h.Value = []byte("default")
+		}
+	}
+	if err := encryptor.Decrypt(cm); err == nil {
+		t.Error("expected data key wrapped by another key to fail decryption")
+	}
+}
+
+func TestEnvelopeEncryptorBindsPayloadToMessage(t *testing.T) {
+	encryptor := newTestEnvelopeEncryptor(t, newTestKMS(t), false)
+
+	encrypt := func(topic, key string) *ConsumerMessage {
+		msg := &ProducerMessage{
+			Topic:   topic,
+			Key:     StringEncoder(key),
+			Value:   StringEncoder("secret of " + key),
+			Headers: []RecordHeader{{Key: []byte("customer"), Value: []byte(key)}},
+		}
+		if err := encryptor.Encrypt(msg); err != nil {
+			t.Fatal(err)
+		}
+		return consumed(t, msg)
+	}
+	alice := encrypt("orders", "alice")
+	if err := encryptor.Decrypt(encrypt("orders", "alice")); err != nil {
+		t.Fatal(err)
+	}
+
+	// the encrypted payload and headers of alice copied to another record key
+	bob := encrypt("orders", "bob")
+	bob.Value, bob.Headers = alice.Value, alice.Headers
+	if err := encryptor.Decrypt(bob); err == nil {
+		t.Error("expected a value copied to another record key to fail decryption")
+	}
+
+	// or to another topic
+	copied := &ConsumerMessage{Topic: "other", Key: alice.Key, Value: alice.Value, Headers: alice.Headers}
+	if err := encryptor.Decrypt(copied); err == nil {
+		t.Error("expected a value copied to another topic to fail decryption")
+	}
+}
+
+func TestAsyncProducerEncryptionError(t *testing.T) {
+	seedBroker := NewMockBroker(t, 1)
+	defer seedBroker.Close()
+	metadata := new(MetadataResponse)
+	metadata.AddBroker(seedBroker.Addr(), seedBroker.BrokerID())
+	metadata.AddTopicPartition("orders", 0, seedBroker.BrokerID(), nil, nil, nil, ErrNoError)
+	seedBroker.Returns(metadata)
+
+	encryptor, err := NewEnvelopeEncryptor(EnvelopeEncryptionConfig{KMS: newTestKMS(t), DefaultKeyID: "unknown"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := NewTestConfig()
+	config.Producer.Encryptor = encryptor
+	producer, err := NewAsyncProducer([]string{seedBroker.Addr()}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	producer.Input() <- &ProducerMessage{Topic: "orders", Value: StringEncoder("secret")}
+	pErr := <-producer.Errors()
+	if pErr.Err == nil || pErr.Msg.Topic != "orders" {
+		t.Errorf("expected an encryption error, got %v", pErr)
+	}
+	closeProducer(t, producer)
+}
+
+func TestConsumerDecryptionError(t *testing.T) {
+	encryptor := newTestEnvelopeEncryptor(t, newTestKMS(t), true)
+	encrypted := &ProducerMessage{Topic: "orders", Value: StringEncoder("secret")}
+	if err := encryptor.Encrypt(encrypted); err != nil {
+		t.Fatal(err)
+	}
+	value, _ := encrypted.Value.Encode()
+
+	fetchResponse := &FetchResponse{Version: 5}
+	fetchResponse.AddRecord("orders", 0, nil, StringEncoder("plain"), 1)
+	fetchResponse.AddRecord("orders", 0, nil, ByteEncoder(value), 2)
+	batch := fetchResponse.GetBlock("orders", 0).RecordsSet[0].RecordBatch
+	for i := range encrypted.Headers {
+		batch.Records[1].Headers = append(batch.Records[1].Headers, &encrypted.Headers[i])
+	}
+
+	broker0 := NewMockBroker(t, 0)
+	defer broker0.Close()
+	broker0.SetHandlerByMap(map[string]MockResponse{
+		"MetadataRequest": NewMockMetadataResponse(t).
+			SetBroker(broker0.Addr(), broker0.BrokerID()).
+			SetLeader("orders", 0, broker0.BrokerID()),
+		"OffsetRequest": NewMockOffsetResponse(t).
+			SetOffset("orders", 0, OffsetNewest, 10).
+			SetOffset("orders", 0, OffsetOldest, 0),
+		"FetchRequest": NewMockSequence(fetchResponse, &FetchResponse{Version: 5}),
+	})
+
+	config := NewTestConfig()
+	config.Version = V0_11_0_0
+	config.Consumer.Return.Errors = true
+	config.Consumer.Decryptor = encryptor
+	master, err := NewConsumer([]string{broker0.Addr()}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer safeClose(t, master)
+
+	consumer, err := master.ConsumePartition("orders", 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer safeClose(t, consumer)
+
+	cErr := <-consumer.Errors()
+	if !errors.Is(cErr, ErrPayloadDecryption) || cErr.Topic != "orders" {
+		t.Errorf("expected a decryption error, got %v", cErr)
+	}
+	msg := <-consumer.Messages()
+	if msg.Offset != 2 || string(msg.Value) != "secret" || len(msg.Headers) != 0 {
+		t.Errorf("unexpected message %+v", msg)
+	}
+}
@@ -0,0 +1,122 @@
+package sarama
+
+import "time"
+
+// Event is a lifecycle event of a client, consumer group or of one of their
+// broker connections, notified to the configured EventListener (see
+// Config.EventListener). It is one of *BrokerConnected, *BrokerDisconnected,
+// *MetadataRefreshed, *LeaderChanged, *Throttled, *RebalanceStarted,
+// *PartitionsAssigned or *PartitionsRevoked.
+type Event interface {
+	event()
+}
+
+// EventListener is notified of the lifecycle events of the clients and
+// consumer groups using its configuration.
+//
+// OnEvent is called synchronously from the goroutine the event happens in,
+// possibly while holding internal locks, so it must not block nor call back
+// into the client: hand the event off to a channel or goroutine instead.
+type EventListener interface {
+	OnEvent(event Event)
+}
+
+// EventListenerFunc is an adapter allowing the use of a function as an
+// EventListener, e.g. to send the events to a buffered channel:
+//
+//	events := make(chan sarama.Event, 256)
+//	config.EventListener = sarama.EventListenerFunc(func(event sarama.Event) {
+//		select {
+//		case events <- event:
+//		default: // drop the event rather than blocking the client
+//		}
+//	})
+type EventListenerFunc func(event Event)
+
+// OnEvent calls f(event).
+func (f EventListenerFunc) OnEvent(event Event) {
+	f(event)
+}
+
+// BrokerConnected is notified when a connection to a broker is established.
+type BrokerConnected struct {
+	// BrokerID is -1 for seed brokers, whose id is not known.
+	BrokerID int32
+	Addr     string
+}
+
+// BrokerDisconnected is notified when a connection to a broker is closed,
+// Err being the error closing it, if any.
+type BrokerDisconnected struct {
+	BrokerID int32
+	Addr     string
+	Err      error
+}
+
+// MetadataRefreshed is notified when the client has updated its metadata,
+// Topics being the topics returned by the broker.
+type MetadataRefreshed struct {
+	Topics []string
+}
+
+// LeaderChanged is notified when a metadata refresh reports a different
+// leader for a partition. Leader and PreviousLeader are -1 when the
+// partition has no leader.
+type LeaderChanged struct {
+	Topic          string
+	Partition      int32
+	Leader         int32
+	PreviousLeader int32
+	LeaderEpoch    int32
+}
+
+// Throttled is notified when a broker throttles the client, APIKey being
+// the key of the API whose response reported the throttle time.
+type Throttled struct {
+	BrokerID     int32
+	Addr         string
+	APIKey       int16
+	ThrottleTime time.Duration
+}
+
+// RebalanceStarted is notified when a consumer group starts joining the
+// group, before its partitions are assigned.
+type RebalanceStarted struct {
+	GroupID string
+	Topics  []string
+}
+
+// PartitionsAssigned is notified when a consumer group session starts with
+// the partitions assigned to the member, before ConsumerGroupHandler.Setup.
+type PartitionsAssigned struct {
+	GroupID      string
+	MemberID     string
+	GenerationID int32
+	Claims       map[string][]int32
+}
+
+// PartitionsRevoked is notified when a consumer group session releases the
+// partitions that were assigned to the member, after
+// ConsumerGroupHandler.Cleanup and the final offset commit.
+type PartitionsRevoked struct {
+	GroupID      string
+	MemberID     string
+	GenerationID int32
+	Claims       map[string][]int32
+}
+
+func (*BrokerConnected) event()    {}
+func (*BrokerDisconnected) event() {}
+func (*MetadataRefreshed) event()  {}
+func (*LeaderChanged) event()      {}
+func (*Throttled) event()          {}
+func (*RebalanceStarted) event()   {}
+func (*PartitionsAssigned) event() {}
+func (*PartitionsRevoked) event()  {}
+
+// notifyEvent notifies event to the EventListener of conf, if any.
+func notifyEvent(conf *Config, event Event) {
+	if conf != nil && conf.EventListener != nil {
+		conf.EventListener.OnEvent(event)
+	}
+}
@@ -0,0 +1,183 @@
+//go:build !functional
+
+package sarama
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+type recordingEventListener struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (l *recordingEventListener) OnEvent(event Event) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, event)
+}
+
+// of returns the recorded events of the same type as example.
+func (l *recordingEventListener) of(example Event) []Event {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var events []Event
+	for _, event := range l.events {
+		if reflect.TypeOf(event) == reflect.TypeOf(example) {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+func TestBrokerEvents(t *testing.T) {
+	mockBroker := NewMockBroker(t, 0)
+	defer mockBroker.Close()
+
+	listener := &recordingEventListener{}
+	conf := NewTestConfig()
+	conf.EventListener = listener
+	broker := NewBroker(mockBroker.Addr())
+	if err := broker.Open(conf); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := broker.Connected(); err != nil {
+		t.Fatal(err)
+	}
+	broker.handleThrottledResponse(&ProduceResponse{Version: 1, ThrottleTime: 1})
+	broker.waitIfThrottled()
+	safeClose(t, broker)
+
+	expected := []Event{
+		&BrokerConnected{BrokerID: -1, Addr: mockBroker.Addr()},
+		&Throttled{BrokerID: -1, Addr: mockBroker.Addr(), APIKey: apiKeyProduce, ThrottleTime: 1},
+		&BrokerDisconnected{BrokerID: -1, Addr: mockBroker.Addr()},
+	}
+	if !reflect.DeepEqual(listener.events, expected) {
+		t.Errorf("Expected events %v, got %v", expected, listener.events)
+	}
+}
+
+func TestClientLeaderChangedEvent(t *testing.T) {
+	seedBroker := NewMockBroker(t, 1)
+	defer seedBroker.Close()
+
+	seedBroker.SetHandlerByMap(map[string]MockResponse{
+		"MetadataRequest": NewMockSequence(
+			NewMockMetadataResponse(t).
+				SetBroker(seedBroker.Addr(), seedBroker.BrokerID()).
+				SetLeader("my_topic", 0, 1).
+				SetLeader("my_topic", 1, 1),
+			NewMockMetadataResponse(t).
+				SetBroker(seedBroker.Addr(), seedBroker.BrokerID()).
+				SetLeader("my_topic", 0, 2).
+				SetLeader("my_topic", 1, 1),
+		),
+	})
+
+	listener := &recordingEventListener{}
+	conf := NewTestConfig()
+	conf.EventListener = listener
+	client, err := NewClient([]string{seedBroker.Addr()}, conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer safeClose(t, client)
+
+	if events := listener.of(&LeaderChanged{}); len(events) != 0 {
+		t.Errorf("Expected no leader change on the first metadata refresh, got %v", events)
+	}
+	if err := client.RefreshMetadata("my_topic"); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Event{&LeaderChanged{Topic: "my_topic", Partition: 0, Leader: 2, PreviousLeader: 1}}
+	if events := listener.of(&LeaderChanged{}); !reflect.DeepEqual(events, expected) {
+		t.Errorf("Expected events %v, got %v", expected, events)
+	}
+	refreshed := listener.of(&MetadataRefreshed{})
+	if len(refreshed) != 2 || !reflect.DeepEqual(refreshed[1], &MetadataRefreshed{Topics: []string{"my_topic"}}) {
+		t.Errorf("Unexpected metadata refreshes %v", refreshed)
+	}
+	if events := listener.of(&BrokerConnected{}); len(events) == 0 {
+		t.Error("Expected broker connections to be notified")
+	}
+}
+
+func TestConsumerGroupEvents(t *testing.T) {
+	config := NewTestConfig()
+	config.ClientID = t.Name()
+	config.Version = V2_0_0_0
+	config.Consumer.Return.Errors = true
+	config.Consumer.Offsets.AutoCommit.Enable = false
+	listener := &recordingEventListener{}
+	config.EventListener = listener
+
+	broker0 := NewMockBroker(t, 0)
+	defer broker0.Close()
+
+	broker0.SetHandlerByMap(map[string]MockResponse{
+		"MetadataRequest": NewMockMetadataResponse(t).
+			SetBroker(broker0.Addr(), broker0.BrokerID()).
+			SetLeader("my-topic", 0, broker0.BrokerID()),
+		"OffsetRequest": NewMockOffsetResponse(t).
+			SetOffset("my-topic", 0, OffsetOldest, 0).
+			SetOffset("my-topic", 0, OffsetNewest, 1),
+		"FindCoordinatorRequest": NewMockFindCoordinatorResponse(t).
+			SetCoordinator(CoordinatorGroup, "my-group", broker0),
+		"HeartbeatRequest": NewMockHeartbeatResponse(t),
+		"JoinGroupRequest": NewMockJoinGroupResponse(t).
+			SetGroupProtocol(RangeBalanceStrategyName).
+			SetMemberId("my-member").
+			SetGenerationId(3),
+		"SyncGroupRequest": NewMockSyncGroupResponse(t).SetMemberAssignment(
+			&ConsumerGroupMemberAssignment{
+				Version: 0,
+				Topics: map[string][]int32{
+					"my-topic": {0},
+				},
+			}),
+		"OffsetFetchRequest": NewMockOffsetFetchResponse(t).SetOffset(
+			"my-group", "my-topic", 0, 0, "", ErrNoError,
+		).SetError(ErrNoError),
+		"FetchRequest": NewMockFetchResponse(t, 1).
+			SetMessage("my-topic", 0, 0, StringEncoder("foo")),
+		"LeaveGroupRequest": NewMockLeaveGroupResponse(t),
+	})
+
+	group, err := NewConsumerGroup([]string{broker0.Addr()}, "my-group", config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := &handler{make(chan *ConsumerMessage, 2)}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := group.Consume(context.Background(), []string{"my-topic"}, h); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	if msg := <-h.messageCh; string(msg.Value) != "foo" {
+		t.Errorf("Unexpected message %q", msg.Value)
+	}
+	if err := group.Close(); err != nil {
+		t.Error(err)
+	}
+	<-done
+
+	claims := map[string][]int32{"my-topic": {0}}
+	for _, expected := range []Event{
+		&RebalanceStarted{GroupID: "my-group", Topics: []string{"my-topic"}},
+		&PartitionsAssigned{GroupID: "my-group", MemberID: "my-member", GenerationID: 3, Claims: claims},
+		&PartitionsRevoked{GroupID: "my-group", MemberID: "my-member", GenerationID: 3, Claims: claims},
+	} {
+		if events := listener.of(expected); len(events) != 1 || !reflect.DeepEqual(events[0], expected) {
+			t.Errorf("Expected event %v, got %v", expected, events)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package sarama
+
+// MetadataVersionFeature is the name of the finalized feature that tracks the
+// metadata version of a KRaft cluster.
+const MetadataVersionFeature = "metadata.version"
+
+// FeatureUpgradeType determines how the max version level of a finalized
+// feature may be changed by an UpdateFeaturesRequest.
+type FeatureUpgradeType int8
+
+// ref: https://github.com/apache/kafka/blob/trunk/clients/src/main/java/org/apache/kafka/clients/admin/FeatureUpdate.java
+const (
+	// FeatureUpgradeTypeUnknown is not a valid upgrade type and is rejected by brokers
+	FeatureUpgradeTypeUnknown FeatureUpgradeType = 0
+	// FeatureUpgradeTypeUpgrade only allows the version level to be increased
+	FeatureUpgradeTypeUpgrade FeatureUpgradeType = 1
+	// FeatureUpgradeTypeSafeDowngrade allows a downgrade which does not lose metadata
+	FeatureUpgradeTypeSafeDowngrade FeatureUpgradeType = 2
+	// FeatureUpgradeTypeUnsafeDowngrade allows a downgrade which may lose metadata
+	FeatureUpgradeTypeUnsafeDowngrade FeatureUpgradeType = 3
+)
+
+// FeatureUpdate describes the requested change of a finalized feature. Setting
+// MaxVersionLevel to 0 deletes the finalized feature, which requires a
+// downgrade upgrade type.
+type FeatureUpdate struct {
+	MaxVersionLevel int16
+	UpgradeType     FeatureUpgradeType
+}
+
+// SupportedFeatureVersionRange is the range of versions of a feature supported by a broker.
+type SupportedFeatureVersionRange struct {
+	MinVersion int16
+	MaxVersion int16
+}
+
+// FinalizedFeatureVersionRange is the range of version levels of a feature
+// finalized across the cluster.
+type FinalizedFeatureVersionRange struct {
+	MinVersionLevel int16
+	MaxVersionLevel int16
+}
+
+// FeatureMetadata contains the features supported by a broker and the
+// cluster-wide finalized features, as advertised in ApiVersionsResponse v3+
+// (KIP-584).
+type FeatureMetadata struct {
+	// FinalizedFeatures maps feature names to their finalized version levels.
+	FinalizedFeatures map[string]FinalizedFeatureVersionRange
+	// FinalizedFeaturesEpoch is the epoch of the finalized features, or -1 if unknown.
+	FinalizedFeaturesEpoch int64
+	// SupportedFeatures maps feature names to the version range supported by the broker.
+	SupportedFeatures map[string]SupportedFeatureVersionRange
+}
+
+func newFeatureMetadata(res *ApiVersionsResponse) *FeatureMetadata {
+	fm := &FeatureMetadata{
+		FinalizedFeatures:      make(map[string]FinalizedFeatureVersionRange, len(res.FinalizedFeatures)),
+		FinalizedFeaturesEpoch: res.FinalizedFeaturesEpoch,
+		SupportedFeatures:      make(map[string]SupportedFeatureVersionRange, len(res.SupportedFeatures)),
+	}
+	for _, f := range res.FinalizedFeatures {
+		fm.FinalizedFeatures[f.Name] = FinalizedFeatureVersionRange{
+			MinVersionLevel: f.MinVersionLevel,
+			MaxVersionLevel: f.MaxVersionLevel,
+		}
+	}
+	for _, f := range res.SupportedFeatures {
+		fm.SupportedFeatures[f.Name] = SupportedFeatureVersionRange{
+			MinVersion: f.MinVersion,
+			MaxVersion: f.MaxVersion,
+		}
+	}
+	return fm
+}
@@ -136,7 +136,12 @@ func (r *FetchRequest) encode(pe packetEncoder) (err error) {
 				return err
 			}
 		}
-		getOrRegisterTopicMeter("consumer-fetch-rate", topic, metricRegistry).Mark(1)
+		fetchRateName := "consumer-fetch-rate"
+		if metricsSinkOf(metricRegistry) != nil {
+			// metrics of the same name have the same labels in a MetricsSink
+			fetchRateName = "consumer-topic-fetch-rate"
+		}
+		getOrRegisterTopicMeter(fetchRateName, topic, metricRegistry).Mark(1)
 	}
 	if r.Version >= 7 {
 		err = pe.putArrayLength(len(r.forgotten))
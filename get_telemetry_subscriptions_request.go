@@ -0,0 +1,62 @@
+package sarama
+
+// GetTelemetrySubscriptionsRequest asks a broker which client metrics it
+// wants to be pushed, see KIP-714.
+type GetTelemetrySubscriptionsRequest struct {
+	// Version defines the protocol version to use for encode and decode
+	Version int16
+	// ClientInstanceId contains the unique identifier of the client
+	// instance, or a zero UUID to have the broker assign one.
+	ClientInstanceId Uuid
+}
+
+func (r *GetTelemetrySubscriptionsRequest) setVersion(v int16) {
+	r.Version = v
+}
+
+func (r *GetTelemetrySubscriptionsRequest) encode(pe packetEncoder) error {
+	if err := pe.putRawBytes(r.ClientInstanceId[:]); err != nil {
+		return err
+	}
+	pe.putEmptyTaggedFieldArray()
+	return nil
+}
+
+func (r *GetTelemetrySubscriptionsRequest) decode(pd packetDecoder, version int16) error {
+	r.Version = version
+	id, err := pd.getRawBytes(16)
+	if err != nil {
+		return err
+	}
+	copy(r.ClientInstanceId[:], id)
+	_, err = pd.getEmptyTaggedFieldArray()
+	return err
+}
+
+func (r *GetTelemetrySubscriptionsRequest) key() int16 {
+	return apiKeyGetTelemetrySubscriptions
+}
+
+func (r *GetTelemetrySubscriptionsRequest) version() int16 {
+	return r.Version
+}
+
+func (r *GetTelemetrySubscriptionsRequest) headerVersion() int16 {
+	return 2
+}
+
+func (r *GetTelemetrySubscriptionsRequest) isValidVersion() bool {
+	return r.Version == 0
+}
+
+func (r *GetTelemetrySubscriptionsRequest) isFlexible() bool {
+	return r.isFlexibleVersion(r.Version)
+}
+
+func (r *GetTelemetrySubscriptionsRequest) isFlexibleVersion(version int16) bool {
+	return version >= 0
+}
+
+func (r *GetTelemetrySubscriptionsRequest) requiredVersion() KafkaVersion {
+	return V3_7_0_0
+}
@@ -0,0 +1,18 @@
+//go:build !functional
+
+package sarama
+
+import "testing"
+
+var getTelemetrySubscriptionsRequestV0 = []byte{
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, // ClientInstanceId
+	0, // empty tagged fields
+}
+
+func TestGetTelemetrySubscriptionsRequest(t *testing.T) {
+	request := &GetTelemetrySubscriptionsRequest{
+		Version:          0,
+		ClientInstanceId: Uuid{15: 1},
+	}
+	testRequest(t, "V0", request, getTelemetrySubscriptionsRequestV0)
+}
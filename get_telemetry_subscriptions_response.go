@@ -0,0 +1,141 @@
+package sarama
+
+import "time"
+
+// GetTelemetrySubscriptionsResponse describes the client metrics a broker
+// wants to be pushed with PushTelemetryRequest, see KIP-714.
+type GetTelemetrySubscriptionsResponse struct {
+	// Version defines the protocol version to use for encode and decode
+	Version int16
+	// ThrottleTime contains the duration for which the request was throttled
+	// due to a quota violation, or zero if the request did not violate any
+	// quota.
+	ThrottleTime time.Duration
+	// Err contains the error, or ErrNoError if there was no error.
+	Err KError
+	// ClientInstanceId contains the unique identifier of the client
+	// instance, assigned by the broker if the request did not set one.
+	ClientInstanceId Uuid
+	// SubscriptionId contains the unique identifier of the current
+	// subscription, to be set in the PushTelemetryRequests.
+	SubscriptionId int32
+	// AcceptedCompressionTypes contains the compression types the broker
+	// accepts for the pushed metrics, in order of preference.
+	AcceptedCompressionTypes []CompressionCodec
+	// PushInterval contains the interval at which the metrics should be
+	// pushed.
+	PushInterval time.Duration
+	// TelemetryMaxBytes contains the maximum size of the pushed metrics
+	// payload.
+	TelemetryMaxBytes int32
+	// DeltaTemporality contains whether the sums should be pushed as deltas
+	// since the last push rather than cumulatively.
+	DeltaTemporality bool
+	// RequestedMetrics contains the prefixes of the names of the metrics
+	// to push: an empty list means no metrics, and a single empty string all
+	// metrics.
+	RequestedMetrics []string
+}
+
+func (r *GetTelemetrySubscriptionsResponse) setVersion(v int16) {
+	r.Version = v
+}
+
+func (r *GetTelemetrySubscriptionsResponse) encode(pe packetEncoder) error {
+	pe.putDurationMs(r.ThrottleTime)
+	pe.putKError(r.Err)
+	if err := pe.putRawBytes(r.ClientInstanceId[:]); err != nil {
+		return err
+	}
+	pe.putInt32(r.SubscriptionId)
+	if err := pe.putArrayLength(len(r.AcceptedCompressionTypes)); err != nil {
+		return err
+	}
+	for _, codec := range r.AcceptedCompressionTypes {
+		pe.putInt8(int8(codec))
+	}
+	pe.putDurationMs(r.PushInterval)
+	pe.putInt32(r.TelemetryMaxBytes)
+	pe.putBool(r.DeltaTemporality)
+	if err := pe.putStringArray(r.RequestedMetrics); err != nil {
+		return err
+	}
+	pe.putEmptyTaggedFieldArray()
+	return nil
+}
+
+func (r *GetTelemetrySubscriptionsResponse) decode(pd packetDecoder, version int16) (err error) {
+	r.Version = version
+	if r.ThrottleTime, err = pd.getDurationMs(); err != nil {
+		return err
+	}
+	if r.Err, err = pd.getKError(); err != nil {
+		return err
+	}
+	id, err := pd.getRawBytes(16)
+	if err != nil {
+		return err
+	}
+	copy(r.ClientInstanceId[:], id)
+	if r.SubscriptionId, err = pd.getInt32(); err != nil {
+		return err
+	}
+	n, err := pd.getArrayLength()
+	if err != nil {
+		return err
+	}
+	r.AcceptedCompressionTypes = make([]CompressionCodec, n)
+	for i := range r.AcceptedCompressionTypes {
+		codec, err := pd.getInt8()
+		if err != nil {
+			return err
+		}
+		r.AcceptedCompressionTypes[i] = CompressionCodec(codec)
+	}
+	if r.PushInterval, err = pd.getDurationMs(); err != nil {
+		return err
+	}
+	if r.TelemetryMaxBytes, err = pd.getInt32(); err != nil {
+		return err
+	}
+	if r.DeltaTemporality, err = pd.getBool(); err != nil {
+		return err
+	}
+	if r.RequestedMetrics, err = pd.getStringArray(); err != nil {
+		return err
+	}
+	_, err = pd.getEmptyTaggedFieldArray()
+	return err
+}
+
+func (r *GetTelemetrySubscriptionsResponse) key() int16 {
+	return apiKeyGetTelemetrySubscriptions
+}
+
+func (r *GetTelemetrySubscriptionsResponse) version() int16 {
+	return r.Version
+}
+
+func (r *GetTelemetrySubscriptionsResponse) headerVersion() int16 {
+	return 1
+}
+
+func (r *GetTelemetrySubscriptionsResponse) isValidVersion() bool {
+	return r.Version == 0
+}
+
+func (r *GetTelemetrySubscriptionsResponse) isFlexible() bool {
+	return r.isFlexibleVersion(r.Version)
+}
+
+func (r *GetTelemetrySubscriptionsResponse) isFlexibleVersion(version int16) bool {
+	return version >= 0
+}
+
+func (r *GetTelemetrySubscriptionsResponse) requiredVersion() KafkaVersion {
+	return V3_7_0_0
+}
+
+func (r *GetTelemetrySubscriptionsResponse) throttleTime() time.Duration {
+	return r.ThrottleTime
+}
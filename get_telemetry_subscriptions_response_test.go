@@ -0,0 +1,36 @@
+//go:build !functional
+
+package sarama
+
+import (
+	"testing"
+	"time"
+)
+
+var getTelemetrySubscriptionsResponseV0 = []byte{
+	0, 0, 0, 100, // ThrottleTimeMs
+	0, 0, // ErrorCode
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, // ClientInstanceId
+	0, 0, 0, 7, // SubscriptionId
+	3, 4, 0, // AcceptedCompressionTypes array, length 2
+	0, 0, 0x75, 0x30, // PushIntervalMs
+	0, 0, 0x10, 0, // TelemetryMaxBytes
+	1,                                       // DeltaTemporality
+	2, 8, 's', 'a', 'r', 'a', 'm', 'a', '.', // RequestedMetrics array, length 1
+	0, // empty tagged fields
+}
+
+func TestGetTelemetrySubscriptionsResponse(t *testing.T) {
+	response := &GetTelemetrySubscriptionsResponse{
+		Version:                  0,
+		ThrottleTime:             100 * time.Millisecond,
+		ClientInstanceId:         Uuid{15: 1},
+		SubscriptionId:           7,
+		AcceptedCompressionTypes: []CompressionCodec{CompressionZSTD, CompressionNone},
+		PushInterval:             30 * time.Second,
+		TelemetryMaxBytes:        4096,
+		DeltaTemporality:         true,
+		RequestedMetrics:         []string{"sarama."},
+	}
+	testResponse(t, "V0", response, getTelemetrySubscriptionsResponseV0)
+}
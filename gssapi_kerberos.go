@@ -41,6 +41,9 @@ type GSSAPIConfig struct {
 	Realm              string
 	DisablePAFXFAST    bool
 	BuildSpn           BuildSpnFunc
+	// CredentialCache, if set, provides a Kerberos client shared between
+	// broker connections instead of logging in for every connection.
+	CredentialCache *KerberosCredentialCache
 }
 
 type GSSAPIKerberosAuth struct {
@@ -231,9 +234,18 @@ func (krbAuth *GSSAPIKerberosAuth) Login(
 	return &ticket, nil
 }
 
+// newKerberosClient returns a client from the shared credential cache if one
+// is configured, or a new client otherwise.
+func (krbAuth *GSSAPIKerberosAuth) newKerberosClient() (KerberosClient, error) {
+	if krbAuth.Config.CredentialCache != nil {
+		return krbAuth.Config.CredentialCache.Client()
+	}
+	return krbAuth.NewKerberosClientFunc(krbAuth.Config)
+}
+
 // Authorize performs the kerberos auth handshake for authorization
 func (krbAuth *GSSAPIKerberosAuth) Authorize(broker *Broker) error {
-	client, err := krbAuth.NewKerberosClientFunc(krbAuth.Config)
+	client, err := krbAuth.newKerberosClient()
 	if err != nil {
 		Logger.Printf("Kerberos client initialization error: %s", err)
 		return err
@@ -284,38 +296,6 @@ func (krbAuth *GSSAPIKerberosAuth) AuthorizeV2(
 	broker *Broker,
 	authSendReceiver func(authBytes []byte) (*SaslAuthenticateResponse, error),
 ) error {
-	client, err := krbAuth.NewKerberosClientFunc(krbAuth.Config)
-	if err != nil {
-		Logger.Printf("Kerberos client initialization error: %s", err)
-		return err
-	}
-	defer client.Destroy()
-
-	ticket, err := krbAuth.Login(client, krbAuth.spn(broker))
-	if err != nil {
-		return err
-	}
-
-	principal := strings.Join(ticket.SName.NameString, "/") + "@" + ticket.Realm
-	var receivedBytes []byte
-
-	for {
-		token, err := krbAuth.initSecContext(client, receivedBytes)
-		if err != nil {
-			Logger.Printf("SASL Kerberos init error as %s: %s", principal, err)
-			return err
-		}
-
-		authResponse, err := authSendReceiver(token)
-		if err != nil {
-			Logger.Printf("SASL Kerberos authenticate error as %s: %s", principal, err)
-			return err
-		}
-
-		receivedBytes = authResponse.SaslAuthBytes
-
-		if krbAuth.step == GSS_API_FINISH {
-			return nil
-		}
-	}
+	_, err := runSASLExchange(broker, &gssapiSASLClient{auth: krbAuth, broker: broker}, authSendReceiver)
+	return err
 }
@@ -1,6 +1,7 @@
 package sarama
 
 import (
+	"errors"
 	"io"
 	"strconv"
 	"sync"
@@ -85,3 +86,48 @@ func (b *appendInterceptor) OnConsume(msg *ConsumerMessage) {
 	msg.Value = []byte(string(msg.Value) + strconv.Itoa(b.i))
 	b.i++
 }
+
+var errRejectedByInterceptor = errors.New("rejected by interceptor")
+
+// lifecycleInterceptor rejects messages with a "reject" value and records the
+// acknowledgements, commits and close calls it receives.
+type lifecycleInterceptor struct {
+	lock    sync.Mutex
+	acks    map[string]error
+	commits []map[string]map[int32]int64
+	closed  bool
+}
+
+func (l *lifecycleInterceptor) OnSend(*ProducerMessage) {}
+
+func (l *lifecycleInterceptor) OnSendWithError(msg *ProducerMessage) error {
+	if v, _ := msg.Value.Encode(); string(v) == "reject" {
+		return errRejectedByInterceptor
+	}
+	return nil
+}
+
+func (l *lifecycleInterceptor) OnAcknowledgement(msg *ProducerMessage, err error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.acks == nil {
+		l.acks = make(map[string]error)
+	}
+	v, _ := msg.Value.Encode()
+	l.acks[string(v)] = err
+}
+
+func (l *lifecycleInterceptor) OnConsume(*ConsumerMessage) {}
+
+func (l *lifecycleInterceptor) OnCommit(offsets map[string]map[int32]int64) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.commits = append(l.commits, offsets)
+}
+
+func (l *lifecycleInterceptor) Close() error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.closed = true
+	return nil
+}
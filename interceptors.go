@@ -1,8 +1,15 @@
 package sarama
 
+import (
+	"io"
+)
+
 // ProducerInterceptor allows you to intercept (and possibly mutate) the records
 // received by the producer before they are published to the Kafka cluster.
 // https://cwiki.apache.org/confluence/display/KAFKA/KIP-42%3A+Add+Producer+and+Consumer+Interceptors#KIP42:AddProducerandConsumerInterceptors-Motivation
+//
+// An interceptor may additionally implement ProducerInterceptorWithError,
+// ProducerAcknowledgementInterceptor and io.Closer.
 type ProducerInterceptor interface {
 
 	// OnSend is called when the producer message is intercepted. Please avoid
@@ -11,9 +18,33 @@ type ProducerInterceptor interface {
 	OnSend(*ProducerMessage)
 }
 
+// ProducerInterceptorWithError is a ProducerInterceptor that can reject
+// messages. OnSendWithError is called instead of OnSend; if it returns an
+// error, the remaining interceptors are skipped and the message is returned
+// on the producer's Errors() channel with that error instead of being sent.
+type ProducerInterceptorWithError interface {
+	ProducerInterceptor
+
+	OnSendWithError(*ProducerMessage) error
+}
+
+// ProducerAcknowledgementInterceptor is a ProducerInterceptor that is
+// notified once a message has been acknowledged by the broker or has failed.
+type ProducerAcknowledgementInterceptor interface {
+	ProducerInterceptor
+
+	// OnAcknowledgement is called with a nil error when the message was
+	// successfully produced, or with the error it is returned with otherwise.
+	// It runs on the producer's internal goroutines and should be fast.
+	OnAcknowledgement(msg *ProducerMessage, err error)
+}
+
 // ConsumerInterceptor allows you to intercept (and possibly mutate) the records
 // received by the consumer before they are sent to the messages channel.
 // https://cwiki.apache.org/confluence/display/KAFKA/KIP-42%3A+Add+Producer+and+Consumer+Interceptors#KIP42:AddProducerandConsumerInterceptors-Motivation
+//
+// An interceptor may additionally implement ConsumerCommitInterceptor and
+// io.Closer.
 type ConsumerInterceptor interface {
 
 	// OnConsume is called when the consumed message is intercepted. Please
@@ -22,22 +53,77 @@ type ConsumerInterceptor interface {
 	OnConsume(*ConsumerMessage)
 }
 
-func (msg *ProducerMessage) safelyApplyInterceptor(interceptor ProducerInterceptor) {
+// ConsumerCommitInterceptor is a ConsumerInterceptor that is notified of the
+// offsets committed by the offset manager.
+type ConsumerCommitInterceptor interface {
+	ConsumerInterceptor
+
+	// OnCommit is called after a successful offset commit with the offsets
+	// committed, by topic and partition.
+	OnCommit(offsets map[string]map[int32]int64)
+}
+
+// safelyApplyInterceptor calls the OnSend (or OnSendWithError) hook of the
+// interceptor, returning the error if the message was rejected. Panics are
+// logged to log and otherwise ignored, as in the other safely* functions.
+func (msg *ProducerMessage) safelyApplyInterceptor(interceptor ProducerInterceptor, log *logger) (err error) {
 	defer func() {
 		if r := recover(); r != nil {
-			Logger.Printf("Error when calling producer interceptor: %v, %v", interceptor, r)
+			log.Error("Error when calling producer interceptor", "interceptor", interceptor, "panic", r)
 		}
 	}()
 
+	if i, ok := interceptor.(ProducerInterceptorWithError); ok {
+		return i.OnSendWithError(msg)
+	}
 	interceptor.OnSend(msg)
+	return nil
 }
 
-func (msg *ConsumerMessage) safelyApplyInterceptor(interceptor ConsumerInterceptor) {
+func (msg *ConsumerMessage) safelyApplyInterceptor(interceptor ConsumerInterceptor, log *logger) {
 	defer func() {
 		if r := recover(); r != nil {
-			Logger.Printf("Error when calling consumer interceptor: %v, %v", interceptor, r)
+			log.Error("Error when calling consumer interceptor", "interceptor", interceptor, "panic", r)
 		}
 	}()
 
 	interceptor.OnConsume(msg)
 }
+
+func (msg *ProducerMessage) safelyAcknowledge(interceptor ProducerAcknowledgementInterceptor, err error, log *logger) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Error("Error when calling producer interceptor acknowledgement", "interceptor", interceptor, "panic", r)
+		}
+	}()
+
+	interceptor.OnAcknowledgement(msg, err)
+}
+
+func safelyCommit(interceptor ConsumerCommitInterceptor, offsets map[string]map[int32]int64, log *logger) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Error("Error when calling consumer interceptor commit", "interceptor", interceptor, "panic", r)
+		}
+	}()
+
+	interceptor.OnCommit(offsets)
+}
+
+// safelyCloseInterceptor closes the interceptor if it implements io.Closer,
+// logging any error to log.
+func safelyCloseInterceptor(interceptor interface{}, log *logger) {
+	closer, ok := interceptor.(io.Closer)
+	if !ok {
+		return
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			log.Error("Error when closing interceptor", "interceptor", interceptor, "panic", r)
+		}
+	}()
+
+	if err := closer.Close(); err != nil {
+		log.Error("Error when closing interceptor", "interceptor", interceptor, "err", err)
+	}
+}
@@ -0,0 +1,237 @@
+package sarama
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/jcmturner/gokrb5/v8/messages"
+	"github.com/jcmturner/gokrb5/v8/types"
+)
+
+// KerberosCredentialCache shares a single logged-in KerberosClient between
+// the broker connections of a GSSAPIConfig, so that each new connection only
+// needs a service ticket instead of a full AS exchange. The TGT is renewed in
+// the background by logging in again every RenewInterval, and the client is
+// recreated when the keytab (or credential cache file) it was created from
+// changes on disk.
+//
+// Assign it to GSSAPIConfig.CredentialCache to use it, and Close it once all
+// clients using it have been closed.
+type KerberosCredentialCache struct {
+	// NewKerberosClientFunc creates the shared client (defaults to
+	// NewKerberosClient).
+	NewKerberosClientFunc func(config *GSSAPIConfig) (KerberosClient, error)
+	// RenewInterval is how often the TGT is renewed (defaults to 1 hour).
+	// Renewals that fail are retried after RetryBackoff (defaults to 10s)
+	// while the current client remains in use.
+	RenewInterval time.Duration
+	RetryBackoff  time.Duration
+	// Logger receives the login and renewal failures and successes (defaults
+	// to the global Logger and DebugLogger).
+	Logger StructuredLogger
+
+	config GSSAPIConfig
+
+	lock        sync.Mutex
+	client      KerberosClient
+	fingerprint fileFingerprint
+	timer       *time.Timer
+	closed      bool
+}
+
+// NewKerberosCredentialCache creates a KerberosCredentialCache for config.
+// No login happens until the first broker connection is authenticated.
+func NewKerberosCredentialCache(config GSSAPIConfig) *KerberosCredentialCache {
+	config.CredentialCache = nil
+	return &KerberosCredentialCache{
+		NewKerberosClientFunc: NewKerberosClient,
+		RenewInterval:         time.Hour,
+		RetryBackoff:          10 * time.Second,
+		config:                config,
+	}
+}
+
+// Client returns a client sharing the cached credentials, logging in first
+// if necessary. The returned client must not be used after the cache has
+// been closed.
+func (c *KerberosCredentialCache) Client() (KerberosClient, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.closed {
+		return nil, ErrClosedClient
+	}
+	if c.client == nil || c.credentialFileChanged() {
+		if err := c.loginLocked(); err != nil {
+			if c.client == nil {
+				return nil, err
+			}
+			c.logger().Warn("Kerberos credential reload failed, using the previous credentials", "err", err)
+		}
+	}
+	return &sharedKerberosClient{cache: c}, nil
+}
+
+// Close stops the background renewal and destroys the shared client.
+func (c *KerberosCredentialCache) Close() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.closed = true
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+	if c.client != nil {
+		c.client.Destroy()
+		c.client = nil
+	}
+	return nil
+}
+
+func (c *KerberosCredentialCache) logger() *logger {
+	return &logger{base: c.Logger, args: []any{"principal", c.config.Username + "@" + c.config.Realm}}
+}
+
+// credentialFile returns the keytab or credential cache file the client is
+// created from, if any.
+func (c *KerberosCredentialCache) credentialFile() string {
+	switch c.config.AuthType {
+	case KRB5_KEYTAB_AUTH:
+		return c.config.KeyTabPath
+	case KRB5_CCACHE_AUTH:
+		return c.config.CCachePath
+	}
+	return ""
+}
+
+func (c *KerberosCredentialCache) statCredentialFile() fileFingerprint {
+	name := c.credentialFile()
+	if name == "" {
+		return fileFingerprint{}
+	}
+	info, err := os.Stat(name)
+	if err != nil {
+		return fileFingerprint{}
+	}
+	return fileFingerprint{size: info.Size(), modTime: info.ModTime()}
+}
+
+// c.lock must be held by caller
+func (c *KerberosCredentialCache) credentialFileChanged() bool {
+	if c.credentialFile() == "" {
+		return false
+	}
+	return c.statCredentialFile() != c.fingerprint
+}
+
+// loginLocked creates and logs in a new client, replacing the current one on
+// success, and schedules the next renewal.
+// c.lock must be held by caller
+func (c *KerberosCredentialCache) loginLocked() error {
+	fingerprint := c.statCredentialFile()
+	client, err := c.NewKerberosClientFunc(&c.config)
+	if err == nil {
+		if err = client.Login(); err != nil {
+			client.Destroy()
+		}
+	}
+	if err != nil {
+		c.logger().Error("Kerberos login error", "err", err)
+		if c.client != nil {
+			c.scheduleRenewal(c.RetryBackoff)
+		}
+		return err
+	}
+
+	if c.client != nil {
+		c.client.Destroy()
+	}
+	c.client = client
+	c.fingerprint = fingerprint
+	c.logger().Debug("Kerberos login succeeded")
+	c.scheduleRenewal(c.RenewInterval)
+	return nil
+}
+
+// c.lock must be held by caller
+func (c *KerberosCredentialCache) scheduleRenewal(delay time.Duration) {
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+	c.timer = time.AfterFunc(delay, c.renew)
+}
+
+// renew logs in again to renew the TGT, recreating the client if its
+// credential file changed.
+func (c *KerberosCredentialCache) renew() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.closed {
+		return
+	}
+	if c.client == nil || c.credentialFileChanged() {
+		_ = c.loginLocked()
+		return
+	}
+	if err := c.client.Login(); err != nil {
+		c.logger().Error("Kerberos TGT renewal error", "err", err)
+		c.scheduleRenewal(c.RetryBackoff)
+		return
+	}
+	c.logger().Debug("Kerberos TGT renewed")
+	c.scheduleRenewal(c.RenewInterval)
+}
+
+// sharedKerberosClient is the KerberosClient handed out by a
+// KerberosCredentialCache. Logging in and destroying it are no-ops, as the
+// cache manages the lifecycle of the underlying client.
+type sharedKerberosClient struct {
+	cache *KerberosCredentialCache
+}
+
+func (s *sharedKerberosClient) current() (KerberosClient, error) {
+	if s.cache.client == nil {
+		return nil, ErrClosedClient
+	}
+	return s.cache.client, nil
+}
+
+func (s *sharedKerberosClient) Login() error {
+	return nil
+}
+
+func (s *sharedKerberosClient) GetServiceTicket(spn string) (messages.Ticket, types.EncryptionKey, error) {
+	s.cache.lock.Lock()
+	defer s.cache.lock.Unlock()
+
+	client, err := s.current()
+	if err != nil {
+		return messages.Ticket{}, types.EncryptionKey{}, err
+	}
+	return client.GetServiceTicket(spn)
+}
+
+func (s *sharedKerberosClient) Domain() string {
+	s.cache.lock.Lock()
+	defer s.cache.lock.Unlock()
+
+	if client, err := s.current(); err == nil {
+		return client.Domain()
+	}
+	return ""
+}
+
+func (s *sharedKerberosClient) CName() types.PrincipalName {
+	s.cache.lock.Lock()
+	defer s.cache.lock.Unlock()
+
+	if client, err := s.current(); err == nil {
+		return client.CName()
+	}
+	return types.PrincipalName{}
+}
+
+func (s *sharedKerberosClient) Destroy() {}
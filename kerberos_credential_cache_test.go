@@ -0,0 +1,164 @@
+//go:build !functional
+
+package sarama
+
+import (
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rcrowley/go-metrics"
+)
+
+type countingKerberosClient struct {
+	MockKerberosClient
+	logins    *atomic.Int32
+	destroyed atomic.Bool
+}
+
+func (c *countingKerberosClient) Login() error {
+	c.logins.Add(1)
+	return c.MockKerberosClient.Login()
+}
+
+func (c *countingKerberosClient) Destroy() {
+	c.destroyed.Store(true)
+}
+
+type countingKerberosClients struct {
+	logins  atomic.Int32
+	created []*countingKerberosClient
+}
+
+func (c *countingKerberosClients) newClient(*GSSAPIConfig) (KerberosClient, error) {
+	client := &countingKerberosClient{logins: &c.logins}
+	c.created = append(c.created, client)
+	return client, nil
+}
+
+func TestKerberosCredentialCacheSharedBetweenBrokers(t *testing.T) {
+	mockBroker := NewMockBroker(t, 0)
+	defer mockBroker.Close()
+	gssapiHandler := KafkaGSSAPIHandler{client: &MockKerberosClient{}}
+	mockBroker.SetGSSAPIHandler(gssapiHandler.MockKafkaGSSAPI)
+
+	conf := NewTestConfig()
+	conf.Version = V1_0_0_0
+	conf.Net.SASL.Enable = true
+	conf.Net.SASL.Version = SASLHandshakeV0
+	conf.Net.SASL.Mechanism = SASLTypeGSSAPI
+	conf.Net.SASL.GSSAPI = GSSAPIConfig{
+		AuthType:           KRB5_USER_AUTH,
+		ServiceName:        "kafka",
+		KerberosConfigPath: "testdata/krb5.conf",
+		Realm:              "EXAMPLE.COM",
+		Username:           "kafka",
+		Password:           "kafka",
+	}
+
+	clients := &countingKerberosClients{}
+	cache := NewKerberosCredentialCache(conf.Net.SASL.GSSAPI)
+	cache.NewKerberosClientFunc = clients.newClient
+	t.Cleanup(func() { _ = cache.Close() })
+	conf.Net.SASL.GSSAPI.CredentialCache = cache
+
+	for range 3 {
+		broker := NewBroker(mockBroker.Addr())
+		broker.requestsInFlight = metrics.NilCounter{}
+		if err := broker.Open(conf); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := broker.Connected(); err != nil {
+			t.Fatal(err)
+		}
+		_ = broker.Close()
+	}
+
+	if n := clients.logins.Load(); n != 1 {
+		t.Errorf("expected a single login for all connections, got %d", n)
+	}
+	if len(clients.created) != 1 || clients.created[0].destroyed.Load() {
+		t.Error("expected the shared client to be kept alive")
+	}
+
+	_ = cache.Close()
+	if !clients.created[0].destroyed.Load() {
+		t.Error("expected the shared client to be destroyed when the cache is closed")
+	}
+	if _, err := cache.Client(); err == nil {
+		t.Error("expected an error from a closed cache")
+	}
+}
+
+func TestKerberosCredentialCacheRenewal(t *testing.T) {
+	clients := &countingKerberosClients{}
+	cache := NewKerberosCredentialCache(GSSAPIConfig{AuthType: KRB5_USER_AUTH})
+	cache.NewKerberosClientFunc = clients.newClient
+	cache.RenewInterval = 20 * time.Millisecond
+	t.Cleanup(func() { _ = cache.Close() })
+
+	if _, err := cache.Client(); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for clients.logins.Load() < 3 {
+		if time.Now().After(deadline) {
+			t.Fatal("TGT was not renewed in the background")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+	if len(clients.created) != 1 {
+		t.Errorf("expected renewals to reuse the client, got %d clients", len(clients.created))
+	}
+}
+
+func TestKerberosCredentialCacheKeytabReload(t *testing.T) {
+	keytab := filepath.Join(t.TempDir(), "kafka.keytab")
+	if err := os.WriteFile(keytab, []byte("first"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	clients := &countingKerberosClients{}
+	cache := NewKerberosCredentialCache(GSSAPIConfig{AuthType: KRB5_KEYTAB_AUTH, KeyTabPath: keytab})
+	cache.NewKerberosClientFunc = clients.newClient
+	t.Cleanup(func() { _ = cache.Close() })
+
+	client, err := cache.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cache.Client(); err != nil {
+		t.Fatal(err)
+	}
+	if len(clients.created) != 1 {
+		t.Fatalf("expected a single client while the keytab is unchanged, got %d", len(clients.created))
+	}
+
+	if err := os.WriteFile(keytab, []byte("second"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(keytab, future, future); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cache.Client(); err != nil {
+		t.Fatal(err)
+	}
+	if len(clients.created) != 2 {
+		t.Fatalf("expected the client to be recreated after the keytab changed, got %d clients", len(clients.created))
+	}
+	if !clients.created[0].destroyed.Load() {
+		t.Error("expected the previous client to be destroyed")
+	}
+
+	// clients handed out earlier use the new credentials
+	if _, _, err := client.GetServiceTicket("kafka/localhost"); err != nil {
+		t.Error(err)
+	}
+}
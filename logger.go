@@ -0,0 +1,112 @@
+package sarama
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// StructuredLogger is a leveled logger taking key-value pairs of fields
+// alongside each message, as done by *slog.Logger which implements it:
+//
+//	config.Logger = slog.Default()
+//
+// The fields Sarama adds to the entries it logs describe the component that
+// logs them: "broker" and "addr" for brokers, "topic" and "partition" for
+// partition producers and consumers, "group" and "member" for consumer
+// groups and "transactional_id" for transactional producers. Use a logger
+// with fields of its own, e.g. from slog.Logger.With, to tell the entries of
+// several clients apart.
+type StructuredLogger interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+// logger is the logger of a client component, adding the fields describing
+// the component to the entries it logs to Config.Logger. Without
+// Config.Logger, debug entries are logged to DebugLogger and the others to
+// Logger, with their fields formatted as key=value pairs after the message.
+// A nil *logger logs to the global loggers without fields.
+type logger struct {
+	base StructuredLogger
+	args []any
+}
+
+func newLogger(conf *Config, args ...any) *logger {
+	l := &logger{args: args}
+	if conf != nil {
+		l.base = conf.Logger
+	}
+	return l
+}
+
+// with returns a logger adding args to the fields of l.
+func (l *logger) with(args ...any) *logger {
+	if l == nil {
+		return &logger{args: args}
+	}
+	return &logger{base: l.base, args: append(slices.Clip(l.args), args...)}
+}
+
+func (l *logger) Debug(msg string, args ...any) {
+	if l != nil && l.base != nil {
+		l.base.Debug(msg, l.fields(args)...)
+		return
+	}
+	DebugLogger.Println(l.format(msg, args))
+}
+
+func (l *logger) Info(msg string, args ...any) {
+	if l != nil && l.base != nil {
+		l.base.Info(msg, l.fields(args)...)
+		return
+	}
+	Logger.Println(l.format(msg, args))
+}
+
+func (l *logger) Warn(msg string, args ...any) {
+	if l != nil && l.base != nil {
+		l.base.Warn(msg, l.fields(args)...)
+		return
+	}
+	Logger.Println(l.format(msg, args))
+}
+
+func (l *logger) Error(msg string, args ...any) {
+	if l != nil && l.base != nil {
+		l.base.Error(msg, l.fields(args)...)
+		return
+	}
+	Logger.Println(l.format(msg, args))
+}
+
+func (l *logger) fields(args []any) []any {
+	if len(l.args) == 0 {
+		return args
+	}
+	return append(slices.Clip(l.args), args...)
+}
+
+// format formats msg and the fields for the global loggers, e.g.
+// "Connected to broker addr=localhost:9092 broker=1".
+func (l *logger) format(msg string, args []any) string {
+	var fields []any
+	if l != nil {
+		fields = l.fields(args)
+	} else {
+		fields = args
+	}
+	var sb strings.Builder
+	sb.WriteString(msg)
+	for i := 0; i < len(fields); i += 2 {
+		sb.WriteByte(' ')
+		if i+1 == len(fields) {
+			fmt.Fprintf(&sb, "!BADKEY=%v", fields[i])
+			break
+		}
+		fmt.Fprintf(&sb, "%v=%v", fields[i], fields[i+1])
+	}
+	return sb.String()
+}
@@ -2,7 +2,14 @@
 
 package sarama
 
-import "testing"
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+)
 
 // testLogger implements the StdLogger interface and records the text in the
 // logs of the given T passed from Test functions.
@@ -36,3 +43,53 @@ func (l *testLogger) Println(v ...interface{}) {
 		l.t.Log(v...)
 	}
 }
+
+func TestLoggerFormat(t *testing.T) {
+	l := newLogger(nil, "broker", 1).with("topic", "my_topic")
+	if s := l.format("Connected", []any{"partition", 0}); s != "Connected broker=1 topic=my_topic partition=0" {
+		t.Errorf("Unexpected log entry %q", s)
+	}
+	if s := (*logger)(nil).format("Connected", []any{"odd"}); s != "Connected !BADKEY=odd" {
+		t.Errorf("Unexpected log entry %q", s)
+	}
+}
+
+func TestBrokerStructuredLogger(t *testing.T) {
+	mockBroker := NewMockBroker(t, 0)
+	defer mockBroker.Close()
+
+	var buf bytes.Buffer
+	var mu sync.Mutex
+	conf := NewTestConfig()
+	conf.Logger = slog.New(slog.NewTextHandler(&lockedWriter{w: &buf, mu: &mu}, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	broker := NewBroker(mockBroker.Addr())
+	if err := broker.Open(conf); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := broker.Connected(); err != nil {
+		t.Fatal(err)
+	}
+	safeClose(t, broker)
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, expected := range []string{
+		`level=DEBUG msg="Connected to broker" broker=-1 addr=` + mockBroker.Addr() + ` registered=false`,
+		`level=DEBUG msg="Closed connection to broker" broker=-1 addr=` + mockBroker.Addr(),
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("Expected log entry %q, got:\n%s", expected, buf.String())
+		}
+	}
+}
+
+type lockedWriter struct {
+	w  io.Writer
+	mu *sync.Mutex
+}
+
+func (w *lockedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.Write(p)
+}
@@ -1,8 +1,6 @@
 package sarama
 
 import (
-	"strconv"
-	"strings"
 	"sync"
 
 	"github.com/rcrowley/go-metrics"
@@ -17,37 +15,103 @@ const (
 	metricsAlphaFactor   = 0.015
 )
 
-func getOrRegisterHistogram(name string, r metrics.Registry) metrics.Histogram {
-	return r.GetOrRegister(name, func() metrics.Histogram {
+func getOrRegisterHistogram(name string, r metrics.Registry, labels ...metricLabel) metrics.Histogram {
+	if sink := metricsSinkOf(r); sink != nil {
+		return sinkHistogram{histogram: sink.Histogram(name, metricLabels(labels))}
+	}
+	return r.GetOrRegister(metricName(name, labels), func() metrics.Histogram {
 		return metrics.NewHistogram(metrics.NewExpDecaySample(metricsReservoirSize, metricsAlphaFactor))
 	}).(metrics.Histogram)
 }
 
+func getOrRegisterMeter(name string, r metrics.Registry, labels ...metricLabel) metrics.Meter {
+	if sink := metricsSinkOf(r); sink != nil {
+		return sinkMeter{counter: sink.Counter(name, metricLabels(labels))}
+	}
+	return metrics.GetOrRegisterMeter(metricName(name, labels), r)
+}
+
+func getOrRegisterCounter(name string, r metrics.Registry, labels ...metricLabel) metrics.Counter {
+	if sink := metricsSinkOf(r); sink != nil {
+		return sinkCounter{gauge: sink.Gauge(name, metricLabels(labels))}
+	}
+	return metrics.GetOrRegisterCounter(metricName(name, labels), r)
+}
+
+func getOrRegisterGauge(name string, r metrics.Registry, labels ...metricLabel) metrics.Gauge {
+	if sink := metricsSinkOf(r); sink != nil {
+		return sinkGauge{gauge: sink.Gauge(name, metricLabels(labels))}
+	}
+	return metrics.GetOrRegisterGauge(metricName(name, labels), r)
+}
+
+// The aggregate metrics (e.g. the request-rate of all brokers) are not
+// reported to a MetricsSink, which aggregates the labelled metrics itself.
+
+func getOrRegisterAggregateHistogram(name string, r metrics.Registry) metrics.Histogram {
+	if metricsSinkOf(r) != nil {
+		return metrics.NilHistogram{}
+	}
+	return getOrRegisterHistogram(name, r)
+}
+
+func getOrRegisterAggregateMeter(name string, r metrics.Registry) metrics.Meter {
+	if metricsSinkOf(r) != nil {
+		return metrics.NilMeter{}
+	}
+	return getOrRegisterMeter(name, r)
+}
+
+func getOrRegisterAggregateCounter(name string, r metrics.Registry) metrics.Counter {
+	if metricsSinkOf(r) != nil {
+		return metrics.NilCounter{}
+	}
+	return getOrRegisterCounter(name, r)
+}
+
+func getOrRegisterAggregateGauge(name string, r metrics.Registry) metrics.Gauge {
+	if metricsSinkOf(r) != nil {
+		return metrics.NilGauge{}
+	}
+	return getOrRegisterGauge(name, r)
+}
+
+// unregisterMetric unregisters the metric of name and labels, e.g. of a
+// partition no longer consumed, removing it from the MetricsSink if it
+// supports it.
+func unregisterMetric(name string, r metrics.Registry, labels ...metricLabel) {
+	if sink := metricsSinkOf(r); sink != nil {
+		if remover, ok := sink.(MetricsSinkRemover); ok {
+			remover.Remove(name, metricLabels(labels))
+		}
+		return
+	}
+	r.Unregister(metricName(name, labels))
+}
+
 func getMetricNameForBroker(name string, broker *Broker) string {
-	// Use broker id like the Java client as it does not contain '.' or ':' characters that
-	// can be interpreted as special character by monitoring tool (e.g. Graphite)
-	return name + "-for-broker-" + strconv.FormatInt(int64(broker.ID()), 10)
+	return metricName(name, []metricLabel{brokerMetricLabel(broker.ID())})
 }
 
 func getMetricNameForTopic(name string, topic string) string {
-	// Convert dot to _ since reporters like Graphite typically use dot to represent hierarchy
-	// cf. KAFKA-1902 and KAFKA-2337
-	return name + "-for-topic-" + strings.ReplaceAll(topic, ".", "_")
+	return metricName(name, []metricLabel{topicMetricLabel(topic)})
 }
 
 func getOrRegisterTopicMeter(name string, topic string, r metrics.Registry) metrics.Meter {
-	return metrics.GetOrRegisterMeter(getMetricNameForTopic(name, topic), r)
+	return getOrRegisterMeter(name, r, topicMetricLabel(topic))
 }
 
 func getOrRegisterTopicHistogram(name string, topic string, r metrics.Registry) metrics.Histogram {
-	return getOrRegisterHistogram(getMetricNameForTopic(name, topic), r)
+	return getOrRegisterHistogram(name, r, topicMetricLabel(topic))
 }
 
 // cleanupRegistry is an implementation of metrics.Registry that allows
 // to unregister from the parent registry only those metrics
-// that have been registered in cleanupRegistry
+// that have been registered in cleanupRegistry.
+// It also carries the MetricsSink the metrics are reported to instead, if any.
 type cleanupRegistry struct {
 	parent  metrics.Registry
+	sink    MetricsSink
 	metrics map[string]struct{}
 	mutex   sync.RWMutex
 }
@@ -59,6 +123,16 @@ func newCleanupRegistry(parent metrics.Registry) metrics.Registry {
 	}
 }
 
+// newMetricRegistry returns the registry of the metrics of a client
+// component, reporting them to conf.MetricsSink if set.
+func newMetricRegistry(conf *Config) metrics.Registry {
+	return &cleanupRegistry{
+		parent:  conf.MetricRegistry,
+		sink:    conf.MetricsSink,
+		metrics: map[string]struct{}{},
+	}
+}
+
 func (r *cleanupRegistry) Each(fn func(string, interface{})) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
@@ -0,0 +1,151 @@
+// Package otel provides a sarama.MetricsSink reporting the metrics of Sarama
+// with the OpenTelemetry metrics API.
+//
+// The metrics keep the names of the Sarama metrics, prefixed with "sarama.",
+// and their labels are reported as attributes, so that for instance the
+// request-rate of the brokers is the sarama.request-rate counter with a
+// broker attribute.
+package otel
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/IBM/sarama"
+	otelapi "go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Sink is a sarama.MetricsSink creating the instruments with an OpenTelemetry
+// meter.
+type Sink struct {
+	meter metric.Meter
+
+	lock       sync.Mutex
+	counters   map[string]metric.Int64Counter
+	gauges     map[string]metric.Int64Gauge
+	histograms map[string]metric.Int64Histogram
+	values     map[string]*atomic.Int64
+}
+
+// NewSink returns a Sink creating the instruments with meter, e.g.
+// otel.Meter("github.com/IBM/sarama").
+func NewSink(meter metric.Meter) *Sink {
+	return &Sink{
+		meter:      meter,
+		counters:   map[string]metric.Int64Counter{},
+		gauges:     map[string]metric.Int64Gauge{},
+		histograms: map[string]metric.Int64Histogram{},
+		values:     map[string]*atomic.Int64{},
+	}
+}
+
+// Counter implements sarama.MetricsSink.
+func (s *Sink) Counter(name string, labels map[string]string) sarama.MetricsCounter {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	instrument, ok := s.counters[name]
+	if !ok {
+		var err error
+		if instrument, err = s.meter.Int64Counter("sarama."+name,
+			metric.WithDescription("Sarama "+name+" meter.")); err != nil {
+			otelapi.Handle(err)
+		}
+		s.counters[name] = instrument
+	}
+	return counter{instrument, attributes(labels)}
+}
+
+// Gauge implements sarama.MetricsSink. As gauges are incremented and
+// decremented by Sarama, their current value is kept by the sink and
+// recorded on each update.
+func (s *Sink) Gauge(name string, labels map[string]string) sarama.MetricsGauge {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	instrument, ok := s.gauges[name]
+	if !ok {
+		var err error
+		if instrument, err = s.meter.Int64Gauge("sarama."+name,
+			metric.WithDescription("Sarama "+name+" gauge.")); err != nil {
+			otelapi.Handle(err)
+		}
+		s.gauges[name] = instrument
+	}
+	key := name + "{" + labelsKey(labels) + "}"
+	value, ok := s.values[key]
+	if !ok {
+		value = new(atomic.Int64)
+		s.values[key] = value
+	}
+	return gauge{instrument, attributes(labels), value}
+}
+
+// Histogram implements sarama.MetricsSink.
+func (s *Sink) Histogram(name string, labels map[string]string) sarama.MetricsHistogram {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	instrument, ok := s.histograms[name]
+	if !ok {
+		var err error
+		if instrument, err = s.meter.Int64Histogram("sarama."+name,
+			metric.WithDescription("Sarama "+name+" histogram.")); err != nil {
+			otelapi.Handle(err)
+		}
+		s.histograms[name] = instrument
+	}
+	return histogram{instrument, attributes(labels)}
+}
+
+func attributes(labels map[string]string) metric.MeasurementOption {
+	kvs := make([]attribute.KeyValue, 0, len(labels))
+	for k, v := range labels {
+		kvs = append(kvs, attribute.String(k, v))
+	}
+	return metric.WithAttributeSet(attribute.NewSet(kvs...))
+}
+
+func labelsKey(labels map[string]string) string {
+	kvs := make([]string, 0, len(labels))
+	for k, v := range labels {
+		kvs = append(kvs, k+"="+v)
+	}
+	sort.Strings(kvs)
+	return strings.Join(kvs, ",")
+}
+
+type counter struct {
+	instrument metric.Int64Counter
+	attributes metric.MeasurementOption
+}
+
+func (c counter) Add(delta int64) {
+	c.instrument.Add(context.Background(), delta, c.attributes)
+}
+
+type gauge struct {
+	instrument metric.Int64Gauge
+	attributes metric.MeasurementOption
+	value      *atomic.Int64
+}
+
+func (g gauge) Set(value int64) {
+	g.value.Store(value)
+	g.instrument.Record(context.Background(), value, g.attributes)
+}
+
+func (g gauge) Add(delta int64) {
+	g.instrument.Record(context.Background(), g.value.Add(delta), g.attributes)
+}
+
+type histogram struct {
+	instrument metric.Int64Histogram
+	attributes metric.MeasurementOption
+}
+
+func (h histogram) Observe(value int64) {
+	h.instrument.Record(context.Background(), value, h.attributes)
+}
@@ -0,0 +1,52 @@
+package otel
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestSink(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	sink := NewSink(provider.Meter("github.com/IBM/sarama"))
+
+	sink.Counter("request-rate", map[string]string{"broker": "1"}).Add(2)
+	sink.Counter("request-rate", map[string]string{"broker": "1"}).Add(1)
+	inFlight := sink.Gauge("requests-in-flight", map[string]string{"broker": "1"})
+	inFlight.Add(3)
+	sink.Gauge("requests-in-flight", map[string]string{"broker": "1"}).Add(-1)
+	sink.Histogram("batch-size", map[string]string{"topic": "my.topic"}).Observe(100)
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	found := map[string]bool{}
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		found[m.Name] = true
+		switch data := m.Data.(type) {
+		case metricdata.Sum[int64]:
+			point := data.DataPoints[0]
+			if broker, _ := point.Attributes.Value("broker"); point.Value != 3 || broker != attribute.StringValue("1") {
+				t.Errorf("unexpected %s data point %+v", m.Name, point)
+			}
+		case metricdata.Gauge[int64]:
+			if point := data.DataPoints[0]; point.Value != 2 {
+				t.Errorf("unexpected %s data point %+v", m.Name, point)
+			}
+		case metricdata.Histogram[int64]:
+			if point := data.DataPoints[0]; point.Count != 1 || point.Sum != 100 {
+				t.Errorf("unexpected %s data point %+v", m.Name, point)
+			}
+		}
+	}
+	for _, name := range []string{"sarama.request-rate", "sarama.requests-in-flight", "sarama.batch-size"} {
+		if !found[name] {
+			t.Errorf("expected %s to be reported", name)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+// Package prometheus provides a sarama.MetricsSink reporting the metrics of
+// Sarama to the Prometheus client library.
+//
+// The names of the metrics are prefixed with a namespace and have their dashes
+// replaced with underscores, counters being suffixed with "_total", so that
+// for instance the request-rate of the brokers is exported as
+// sarama_request_rate_total{broker="1"}.
+package prometheus
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/IBM/sarama"
+	prom "github.com/prometheus/client_golang/prometheus"
+)
+
+// DefaultBuckets are the buckets of the histograms, spanning the sizes in
+// bytes as well as the latencies in milliseconds reported by Sarama.
+var DefaultBuckets = prom.ExponentialBuckets(1, 2, 24)
+
+// Sink is a sarama.MetricsSink registering the metrics as Prometheus
+// collectors.
+//
+// A metric that cannot be registered, e.g. because its name clashes with
+// another collector of the registerer, or whose labels do not match those it
+// was registered with, is not reported: its error is passed once to the
+// error handler and the updates of the metric are dropped.
+type Sink struct {
+	registerer   prom.Registerer
+	namespace    string
+	buckets      []float64
+	errorHandler func(error)
+
+	lock       sync.Mutex
+	counters   map[string]*prom.CounterVec
+	gauges     map[string]*prom.GaugeVec
+	histograms map[string]*prom.HistogramVec
+	reported   map[string]bool // metrics whose error was reported
+}
+
+// NewSink returns a Sink registering the metrics with registerer (e.g.
+// prometheus.DefaultRegisterer) in namespace (e.g. "sarama").
+func NewSink(registerer prom.Registerer, namespace string) *Sink {
+	return &Sink{
+		registerer: registerer,
+		namespace:  namespace,
+		buckets:    DefaultBuckets,
+		errorHandler: func(err error) {
+			sarama.Logger.Println(err)
+		},
+		counters:   map[string]*prom.CounterVec{},
+		gauges:     map[string]*prom.GaugeVec{},
+		histograms: map[string]*prom.HistogramVec{},
+		reported:   map[string]bool{},
+	}
+}
+
+// WithBuckets sets the buckets of the histograms, which must be called before
+// the sink is used.
+func (s *Sink) WithBuckets(buckets []float64) *Sink {
+	s.buckets = buckets
+	return s
+}
+
+// WithErrorHandler sets the function the errors of the metrics that cannot
+// be reported are passed to, once per metric, instead of logging them with
+// sarama.Logger. It must be called before the sink is used.
+func (s *Sink) WithErrorHandler(handler func(error)) *Sink {
+	s.errorHandler = handler
+	return s
+}
+
+// Counter implements sarama.MetricsSink.
+func (s *Sink) Counter(name string, labels map[string]string) sarama.MetricsCounter {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	vec, ok := s.counters[name]
+	if !ok {
+		collector, err := register(s.registerer, prom.NewCounterVec(prom.CounterOpts{
+			Namespace: s.namespace,
+			Name:      metricName(name) + "_total",
+			Help:      "Sarama " + name + " meter.",
+		}, labelNames(labels)))
+		// the metric is not reported if it cannot be registered
+		vec, _ = collector.(*prom.CounterVec)
+		if vec == nil {
+			s.reportError(name, err)
+		}
+		s.counters[name] = vec
+	}
+	if vec == nil {
+		return noop{}
+	}
+	c, err := vec.GetMetricWith(labels)
+	if err != nil {
+		s.reportError(name, err)
+		return noop{}
+	}
+	return counter{c}
+}
+
+// Gauge implements sarama.MetricsSink.
+func (s *Sink) Gauge(name string, labels map[string]string) sarama.MetricsGauge {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	vec, ok := s.gauges[name]
+	if !ok {
+		collector, err := register(s.registerer, prom.NewGaugeVec(prom.GaugeOpts{
+			Namespace: s.namespace,
+			Name:      metricName(name),
+			Help:      "Sarama " + name + " gauge.",
+		}, labelNames(labels)))
+		// the metric is not reported if it cannot be registered
+		vec, _ = collector.(*prom.GaugeVec)
+		if vec == nil {
+			s.reportError(name, err)
+		}
+		s.gauges[name] = vec
+	}
+	if vec == nil {
+		return noop{}
+	}
+	g, err := vec.GetMetricWith(labels)
+	if err != nil {
+		s.reportError(name, err)
+		return noop{}
+	}
+	return gauge{g}
+}
+
+// Histogram implements sarama.MetricsSink.
+func (s *Sink) Histogram(name string, labels map[string]string) sarama.MetricsHistogram {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	vec, ok := s.histograms[name]
+	if !ok {
+		collector, err := register(s.registerer, prom.NewHistogramVec(prom.HistogramOpts{
+			Namespace: s.namespace,
+			Name:      metricName(name),
+			Help:      "Sarama " + name + " histogram.",
+			Buckets:   s.buckets,
+		}, labelNames(labels)))
+		// the metric is not reported if it cannot be registered
+		vec, _ = collector.(*prom.HistogramVec)
+		if vec == nil {
+			s.reportError(name, err)
+		}
+		s.histograms[name] = vec
+	}
+	if vec == nil {
+		return noop{}
+	}
+	o, err := vec.GetMetricWith(labels)
+	if err != nil {
+		s.reportError(name, err)
+		return noop{}
+	}
+	return histogram{o}
+}
+
+// reportError passes err to the error handler, unless an error of the metric
+// name was already reported.
+// s.lock must be held by caller
+func (s *Sink) reportError(name string, err error) {
+	if s.reported[name] {
+		return
+	}
+	s.reported[name] = true
+	if err == nil {
+		err = errors.New("a collector of another type is already registered")
+	}
+	s.errorHandler(fmt.Errorf("prometheus: not reporting metric %s: %w", name, err))
+}
+
+// Remove implements sarama.MetricsSinkRemover.
+func (s *Sink) Remove(name string, labels map[string]string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if vec := s.counters[name]; vec != nil {
+		vec.Delete(labels)
+	}
+	if vec := s.gauges[name]; vec != nil {
+		vec.Delete(labels)
+	}
+	if vec := s.histograms[name]; vec != nil {
+		vec.Delete(labels)
+	}
+}
+
+// register registers c, or returns the identical collector already
+// registered, e.g. by the sink of another client sharing the registerer.
+func register(registerer prom.Registerer, c prom.Collector) (prom.Collector, error) {
+	if err := registerer.Register(c); err != nil {
+		if are, ok := err.(prom.AlreadyRegisteredError); ok {
+			return are.ExistingCollector, nil
+		}
+		return nil, err
+	}
+	return c, nil
+}
+
+func metricName(name string) string {
+	return strings.NewReplacer("-", "_", ".", "_").Replace(name)
+}
+
+func labelNames(labels map[string]string) []string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+type counter struct{ prom.Counter }
+
+func (c counter) Add(delta int64) { c.Counter.Add(float64(delta)) }
+
+type gauge struct{ prom.Gauge }
+
+func (g gauge) Set(value int64) { g.Gauge.Set(float64(value)) }
+func (g gauge) Add(delta int64) { g.Gauge.Add(float64(delta)) }
+
+type histogram struct{ prom.Observer }
+
+func (h histogram) Observe(value int64) { h.Observer.Observe(float64(value)) }
+
+// noop is the instrument of the metrics that cannot be reported.
+type noop struct{}
+
+func (noop) Add(int64)     {}
+func (noop) Set(int64)     {}
+func (noop) Observe(int64) {}
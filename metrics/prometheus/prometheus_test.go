@@ -0,0 +1,74 @@
+package prometheus
+
+import (
+	"strings"
+	"testing"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestSink(t *testing.T) {
+	registry := prom.NewRegistry()
+	sink := NewSink(registry, "sarama")
+	sink.Counter("request-rate", map[string]string{"broker": "1"}).Add(2)
+	sink.Counter("request-rate", map[string]string{"broker": "2"}).Add(1)
+	inFlight := sink.Gauge("requests-in-flight", map[string]string{"broker": "1"})
+	inFlight.Add(3)
+	inFlight.Add(-1)
+	sink.Histogram("batch-size", map[string]string{"topic": "my.topic"}).Observe(100)
+	sink.Gauge("records-lag", map[string]string{"topic": "t", "partition": "0"}).Set(5)
+	sink.Remove("records-lag", map[string]string{"topic": "t", "partition": "0"})
+
+	// another sink sharing the registry reuses the collectors
+	NewSink(registry, "sarama").Counter("request-rate", map[string]string{"broker": "1"}).Add(1)
+
+	expected := `
+# HELP sarama_request_rate_total Sarama request-rate meter.
+# TYPE sarama_request_rate_total counter
+sarama_request_rate_total{broker="1"} 3
+sarama_request_rate_total{broker="2"} 1
+# HELP sarama_requests_in_flight Sarama requests-in-flight gauge.
+# TYPE sarama_requests_in_flight gauge
+sarama_requests_in_flight{broker="1"} 2
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected),
+		"sarama_request_rate_total", "sarama_requests_in_flight"); err != nil {
+		t.Error(err)
+	}
+	if n := testutil.CollectAndCount(registry, "sarama_records_lag"); n != 0 {
+		t.Errorf("expected the removed records lag not to be collected, got %d", n)
+	}
+	if n := testutil.CollectAndCount(registry, "sarama_batch_size"); n != 1 {
+		t.Errorf("expected one batch size histogram, got %d", n)
+	}
+}
+
+func TestSinkErrors(t *testing.T) {
+	registry := prom.NewRegistry()
+	// a collector of the user clashing with a metric of Sarama
+	registry.MustRegister(prom.NewGauge(prom.GaugeOpts{Name: "sarama_request_rate_total", Help: "Not Sarama's."}))
+
+	var errs []error
+	sink := NewSink(registry, "sarama").WithErrorHandler(func(err error) { errs = append(errs, err) })
+	for range 2 {
+		sink.Counter("request-rate", map[string]string{"broker": "1"}).Add(1)
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "request-rate") {
+		t.Errorf("expected the registration error to be reported once, got %v", errs)
+	}
+	sink.Remove("request-rate", map[string]string{"broker": "1"})
+
+	// a metric reported with other labels than it was registered with
+	sink.Gauge("records-lag", map[string]string{"topic": "t", "partition": "0"}).Set(5)
+	for range 2 {
+		sink.Gauge("records-lag", map[string]string{"topic": "t"}).Set(1)
+	}
+	if len(errs) != 2 || !strings.Contains(errs[1].Error(), "records-lag") {
+		t.Errorf("expected the label error to be reported once, got %v", errs)
+	}
+	sink.Gauge("records-lag", map[string]string{"topic": "t", "partition": "1"}).Set(2)
+	if n := testutil.CollectAndCount(registry, "sarama_records_lag"); n != 2 {
+		t.Errorf("expected the records lag with the registered labels to be collected, got %d", n)
+	}
+}
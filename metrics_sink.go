@@ -0,0 +1,158 @@
+package sarama
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/rcrowley/go-metrics"
+)
+
+// Labels of the metrics reported to a MetricsSink. The same labels are
+// appended to the names of the metrics registered in Config.MetricRegistry
+// (e.g. "request-rate-for-broker-1").
+const (
+	MetricLabelBroker    = "broker"
+	MetricLabelTopic     = "topic"
+	MetricLabelPartition = "partition"
+	MetricLabelGroup     = "group"
+	MetricLabelAPIKey    = "api_key"
+)
+
+// MetricsSink is the interface to a metrics backend, such as Prometheus or
+// OpenTelemetry, that Sarama reports its metrics to when it is set as
+// Config.MetricsSink, instead of registering them in Config.MetricRegistry.
+//
+// The metrics are named as in the go-metrics registry (e.g. "request-rate")
+// but the broker, topic, partition, consumer group and request API key they
+// relate to are given as labels rather than as name suffixes, and the metrics
+// aggregating them (e.g. the request-rate of all brokers) are not reported.
+// Metrics of the same name always have the same labels, which is why the
+// per-topic consumer-fetch-rate is reported as consumer-topic-fetch-rate.
+// Instruments are requested when a metric is first updated for a set of
+// labels and may be requested again with the same name and labels, in which
+// case the sink is expected to return the same instrument.
+// Implementations must be safe for concurrent use.
+type MetricsSink interface {
+	// Counter returns a monotonic counter, used for the go-metrics meters.
+	Counter(name string, labels map[string]string) MetricsCounter
+	// Gauge returns a gauge, used for the go-metrics counters and gauges.
+	Gauge(name string, labels map[string]string) MetricsGauge
+	// Histogram returns a histogram, used for the go-metrics histograms.
+	Histogram(name string, labels map[string]string) MetricsHistogram
+}
+
+// MetricsSinkRemover is a MetricsSink that can remove the metric of a given
+// name and labels, e.g. the records-lag of a partition no longer consumed.
+type MetricsSinkRemover interface {
+	MetricsSink
+	Remove(name string, labels map[string]string)
+}
+
+// MetricsCounter is a monotonic counter of a MetricsSink.
+type MetricsCounter interface {
+	Add(delta int64)
+}
+
+// MetricsGauge is a gauge of a MetricsSink, that is either set to a value or
+// incremented and decremented.
+type MetricsGauge interface {
+	Set(value int64)
+	Add(delta int64)
+}
+
+// MetricsHistogram is a histogram of a MetricsSink.
+type MetricsHistogram interface {
+	Observe(value int64)
+}
+
+// metricLabel is a label of a metric reported to a MetricsSink, and the
+// suffix it appends to the name of the metric registered in a go-metrics
+// registry.
+type metricLabel struct {
+	key, value, suffix string
+}
+
+func brokerMetricLabel(id int32) metricLabel {
+	// Use broker id like the Java client as it does not contain '.' or ':' characters that
+	// can be interpreted as special character by monitoring tool (e.g. Graphite)
+	value := strconv.FormatInt(int64(id), 10)
+	return metricLabel{key: MetricLabelBroker, value: value, suffix: "-for-broker-" + value}
+}
+
+func topicMetricLabel(topic string) metricLabel {
+	// Convert dot to _ since reporters like Graphite typically use dot to represent hierarchy
+	// cf. KAFKA-1902 and KAFKA-2337
+	return metricLabel{key: MetricLabelTopic, value: topic, suffix: "-for-topic-" + strings.ReplaceAll(topic, ".", "_")}
+}
+
+func partitionMetricLabel(partition int32) metricLabel {
+	value := strconv.FormatInt(int64(partition), 10)
+	return metricLabel{key: MetricLabelPartition, value: value, suffix: "-for-partition-" + value}
+}
+
+func groupMetricLabel(group string) metricLabel {
+	return metricLabel{key: MetricLabelGroup, value: group, suffix: "-" + group}
+}
+
+func apiKeyMetricLabel(key int16) metricLabel {
+	value := strconv.FormatInt(int64(key), 10)
+	return metricLabel{key: MetricLabelAPIKey, value: value, suffix: "-" + value}
+}
+
+func metricName(name string, labels []metricLabel) string {
+	for _, label := range labels {
+		name += label.suffix
+	}
+	return name
+}
+
+func metricLabels(labels []metricLabel) map[string]string {
+	m := make(map[string]string, len(labels))
+	for _, label := range labels {
+		m[label.key] = label.value
+	}
+	return m
+}
+
+// metricsSinkOf returns the MetricsSink the metrics of r are reported to, if
+// any.
+func metricsSinkOf(r metrics.Registry) MetricsSink {
+	if r, ok := r.(*cleanupRegistry); ok {
+		return r.sink
+	}
+	return nil
+}
+
+// The following types adapt the instruments of a MetricsSink to the
+// go-metrics interfaces used throughout Sarama. Only the update methods are
+// forwarded, reading a metric back is left to the sink.
+
+type sinkMeter struct {
+	metrics.NilMeter
+	counter MetricsCounter
+}
+
+func (m sinkMeter) Mark(n int64) { m.counter.Add(n) }
+
+type sinkCounter struct {
+	metrics.NilCounter
+	gauge MetricsGauge
+}
+
+func (c sinkCounter) Inc(i int64) { c.gauge.Add(i) }
+func (c sinkCounter) Dec(i int64) { c.gauge.Add(-i) }
+func (c sinkCounter) Clear()      { c.gauge.Set(0) }
+
+type sinkGauge struct {
+	metrics.NilGauge
+	gauge MetricsGauge
+}
+
+func (g sinkGauge) Update(v int64) { g.gauge.Set(v) }
+
+type sinkHistogram struct {
+	metrics.NilHistogram
+	histogram MetricsHistogram
+}
+
+func (h sinkHistogram) Update(v int64) { h.histogram.Observe(v) }
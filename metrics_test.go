@@ -3,6 +3,9 @@
 package sarama
 
 import (
+	"sort"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/rcrowley/go-metrics"
@@ -62,3 +65,144 @@ func Benchmark_getMetricNameForBroker(b *testing.B) {
 		}
 	}
 }
+
+type testMetricsSink struct {
+	mu     sync.Mutex
+	values map[string]int64
+}
+
+func newTestMetricsSink() *testMetricsSink {
+	return &testMetricsSink{values: map[string]int64{}}
+}
+
+// key identifies a metric by its name and labels, e.g. "request-rate{broker=1}".
+func (s *testMetricsSink) key(name string, labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k, v := range labels {
+		keys = append(keys, k+"="+v)
+	}
+	sort.Strings(keys)
+	return name + "{" + strings.Join(keys, ",") + "}"
+}
+
+func (s *testMetricsSink) value(key string) (int64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.values[key]
+	return v, ok
+}
+
+func (s *testMetricsSink) update(key string, fn func(int64) int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = fn(s.values[key])
+}
+
+func (s *testMetricsSink) Counter(name string, labels map[string]string) MetricsCounter {
+	return testMetricsInstrument{s, s.key(name, labels)}
+}
+
+func (s *testMetricsSink) Gauge(name string, labels map[string]string) MetricsGauge {
+	return testMetricsInstrument{s, s.key(name, labels)}
+}
+
+func (s *testMetricsSink) Histogram(name string, labels map[string]string) MetricsHistogram {
+	return testMetricsInstrument{s, s.key(name, labels)}
+}
+
+// testMetricsInstrument keeps the sum of the values of counters and
+// histograms, and the value of gauges.
+type testMetricsInstrument struct {
+	sink *testMetricsSink
+	key  string
+}
+
+func (i testMetricsInstrument) Add(delta int64) {
+	i.sink.update(i.key, func(v int64) int64 { return v + delta })
+}
+
+func (i testMetricsInstrument) Set(value int64) {
+	i.sink.update(i.key, func(int64) int64 { return value })
+}
+
+func (i testMetricsInstrument) Observe(value int64) { i.Add(value) }
+
+func TestGetOrRegisterLabelledMetrics(t *testing.T) {
+	metricRegistry := metrics.NewRegistry()
+	registry := newCleanupRegistry(metricRegistry)
+	getOrRegisterMeter("rate", registry, apiKeyMetricLabel(3), brokerMetricLabel(1)).Mark(2)
+	getOrRegisterAggregateMeter("rate", registry).Mark(1)
+	if meter, ok := metricRegistry.Get("rate-3-for-broker-1").(metrics.Meter); !ok || meter.Count() != 2 {
+		t.Error("Unexpected labelled meter", meter)
+	}
+	if meter, ok := metricRegistry.Get("rate").(metrics.Meter); !ok || meter.Count() != 1 {
+		t.Error("Unexpected aggregate meter", meter)
+	}
+
+	sink := newTestMetricsSink()
+	conf := NewTestConfig()
+	conf.MetricRegistry = metrics.NewRegistry()
+	conf.MetricsSink = sink
+	registry = newMetricRegistry(conf)
+	getOrRegisterMeter("rate", registry, apiKeyMetricLabel(3), brokerMetricLabel(1)).Mark(2)
+	getOrRegisterAggregateMeter("rate", registry).Mark(1)
+	getOrRegisterTopicHistogram("size", "says.hello", registry).Update(10)
+	counter := getOrRegisterCounter("in-flight", registry, brokerMetricLabel(1))
+	counter.Inc(3)
+	counter.Dec(1)
+	getOrRegisterGauge("expiry", registry).Update(42)
+
+	for key, expected := range map[string]int64{
+		"rate{api_key=3,broker=1}": 2,
+		"size{topic=says.hello}":   10,
+		"in-flight{broker=1}":      2,
+		"expiry{}":                 42,
+	} {
+		if v, ok := sink.value(key); !ok || v != expected {
+			t.Errorf("Expected %s to be %d, got %d", key, expected, v)
+		}
+	}
+	if _, ok := sink.value("rate{}"); ok {
+		t.Error("Expected aggregate metric not to be reported to the sink")
+	}
+	if n := len(conf.MetricRegistry.GetAll()); n != 0 {
+		t.Errorf("Expected no metric in the registry, got %d", n)
+	}
+}
+
+func TestBrokerMetricsSink(t *testing.T) {
+	mockBroker := NewMockBroker(t, 0)
+	defer mockBroker.Close()
+	mockBroker.SetHandlerByMap(map[string]MockResponse{
+		"MetadataRequest": NewMockMetadataResponse(t),
+	})
+
+	sink := newTestMetricsSink()
+	conf := NewTestConfig()
+	conf.MetricsSink = sink
+	broker := NewBroker(mockBroker.Addr())
+	if err := broker.Open(conf); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := broker.GetMetadata(&MetadataRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	safeClose(t, broker)
+
+	// seed brokers are reported to the sink with their id
+	for _, key := range []string{
+		"request-rate{broker=-1}",
+		"response-rate{broker=-1}",
+		"protocol-requests-rate{api_key=3,broker=-1}",
+	} {
+		if v, ok := sink.value(key); !ok || v != 1 {
+			t.Errorf("Expected %s to be 1, got %d", key, v)
+		}
+	}
+	if v, _ := sink.value("requests-in-flight{broker=-1}"); v != 0 {
+		t.Errorf("Expected no request in flight, got %d", v)
+	}
+	if n := len(conf.MetricRegistry.GetAll()); n != 0 {
+		t.Errorf("Expected no metric in the registry, got %d", n)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 )
 
 // TestReporter has methods matching go's testing.T to avoid importing
@@ -132,6 +133,45 @@ func (m *MockDescribeGroupsResponse) For(reqBody versionedDecoder) encoderWithHe
 	return response
 }
 
+// MockConsumerGroupDescribeResponse is a `ConsumerGroupDescribeResponse` builder.
+type MockConsumerGroupDescribeResponse struct {
+	groups map[string]*ConsumerGroupDescribeGroup
+	t      TestReporter
+}
+
+func NewMockConsumerGroupDescribeResponse(t TestReporter) *MockConsumerGroupDescribeResponse {
+	return &MockConsumerGroupDescribeResponse{
+		t:      t,
+		groups: make(map[string]*ConsumerGroupDescribeGroup),
+	}
+}
+
+func (m *MockConsumerGroupDescribeResponse) AddGroup(group *ConsumerGroupDescribeGroup) *MockConsumerGroupDescribeResponse {
+	m.groups[group.GroupId] = group
+	return m
+}
+
+func (m *MockConsumerGroupDescribeResponse) For(reqBody versionedDecoder) encoderWithHeader {
+	request := reqBody.(*ConsumerGroupDescribeRequest)
+
+	response := &ConsumerGroupDescribeResponse{Version: request.version()}
+	for _, requestedGroup := range request.GroupIds {
+		if group, ok := m.groups[requestedGroup]; ok {
+			response.Groups = append(response.Groups, *group)
+		} else {
+			// Mimic real kafka - classic and unknown groups are reported as not found
+			msg := fmt.Sprintf("Group %s not found.", requestedGroup)
+			response.Groups = append(response.Groups, ConsumerGroupDescribeGroup{
+				ErrorCode:    ErrGroupIDNotFound,
+				ErrorMessage: &msg,
+				GroupId:      requestedGroup,
+			})
+		}
+	}
+
+	return response
+}
+
 // MockMetadataResponse is a `MetadataResponse` builder.
 type MockMetadataResponse struct {
 	controllerID int32
@@ -660,7 +700,8 @@ func (mr *MockOffsetFetchResponse) For(reqBody versionedDecoder) encoderWithHead
 }
 
 type MockCreateTopicsResponse struct {
-	t TestReporter
+	t      TestReporter
+	errors map[string]KError
 }
 
 func NewMockCreateTopicsResponse(t TestReporter) *MockCreateTopicsResponse {
@@ -673,8 +714,22 @@ func (mr *MockCreateTopicsResponse) For(reqBody versionedDecoder) encoderWithHea
 		Version: req.Version,
 	}
 	res.TopicErrors = make(map[string]*TopicError)
+	if res.Version >= 5 {
+		res.TopicResults = make(map[string]*CreatableTopicResult)
+	}
 
-	for topic := range req.TopicDetails {
+	for topic, detail := range req.TopicDetails {
+		if res.Version >= 5 {
+			res.TopicResults[topic] = &CreatableTopicResult{
+				TopicConfigErrorCode: ErrNoError,
+				NumPartitions:        -1,
+				ReplicationFactor:    -1,
+			}
+		}
+		if kerr, ok := mr.errors[topic]; ok {
+			res.TopicErrors[topic] = &TopicError{Err: kerr}
+			continue
+		}
 		if res.Version >= 1 && strings.HasPrefix(topic, "_") {
 			msg := "insufficient permissions to create topic with reserved prefix"
 			res.TopicErrors[topic] = &TopicError{
@@ -684,10 +739,30 @@ func (mr *MockCreateTopicsResponse) For(reqBody versionedDecoder) encoderWithHea
 			continue
 		}
 		res.TopicErrors[topic] = &TopicError{Err: ErrNoError}
+		if res.Version >= 5 {
+			result := res.TopicResults[topic]
+			result.NumPartitions = detail.NumPartitions
+			result.ReplicationFactor = detail.ReplicationFactor
+			result.Configs = make(map[string]*CreatableTopicConfigs, len(detail.ConfigEntries))
+			for name, value := range detail.ConfigEntries {
+				result.Configs[name] = &CreatableTopicConfigs{
+					Value:        value,
+					ConfigSource: SourceTopic,
+				}
+			}
+		}
 	}
 	return res
 }
 
+func (mr *MockCreateTopicsResponse) SetError(topic string, kerror KError) *MockCreateTopicsResponse {
+	if mr.errors == nil {
+		mr.errors = make(map[string]KError)
+	}
+	mr.errors[topic] = kerror
+	return mr
+}
+
 type MockDeleteTopicsResponse struct {
 	t     TestReporter
 	error KError
@@ -1454,8 +1529,11 @@ func (m *MockDescribeLogDirsResponse) For(reqBody versionedDecoder) encoderWithH
 }
 
 type MockApiVersionsResponse struct {
-	t       TestReporter
-	apiKeys []ApiVersionsResponseKey
+	t                 TestReporter
+	apiKeys           []ApiVersionsResponseKey
+	supportedFeatures []ApiVersionsResponseSupportedFeatureKey
+	finalizedFeatures []ApiVersionsResponseFinalizedFeatureKey
+	featuresEpoch     int64
 }
 
 func NewMockApiVersionsResponse(t TestReporter) *MockApiVersionsResponse {
@@ -1473,6 +1551,7 @@ func NewMockApiVersionsResponse(t TestReporter) *MockApiVersionsResponse {
 				MaxVersion: 11,
 			},
 		},
+		featuresEpoch: -1,
 	}
 }
 
@@ -1481,11 +1560,155 @@ func (m *MockApiVersionsResponse) SetApiKeys(apiKeys []ApiVersionsResponseKey) *
 	return m
 }
 
+func (m *MockApiVersionsResponse) SetSupportedFeatures(features []ApiVersionsResponseSupportedFeatureKey) *MockApiVersionsResponse {
+	m.supportedFeatures = features
+	return m
+}
+
+func (m *MockApiVersionsResponse) SetFinalizedFeatures(epoch int64, features []ApiVersionsResponseFinalizedFeatureKey) *MockApiVersionsResponse {
+	m.featuresEpoch = epoch
+	m.finalizedFeatures = features
+	return m
+}
+
 func (m *MockApiVersionsResponse) For(reqBody versionedDecoder) encoderWithHeader {
 	req := reqBody.(*ApiVersionsRequest)
 	res := &ApiVersionsResponse{
+		Version:                req.Version,
+		ApiKeys:                m.apiKeys,
+		SupportedFeatures:      m.supportedFeatures,
+		FinalizedFeaturesEpoch: m.featuresEpoch,
+		FinalizedFeatures:      m.finalizedFeatures,
+	}
+	return res
+}
+
+// MockDescribeClusterResponse is a `DescribeClusterResponse` builder.
+type MockDescribeClusterResponse struct {
+	t            TestReporter
+	clusterID    string
+	controllerID int32
+	brokers      []*Broker
+}
+
+func NewMockDescribeClusterResponse(t TestReporter) *MockDescribeClusterResponse {
+	return &MockDescribeClusterResponse{t: t, controllerID: -1}
+}
+
+func (m *MockDescribeClusterResponse) SetClusterID(clusterID string) *MockDescribeClusterResponse {
+	m.clusterID = clusterID
+	return m
+}
+
+func (m *MockDescribeClusterResponse) SetController(brokerID int32) *MockDescribeClusterResponse {
+	m.controllerID = brokerID
+	return m
+}
+
+func (m *MockDescribeClusterResponse) SetBroker(addr string, brokerID int32) *MockDescribeClusterResponse {
+	m.brokers = append(m.brokers, &Broker{id: brokerID, addr: addr})
+	return m
+}
+
+func (m *MockDescribeClusterResponse) For(reqBody versionedDecoder) encoderWithHeader {
+	req := reqBody.(*DescribeClusterRequest)
+	return &DescribeClusterResponse{
+		Version:      req.Version,
+		EndpointType: req.EndpointType,
+		ClusterID:    m.clusterID,
+		ControllerID: m.controllerID,
+		Brokers:      m.brokers,
+	}
+}
+
+// MockDescribeQuorumResponse is a `DescribeQuorumResponse` builder.
+type MockDescribeQuorumResponse struct {
+	t         TestReporter
+	partition DescribeQuorumPartitionData
+}
+
+func NewMockDescribeQuorumResponse(t TestReporter) *MockDescribeQuorumResponse {
+	return &MockDescribeQuorumResponse{
+		t:         t,
+		partition: DescribeQuorumPartitionData{LeaderID: -1},
+	}
+}
+
+func (m *MockDescribeQuorumResponse) SetLeader(leaderID, leaderEpoch int32, highWatermark int64) *MockDescribeQuorumResponse {
+	m.partition.LeaderID = leaderID
+	m.partition.LeaderEpoch = leaderEpoch
+	m.partition.HighWatermark = highWatermark
+	return m
+}
+
+func (m *MockDescribeQuorumResponse) AddVoter(replicaID int32, logEndOffset int64) *MockDescribeQuorumResponse {
+	m.partition.CurrentVoters = append(m.partition.CurrentVoters, DescribeQuorumReplicaState{
+		ReplicaID:             replicaID,
+		LogEndOffset:          logEndOffset,
+		LastFetchTimestamp:    -1,
+		LastCaughtUpTimestamp: -1,
+	})
+	return m
+}
+
+func (m *MockDescribeQuorumResponse) AddObserver(replicaID int32, logEndOffset int64) *MockDescribeQuorumResponse {
+	m.partition.Observers = append(m.partition.Observers, DescribeQuorumReplicaState{
+		ReplicaID:             replicaID,
+		LogEndOffset:          logEndOffset,
+		LastFetchTimestamp:    -1,
+		LastCaughtUpTimestamp: -1,
+	})
+	return m
+}
+
+func (m *MockDescribeQuorumResponse) For(reqBody versionedDecoder) encoderWithHeader {
+	req := reqBody.(*DescribeQuorumRequest)
+	return &DescribeQuorumResponse{
 		Version: req.Version,
-		ApiKeys: m.apiKeys,
+		Topics: []DescribeQuorumTopicData{
+			{
+				TopicName:  clusterMetadataTopic,
+				Partitions: []DescribeQuorumPartitionData{m.partition},
+			},
+		},
+	}
+}
+
+// MockUpdateFeaturesResponse is an `UpdateFeaturesResponse` builder.
+type MockUpdateFeaturesResponse struct {
+	t      TestReporter
+	err    KError
+	errors map[string]KError
+}
+
+func NewMockUpdateFeaturesResponse(t TestReporter) *MockUpdateFeaturesResponse {
+	return &MockUpdateFeaturesResponse{t: t}
+}
+
+func (m *MockUpdateFeaturesResponse) SetError(err KError) *MockUpdateFeaturesResponse {
+	m.err = err
+	return m
+}
+
+func (m *MockUpdateFeaturesResponse) SetFeatureError(feature string, err KError) *MockUpdateFeaturesResponse {
+	if m.errors == nil {
+		m.errors = make(map[string]KError)
+	}
+	m.errors[feature] = err
+	return m
+}
+
+func (m *MockUpdateFeaturesResponse) For(reqBody versionedDecoder) encoderWithHeader {
+	req := reqBody.(*UpdateFeaturesRequest)
+	res := &UpdateFeaturesResponse{
+		Version:   req.Version,
+		ErrorCode: m.err,
+	}
+	for _, update := range req.FeatureUpdates {
+		res.Results = append(res.Results, UpdatableFeatureResult{
+			Feature:   update.Feature,
+			ErrorCode: m.errors[update.Feature],
+		})
 	}
 	return res
 }
@@ -1529,3 +1752,169 @@ func (m *MockInitProducerIDResponse) For(reqBody versionedDecoder) encoderWithHe
 	}
 	return res
 }
+
+// MockGetTelemetrySubscriptionsResponse is a `GetTelemetrySubscriptionsResponse` builder.
+type MockGetTelemetrySubscriptionsResponse struct {
+	t                TestReporter
+	err              KError
+	clientInstanceId Uuid
+	subscriptionId   int32
+	pushInterval     time.Duration
+	deltaTemporality bool
+	requestedMetrics []string
+}
+
+func NewMockGetTelemetrySubscriptionsResponse(t TestReporter) *MockGetTelemetrySubscriptionsResponse {
+	return &MockGetTelemetrySubscriptionsResponse{t: t, pushInterval: defaultTelemetryPushInterval}
+}
+
+func (m *MockGetTelemetrySubscriptionsResponse) SetError(err KError) *MockGetTelemetrySubscriptionsResponse {
+	m.err = err
+	return m
+}
+
+func (m *MockGetTelemetrySubscriptionsResponse) SetClientInstanceId(id Uuid) *MockGetTelemetrySubscriptionsResponse {
+	m.clientInstanceId = id
+	return m
+}
+
+func (m *MockGetTelemetrySubscriptionsResponse) SetSubscription(id int32, pushInterval time.Duration, deltaTemporality bool, requestedMetrics ...string) *MockGetTelemetrySubscriptionsResponse {
+	m.subscriptionId = id
+	m.pushInterval = pushInterval
+	m.deltaTemporality = deltaTemporality
+	m.requestedMetrics = requestedMetrics
+	return m
+}
+
+func (m *MockGetTelemetrySubscriptionsResponse) For(reqBody versionedDecoder) encoderWithHeader {
+	req := reqBody.(*GetTelemetrySubscriptionsRequest)
+	clientInstanceId := req.ClientInstanceId
+	if clientInstanceId == (Uuid{}) {
+		clientInstanceId = m.clientInstanceId
+	}
+	return &GetTelemetrySubscriptionsResponse{
+		Version:                  req.Version,
+		Err:                      m.err,
+		ClientInstanceId:         clientInstanceId,
+		SubscriptionId:           m.subscriptionId,
+		AcceptedCompressionTypes: []CompressionCodec{CompressionNone},
+		PushInterval:             m.pushInterval,
+		TelemetryMaxBytes:        1024 * 1024,
+		DeltaTemporality:         m.deltaTemporality,
+		RequestedMetrics:         m.requestedMetrics,
+	}
+}
+
+// MockPushTelemetryResponse is a `PushTelemetryResponse` builder.
+type MockPushTelemetryResponse struct {
+	t   TestReporter
+	err KError
+}
+
+func NewMockPushTelemetryResponse(t TestReporter) *MockPushTelemetryResponse {
+	return &MockPushTelemetryResponse{t: t}
+}
+
+func (m *MockPushTelemetryResponse) SetError(err KError) *MockPushTelemetryResponse {
+	m.err = err
+	return m
+}
+
+func (m *MockPushTelemetryResponse) For(reqBody versionedDecoder) encoderWithHeader {
+	req := reqBody.(*PushTelemetryRequest)
+	return &PushTelemetryResponse{
+		Version: req.Version,
+		Err:     m.err,
+	}
+}
+
+// MockCaptureResponse is a MockResponse replaying the responses of a
+// capture: each request is answered with the captured response to the next
+// captured request of the same API key, as it was read off the wire, or not
+// answered if that request was not.
+type MockCaptureResponse struct {
+	t TestReporter
+	// responses holds the captured responses in the order of their requests,
+	// nil for the requests without response
+	responses map[int16][]*CaptureRecord
+}
+
+// NewMockCaptureResponse returns a MockCaptureResponse replaying the
+// responses of records. The requests of records tell which requests were not
+// answered, the responses without captured request are replayed in order.
+func NewMockCaptureResponse(t TestReporter, records []*CaptureRecord) *MockCaptureResponse {
+	m := &MockCaptureResponse{t: t, responses: make(map[int16][]*CaptureRecord)}
+	// the index of the requests awaiting their response, correlation ids
+	// being unique within a connection
+	type requestID struct {
+		connection    int64
+		correlationID int32
+	}
+	pending := make(map[requestID]int)
+	for _, record := range records {
+		id := requestID{record.ConnectionID, record.CorrelationID}
+		responses := m.responses[record.APIKey]
+		if !record.Response {
+			pending[id] = len(responses)
+			m.responses[record.APIKey] = append(responses, nil)
+			continue
+		}
+		if i, ok := pending[id]; ok {
+			delete(pending, id)
+			responses[i] = record
+			continue
+		}
+		m.responses[record.APIKey] = append(responses, record)
+	}
+	return m
+}
+
+func (m *MockCaptureResponse) For(reqBody versionedDecoder) encoderWithHeader {
+	req := reqBody.(protocolBody)
+	responses := m.responses[req.key()]
+	if produce, ok := req.(*ProduceRequest); ok && produce.RequiredAcks == NoResponse {
+		if len(responses) > 0 && responses[0] == nil {
+			m.responses[req.key()] = responses[1:]
+		}
+		return nil
+	}
+	if len(responses) == 0 {
+		m.t.Errorf("no captured response left for %T", req)
+		return nil
+	}
+	record := responses[0]
+	m.responses[req.key()] = responses[1:]
+	if record == nil {
+		// the captured request was not answered
+		return nil
+	}
+
+	res := allocateResponseBody(record.APIKey, record.APIVersion)
+	if res == nil {
+		m.t.Errorf("unknown captured response key %d", record.APIKey)
+		return nil
+	}
+	if record.APIVersion != req.version() {
+		m.t.Errorf("%T version %d replayed with a response of version %d", req, req.version(), record.APIVersion)
+	}
+	headerLength := int(getHeaderLength(res.headerVersion())) - 4
+	if len(record.Frame) < headerLength {
+		m.t.Errorf("captured response to %T shorter than its header (%d bytes)", req, len(record.Frame))
+		return nil
+	}
+	return &capturedResponse{body: record.Frame[headerLength:], header: res.headerVersion()}
+}
+
+// capturedResponse is the body of a captured response, encoded as is.
+type capturedResponse struct {
+	body   []byte
+	header int16
+}
+
+func (r *capturedResponse) encode(pe packetEncoder) error {
+	return pe.putRawBytes(r.body)
+}
+
+func (r *capturedResponse) headerVersion() int16 {
+	return r.header
+}
@@ -0,0 +1,329 @@
+package sarama
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInvalidAccessToken is returned when a token issued by the token endpoint
+// fails validation.
+var ErrInvalidAccessToken = errors.New("kafka: invalid OAuth access token")
+
+// ClientCredentialsConfig configures a ClientCredentialsTokenProvider.
+type ClientCredentialsConfig struct {
+	// TokenURL is the URL of the OAuth 2.0 token endpoint.
+	TokenURL string
+	// ClientID and ClientSecret are sent to the token endpoint using HTTP
+	// basic authentication.
+	ClientID     string
+	ClientSecret string
+	// Scopes are requested with the scope parameter, if any.
+	Scopes []string
+	// EndpointParams contains additional form parameters sent to the token
+	// endpoint, such as an audience.
+	EndpointParams url.Values
+	// Extensions are attached to every token and sent with the SASL/OAUTHBEARER
+	// initial client response.
+	Extensions map[string]string
+	// HTTPClient is used to reach the token endpoint (defaults to
+	// http.DefaultClient).
+	HTTPClient *http.Client
+	// Timeout bounds a single call to the token endpoint (defaults to 10s).
+	Timeout time.Duration
+	// RefreshWindowFactor is the fraction of the token lifetime after which a
+	// new token is fetched in the background (defaults to 0.8).
+	RefreshWindowFactor float64
+	// RetryBackoff is the initial wait after a failed token request, doubled
+	// after each further failure up to RetryBackoffMax (defaults to 100ms and
+	// 10s).
+	RetryBackoff    time.Duration
+	RetryBackoffMax time.Duration
+	// SkipTokenValidation disables the JWT checks of issued tokens, for token
+	// endpoints that issue opaque tokens. The token lifetime is then taken
+	// from the expires_in field of the token response.
+	SkipTokenValidation bool
+	// Logger receives the failures of the background refreshes (defaults to
+	// the global Logger).
+	Logger StructuredLogger
+}
+
+// ClientCredentialsTokenProvider is an AccessTokenProvider that fetches
+// tokens from an OAuth 2.0 token endpoint using the client credentials grant.
+// Tokens are cached and refreshed in the background once RefreshWindowFactor
+// of their lifetime has passed; failed refreshes are retried with exponential
+// backoff while the cached token remains valid.
+//
+// Unless SkipTokenValidation is set, issued tokens must be JWTs carrying an
+// `exp` claim in the future and a non-empty `sub` claim.
+type ClientCredentialsTokenProvider struct {
+	conf ClientCredentialsConfig
+	now  func() time.Time
+
+	lock     sync.Mutex
+	token    *AccessToken
+	expiry   time.Time
+	backoff  time.Duration
+	lastErr  error
+	retryAt  time.Time
+	timer    *time.Timer
+	closed   bool
+	fetching *tokenFetch // the call to the token endpoint in flight, if any
+}
+
+// tokenFetch is a call to the token endpoint, shared by the callers needing
+// a new token while it is in flight.
+type tokenFetch struct {
+	done chan none
+	err  error
+}
+
+// NewClientCredentialsTokenProvider creates a ClientCredentialsTokenProvider
+// from conf. No token is fetched until the first call to Token.
+func NewClientCredentialsTokenProvider(conf ClientCredentialsConfig) (*ClientCredentialsTokenProvider, error) {
+	if conf.TokenURL == "" {
+		return nil, ConfigurationError("ClientCredentialsConfig.TokenURL must not be empty")
+	}
+	if conf.ClientID == "" {
+		return nil, ConfigurationError("ClientCredentialsConfig.ClientID must not be empty")
+	}
+	if _, ok := conf.Extensions[SASLExtKeyAuth]; ok {
+		return nil, ConfigurationError(fmt.Sprintf("ClientCredentialsConfig.Extensions must not contain the reserved key %s", SASLExtKeyAuth))
+	}
+	if conf.HTTPClient == nil {
+		conf.HTTPClient = http.DefaultClient
+	}
+	if conf.Timeout <= 0 {
+		conf.Timeout = 10 * time.Second
+	}
+	if conf.RefreshWindowFactor <= 0 || conf.RefreshWindowFactor >= 1 {
+		conf.RefreshWindowFactor = 0.8
+	}
+	if conf.RetryBackoff <= 0 {
+		conf.RetryBackoff = 100 * time.Millisecond
+	}
+	if conf.RetryBackoffMax < conf.RetryBackoff {
+		conf.RetryBackoffMax = max(10*time.Second, conf.RetryBackoff)
+	}
+	return &ClientCredentialsTokenProvider{conf: conf, now: time.Now}, nil
+}
+
+// Token returns the cached token while it is valid, even while a new one is
+// being fetched in the background, otherwise it fetches a new one from the
+// token endpoint.
+func (p *ClientCredentialsTokenProvider) Token() (*AccessToken, error) {
+	p.lock.Lock()
+	if p.closed {
+		p.lock.Unlock()
+		return nil, ErrClosedClient
+	}
+	if token := p.token; token != nil && (p.expiry.IsZero() || p.now().Before(p.expiry)) {
+		p.lock.Unlock()
+		return token, nil
+	}
+	// don't hammer the token endpoint while backing off from a failure
+	if err := p.lastErr; err != nil && p.now().Before(p.retryAt) {
+		p.lock.Unlock()
+		return nil, err
+	}
+	p.lock.Unlock()
+
+	if err := p.refresh(); err != nil {
+		return nil, err
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.token, nil
+}
+
+// Close stops the background refresh. Token returns ErrClosedClient after
+// the provider has been closed.
+func (p *ClientCredentialsTokenProvider) Close() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.closed = true
+	if p.timer != nil {
+		p.timer.Stop()
+		p.timer = nil
+	}
+	return nil
+}
+
+func (p *ClientCredentialsTokenProvider) backgroundRefresh() {
+	if err := p.refresh(); err != nil && !errors.Is(err, ErrClosedClient) {
+		(&logger{base: p.conf.Logger}).Error("Failed to refresh the OAuth access token", "err", err)
+	}
+}
+
+// refresh fetches a new token, or waits for the token fetch in flight, and
+// returns its error. p.lock is not held during the call to the token
+// endpoint, so that the cached token keeps being served meanwhile.
+func (p *ClientCredentialsTokenProvider) refresh() error {
+	p.lock.Lock()
+	if p.closed {
+		p.lock.Unlock()
+		return ErrClosedClient
+	}
+	f := p.fetching
+	if f != nil {
+		p.lock.Unlock()
+		<-f.done
+		return f.err
+	}
+	f = &tokenFetch{done: make(chan none)}
+	p.fetching = f
+	if p.timer != nil {
+		p.timer.Stop()
+		p.timer = nil
+	}
+	p.lock.Unlock()
+
+	token, lifetime, err := p.fetch()
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.fetching = nil
+	f.err = p.updateLocked(token, lifetime, err)
+	close(f.done)
+	return f.err
+}
+
+// updateLocked stores the outcome of a token fetch and schedules the next
+// refresh. On failure a retry is scheduled with exponential backoff. The
+// caller must hold p.lock.
+func (p *ClientCredentialsTokenProvider) updateLocked(token *AccessToken, lifetime time.Duration, err error) error {
+	if err != nil {
+		if p.backoff == 0 {
+			p.backoff = p.conf.RetryBackoff
+		} else {
+			p.backoff = min(2*p.backoff, p.conf.RetryBackoffMax)
+		}
+		p.lastErr = err
+		p.retryAt = p.now().Add(p.backoff)
+		if !p.closed {
+			p.timer = time.AfterFunc(p.backoff, p.backgroundRefresh)
+		}
+		return err
+	}
+
+	p.backoff = 0
+	p.lastErr = nil
+	p.token = token
+	p.expiry = time.Time{}
+	if lifetime > 0 {
+		p.expiry = p.now().Add(lifetime)
+		if p.closed {
+			return nil
+		}
+		refreshIn := time.Duration(float64(lifetime) * p.conf.RefreshWindowFactor)
+		p.timer = time.AfterFunc(refreshIn, p.backgroundRefresh)
+	}
+	return nil
+}
+
+type clientCredentialsResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+func (p *ClientCredentialsTokenProvider) fetch() (*AccessToken, time.Duration, error) {
+	form := url.Values{}
+	for k, v := range p.conf.EndpointParams {
+		form[k] = v
+	}
+	form.Set("grant_type", "client_credentials")
+	if len(p.conf.Scopes) > 0 {
+		form.Set("scope", strings.Join(p.conf.Scopes, " "))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.conf.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.conf.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(p.conf.ClientID), url.QueryEscape(p.conf.ClientSecret))
+
+	resp, err := p.conf.HTTPClient.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("oauth: token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, 0, fmt.Errorf("oauth: failed to read token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("oauth: token endpoint returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var tr clientCredentialsResponse
+	if err := json.Unmarshal(body, &tr); err != nil {
+		return nil, 0, fmt.Errorf("oauth: failed to decode token response: %w", err)
+	}
+	if tr.AccessToken == "" {
+		return nil, 0, fmt.Errorf("%w: token response without access_token", ErrInvalidAccessToken)
+	}
+
+	lifetime := time.Duration(tr.ExpiresIn) * time.Second
+	if !p.conf.SkipTokenValidation {
+		expiry, err := validateJWT(tr.AccessToken, p.now())
+		if err != nil {
+			return nil, 0, err
+		}
+		lifetime = expiry.Sub(p.now())
+	}
+
+	return &AccessToken{Token: tr.AccessToken, Extensions: p.conf.Extensions}, lifetime, nil
+}
+
+// validateJWT checks the `exp` and `sub` claims of an unsecured view of the
+// JWT payload and returns its expiry. The signature is left to the broker.
+func validateJWT(token string, now time.Time) (time.Time, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, fmt.Errorf("%w: expected a JWT with 3 parts, got %d", ErrInvalidAccessToken, len(parts))
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: malformed JWT payload: %w", ErrInvalidAccessToken, err)
+	}
+
+	var claims struct {
+		Exp *json.Number `json:"exp"`
+		Sub string       `json:"sub"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return time.Time{}, fmt.Errorf("%w: malformed JWT claims: %w", ErrInvalidAccessToken, err)
+	}
+	if claims.Exp == nil {
+		return time.Time{}, fmt.Errorf("%w: missing exp claim", ErrInvalidAccessToken)
+	}
+	exp, err := claims.Exp.Float64()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: invalid exp claim: %w", ErrInvalidAccessToken, err)
+	}
+	expiry := time.Unix(0, int64(exp*float64(time.Second)))
+	if !expiry.After(now) {
+		return time.Time{}, fmt.Errorf("%w: token expired at %s", ErrInvalidAccessToken, expiry.UTC().Format(time.RFC3339))
+	}
+	if strings.TrimSpace(claims.Sub) == "" {
+		return time.Time{}, fmt.Errorf("%w: missing sub claim", ErrInvalidAccessToken)
+	}
+	return expiry, nil
+}
@@ -0,0 +1,250 @@
+//go:build !functional
+
+package sarama
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testJWT(t *testing.T, claims map[string]any) string {
+	t.Helper()
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`))
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return header + "." + base64.RawURLEncoding.EncodeToString(payload) + ".sig"
+}
+
+type testTokenServer struct {
+	*httptest.Server
+	requests atomic.Int32
+	token    atomic.Value
+	status   atomic.Int32
+	delay    atomic.Int64
+}
+
+func newTestTokenServer(t *testing.T, token string) *testTokenServer {
+	s := &testTokenServer{}
+	s.token.Store(token)
+	s.status.Store(http.StatusOK)
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests.Add(1)
+		time.Sleep(time.Duration(s.delay.Load()))
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+		}
+		if user, pass, ok := r.BasicAuth(); !ok || user != "client" || pass != "secret" {
+			t.Errorf("unexpected client credentials %s:%s", user, pass)
+		}
+		if grant := r.PostForm.Get("grant_type"); grant != "client_credentials" {
+			t.Errorf("unexpected grant type %s", grant)
+		}
+		if status := int(s.status.Load()); status != http.StatusOK {
+			http.Error(w, `{"error":"server_error"}`, status)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token":%q,"token_type":"Bearer","expires_in":3600,"scope":%q}`,
+			s.token.Load().(string), r.PostForm.Get("scope"))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func newTestClientCredentialsProvider(t *testing.T, conf ClientCredentialsConfig) *ClientCredentialsTokenProvider {
+	t.Helper()
+	conf.ClientID = "client"
+	conf.ClientSecret = "secret"
+	provider, err := NewClientCredentialsTokenProvider(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = provider.Close() })
+	return provider
+}
+
+func TestClientCredentialsTokenProviderCachesToken(t *testing.T) {
+	jwt := testJWT(t, map[string]any{"sub": "svc", "exp": time.Now().Add(time.Hour).Unix()})
+	server := newTestTokenServer(t, jwt)
+
+	provider := newTestClientCredentialsProvider(t, ClientCredentialsConfig{
+		TokenURL:   server.URL,
+		Scopes:     []string{"kafka", "admin"},
+		Extensions: map[string]string{"logicalCluster": "lkc-1"},
+	})
+
+	for range 3 {
+		token, err := provider.Token()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token.Token != jwt {
+			t.Errorf("unexpected token %s", token.Token)
+		}
+		if token.Extensions["logicalCluster"] != "lkc-1" {
+			t.Errorf("unexpected extensions %v", token.Extensions)
+		}
+	}
+	if n := server.requests.Load(); n != 1 {
+		t.Errorf("expected a single token request, got %d", n)
+	}
+}
+
+func TestClientCredentialsTokenProviderRefreshesProactively(t *testing.T) {
+	first := testJWT(t, map[string]any{"sub": "svc", "exp": time.Now().Add(2 * time.Second).Unix()})
+	server := newTestTokenServer(t, first)
+
+	provider := newTestClientCredentialsProvider(t, ClientCredentialsConfig{
+		TokenURL:            server.URL,
+		RefreshWindowFactor: 0.05,
+	})
+	if _, err := provider.Token(); err != nil {
+		t.Fatal(err)
+	}
+
+	second := testJWT(t, map[string]any{"sub": "svc", "exp": time.Now().Add(time.Hour).Unix()})
+	server.token.Store(second)
+
+	deadline := time.Now().Add(3 * time.Second)
+	for server.requests.Load() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("token was not refreshed before expiry")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	token, err := provider.Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.Token != second {
+		t.Error("expected the refreshed token to be returned")
+	}
+}
+
+func TestClientCredentialsTokenProviderServesCachedTokenWhileRefreshing(t *testing.T) {
+	first := testJWT(t, map[string]any{"sub": "svc", "exp": time.Now().Add(10 * time.Second).Unix()})
+	server := newTestTokenServer(t, first)
+
+	provider := newTestClientCredentialsProvider(t, ClientCredentialsConfig{
+		TokenURL:            server.URL,
+		RefreshWindowFactor: 0.01,
+	})
+	if _, err := provider.Token(); err != nil {
+		t.Fatal(err)
+	}
+
+	second := testJWT(t, map[string]any{"sub": "svc", "exp": time.Now().Add(time.Hour).Unix()})
+	server.token.Store(second)
+	server.delay.Store(int64(time.Second))
+
+	deadline := time.Now().Add(3 * time.Second)
+	for server.requests.Load() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("token was not refreshed before expiry")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// the background refresh is in flight for a second
+	start := time.Now()
+	token, err := provider.Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.Token != first {
+		t.Error("expected the cached token while refreshing")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("expected the cached token without waiting for the refresh, waited %s", elapsed)
+	}
+
+	deadline = time.Now().Add(3 * time.Second)
+	for token.Token != second && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		if token, err = provider.Token(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if token.Token != second {
+		t.Error("expected the refreshed token to be returned")
+	}
+	if n := server.requests.Load(); n != 2 {
+		t.Errorf("expected 2 token requests, got %d", n)
+	}
+}
+
+func TestClientCredentialsTokenProviderValidatesClaims(t *testing.T) {
+	for name, claims := range map[string]map[string]any{
+		"expired":     {"sub": "svc", "exp": time.Now().Add(-time.Minute).Unix()},
+		"missing exp": {"sub": "svc"},
+		"missing sub": {"exp": time.Now().Add(time.Hour).Unix()},
+	} {
+		t.Run(name, func(t *testing.T) {
+			server := newTestTokenServer(t, testJWT(t, claims))
+			provider := newTestClientCredentialsProvider(t, ClientCredentialsConfig{TokenURL: server.URL})
+			if _, err := provider.Token(); !errors.Is(err, ErrInvalidAccessToken) {
+				t.Errorf("expected ErrInvalidAccessToken, got %v", err)
+			}
+		})
+	}
+
+	t.Run("opaque", func(t *testing.T) {
+		server := newTestTokenServer(t, "opaque-token")
+		provider := newTestClientCredentialsProvider(t, ClientCredentialsConfig{TokenURL: server.URL})
+		if _, err := provider.Token(); !errors.Is(err, ErrInvalidAccessToken) {
+			t.Errorf("expected ErrInvalidAccessToken, got %v", err)
+		}
+
+		provider = newTestClientCredentialsProvider(t, ClientCredentialsConfig{TokenURL: server.URL, SkipTokenValidation: true})
+		token, err := provider.Token()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token.Token != "opaque-token" {
+			t.Errorf("unexpected token %s", token.Token)
+		}
+	})
+}
+
+func TestClientCredentialsTokenProviderBacksOff(t *testing.T) {
+	server := newTestTokenServer(t, testJWT(t, map[string]any{"sub": "svc", "exp": time.Now().Add(time.Hour).Unix()}))
+	server.status.Store(http.StatusServiceUnavailable)
+
+	provider := newTestClientCredentialsProvider(t, ClientCredentialsConfig{
+		TokenURL:     server.URL,
+		RetryBackoff: time.Hour,
+	})
+
+	if _, err := provider.Token(); err == nil {
+		t.Fatal("expected an error from the token endpoint")
+	}
+	if _, err := provider.Token(); err == nil {
+		t.Fatal("expected the previous error while backing off")
+	}
+	if n := server.requests.Load(); n != 1 {
+		t.Errorf("expected a single token request while backing off, got %d", n)
+	}
+}
+
+func TestClientCredentialsTokenProviderConfig(t *testing.T) {
+	if _, err := NewClientCredentialsTokenProvider(ClientCredentialsConfig{ClientID: "client"}); err == nil {
+		t.Error("expected an error without token URL")
+	}
+	if _, err := NewClientCredentialsTokenProvider(ClientCredentialsConfig{
+		TokenURL:   "http://localhost",
+		ClientID:   "client",
+		Extensions: map[string]string{SASLExtKeyAuth: "x"},
+	}); err == nil {
+		t.Error("expected an error for the reserved auth extension")
+	}
+}
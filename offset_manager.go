@@ -45,6 +45,8 @@ type offsetManager struct {
 	closeOnce sync.Once
 	closing   chan none
 	closed    chan none
+
+	log *logger
 }
 
 // NewOffsetManagerFromClient creates a new OffsetManager from the given client.
@@ -72,6 +74,8 @@ func newOffsetManagerFromClient(group, memberID string, generation int32, client
 
 		closing: make(chan none),
 		closed:  make(chan none),
+
+		log: newLogger(conf, "group", group),
 	}
 	if conf.Consumer.Group.InstanceId != "" {
 		om.groupInstanceId = &conf.Consumer.Group.InstanceId
@@ -257,16 +261,21 @@ func (om *offsetManager) flushToBroker() {
 		return
 	}
 
+	// Commit on the connection of the admin requests, re-opened if it was reaped,
+	// like the requests sent with sendAndReceive.
+	conn := broker.connectionFor(&OffsetCommitRequest{})
+	conn.reopenIfReaped()
+
 	// Care needs to be taken to unlock this. Don't want to defer the unlock as this would
 	// cause the lock to be held while waiting for the broker to reply.
-	broker.lock.Lock()
+	conn.lock.Lock()
 	req := om.constructRequest()
 	if req == nil {
-		broker.lock.Unlock()
+		conn.lock.Unlock()
 		return
 	}
-	resp, rp, err := sendOffsetCommit(broker, req)
-	broker.lock.Unlock()
+	resp, rp, err := sendOffsetCommit(conn, req)
+	conn.lock.Unlock()
 
 	if err != nil {
 		om.handleError(err)
@@ -283,8 +292,14 @@ func (om *offsetManager) flushToBroker() {
 		return
 	}
 
-	broker.handleThrottledResponse(resp)
-	om.handleResponse(broker, req, resp)
+	conn.handleThrottledResponse(resp)
+	if committed := om.handleResponse(broker, req, resp); len(committed) > 0 {
+		for _, interceptor := range om.conf.Consumer.Interceptors {
+			if i, ok := interceptor.(ConsumerCommitInterceptor); ok {
+				safelyCommit(i, committed, om.log)
+			}
+		}
+	}
 }
 
 func sendOffsetCommit(coordinator *Broker, req *OffsetCommitRequest) (*OffsetCommitResponse, *responsePromise, error) {
@@ -369,10 +384,14 @@ func (om *offsetManager) constructRequest() *OffsetCommitRequest {
 	return nil
 }
 
-func (om *offsetManager) handleResponse(broker *Broker, req *OffsetCommitRequest, resp *OffsetCommitResponse) {
+// handleResponse updates the partition offset managers from the commit
+// response and returns the offsets that were successfully committed.
+func (om *offsetManager) handleResponse(broker *Broker, req *OffsetCommitRequest, resp *OffsetCommitResponse) map[string]map[int32]int64 {
 	om.pomsLock.RLock()
 	defer om.pomsLock.RUnlock()
 
+	committed := make(map[string]map[int32]int64)
+
 	for _, topicManagers := range om.poms {
 		for _, pom := range topicManagers {
 			if req.blocks[pom.topic] == nil || req.blocks[pom.topic][pom.partition] == nil {
@@ -395,6 +414,10 @@ func (om *offsetManager) handleResponse(broker *Broker, req *OffsetCommitRequest
 			case ErrNoError:
 				block := req.blocks[pom.topic][pom.partition]
 				pom.updateCommitted(block.offset, block.metadata)
+				if committed[pom.topic] == nil {
+					committed[pom.topic] = make(map[int32]int64)
+				}
+				committed[pom.topic][pom.partition] = block.offset
 			case ErrNotLeaderForPartition, ErrLeaderNotAvailable,
 				ErrConsumerCoordinatorNotAvailable, ErrNotCoordinatorForConsumer:
 				// not a critical error, we just need to redispatch
@@ -421,6 +444,7 @@ func (om *offsetManager) handleResponse(broker *Broker, req *OffsetCommitRequest
 			}
 		}
 	}
+	return committed
 }
 
 func (om *offsetManager) handleError(err error) {
@@ -555,6 +579,10 @@ type partitionOffsetManager struct {
 	dirty    bool
 	done     bool
 
+	// the offsets of the records dropped by the Consumer.Filter, marked once
+	// skipFrom has been marked
+	skipFrom, skipTo int64
+
 	releaseOnce sync.Once
 	errors      chan *ConsumerError
 }
@@ -584,6 +612,9 @@ func (pom *partitionOffsetManager) MarkOffset(offset int64, metadata string) {
 	pom.lock.Lock()
 	defer pom.lock.Unlock()
 
+	if offset >= pom.skipFrom && offset < pom.skipTo {
+		offset = pom.skipTo
+	}
 	if offset > pom.offset {
 		pom.offset = offset
 		pom.metadata = metadata
@@ -611,6 +642,21 @@ func (pom *partitionOffsetManager) updateCommitted(offset int64, metadata string
 	}
 }
 
+// skip records that the records from from to to were dropped by the
+// Consumer.Filter, and marks to as soon as from is marked, that is once every
+// message delivered before them has been marked. from is -1 if no message
+// has been delivered before them.
+func (pom *partitionOffsetManager) skip(from, to int64) {
+	pom.lock.Lock()
+	defer pom.lock.Unlock()
+
+	pom.skipFrom, pom.skipTo = from, to
+	if pom.offset >= from && to > pom.offset {
+		pom.offset = to
+		pom.dirty = true
+	}
+}
+
 func (pom *partitionOffsetManager) NextOffset() (int64, string) {
 	pom.lock.Lock()
 	defer pom.lock.Unlock()
@@ -652,7 +698,7 @@ func (pom *partitionOffsetManager) handleError(err error) {
 	if pom.parent.conf.Consumer.Return.Errors {
 		pom.errors <- cErr
 	} else {
-		Logger.Println(cErr)
+		pom.parent.log.Error("offsetmanager failed to manage partition offsets", "topic", pom.topic, "partition", pom.partition, "err", err)
 	}
 }
 
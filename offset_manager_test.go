@@ -338,6 +338,87 @@ func TestNewOffsetManagerOffsetsManualCommit(t *testing.T) {
 	safeClose(t, testClient)
 }
 
+func TestOffsetManagerCommitInterceptor(t *testing.T) {
+	interceptor := &lifecycleInterceptor{}
+	config := NewTestConfig()
+	config.Consumer.Offsets.AutoCommit.Enable = false
+	config.Consumer.Interceptors = []ConsumerInterceptor{interceptor}
+
+	om, testClient, broker, coordinator := initOffsetManagerWithBackoffFunc(t, 0, nil, config)
+	defer broker.Close()
+	defer coordinator.Close()
+	pom := initPartitionOffsetManager(t, om, coordinator, 5, "original_meta")
+
+	ocResponse := new(OffsetCommitResponse)
+	ocResponse.AddError("my_topic", 0, ErrNoError)
+	coordinator.Returns(ocResponse)
+
+	pom.MarkOffset(100, "modified_meta")
+	om.Commit()
+
+	if len(interceptor.commits) != 1 || interceptor.commits[0]["my_topic"][0] != 100 {
+		t.Errorf("expected the committed offset to be reported, got %v", interceptor.commits)
+	}
+
+	// nothing to commit, so nothing is reported
+	om.Commit()
+	if len(interceptor.commits) != 1 {
+		t.Errorf("expected no further commits, got %v", interceptor.commits)
+	}
+
+	safeClose(t, om)
+	safeClose(t, pom)
+	safeClose(t, testClient)
+}
+
+func TestOffsetManagerCommitReapedCoordinator(t *testing.T) {
+	config := NewTestConfig()
+	config.Consumer.Offsets.AutoCommit.Enable = false
+
+	om, testClient, broker, coordinator := initOffsetManagerWithBackoffFunc(t, 0, nil, config)
+	defer broker.Close()
+	defer coordinator.Close()
+	pom := initPartitionOffsetManager(t, om, coordinator, 5, "original_meta")
+
+	ocResponse := new(OffsetCommitResponse)
+	ocResponse.AddError("my_topic", 0, ErrNoError)
+	coordinator.Returns(ocResponse)
+
+	// the coordinator connection is reaped, as if found idle
+	conn, err := om.(*offsetManager).coordinator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.lock.Lock()
+	conn.reap()
+	conn.lock.Unlock()
+
+	pom.MarkOffset(100, "modified_meta")
+	om.Commit()
+
+	select {
+	case err := <-pom.Errors():
+		t.Errorf("Unexpected commit error %v", err)
+	default:
+	}
+	if connected, _ := conn.Connected(); !connected {
+		t.Error("Expected the commit to re-open the coordinator connection")
+	}
+	var commits int
+	for _, rr := range coordinator.History() {
+		if _, ok := rr.Request.(*OffsetCommitRequest); ok {
+			commits++
+		}
+	}
+	if commits != 1 {
+		t.Errorf("Expected the offsets to be committed, got %d commit requests", commits)
+	}
+
+	safeClose(t, om)
+	safeClose(t, pom)
+	safeClose(t, testClient)
+}
+
 // Test recovery from ErrNotCoordinatorForConsumer
 // on first fetchInitialOffset call
 func TestOffsetManagerFetchInitialFail(t *testing.T) {
@@ -553,6 +634,41 @@ func TestPartitionOffsetManagerMarkOffset(t *testing.T) {
 	safeClose(t, testClient)
 }
 
+func TestPartitionOffsetManagerSkip(t *testing.T) {
+	om, testClient, broker, coordinator := initOffsetManager(t, 0)
+	defer broker.Close()
+	defer coordinator.Close()
+	pom := initPartitionOffsetManager(t, om, coordinator, 5, "original_meta")
+
+	ocResponse := new(OffsetCommitResponse)
+	ocResponse.AddError("my_topic", 0, ErrNoError)
+	coordinator.Returns(ocResponse)
+
+	// records 10 to 19 were dropped after the messages 5 to 9 were delivered
+	pom.(*partitionOffsetManager).skip(10, 20)
+	if offset, _ := pom.NextOffset(); offset != 5 {
+		t.Errorf("Expected offset 5 until the delivered messages are marked. Actual: %v", offset)
+	}
+	pom.MarkOffset(8, "")
+	if offset, _ := pom.NextOffset(); offset != 8 {
+		t.Errorf("Expected offset 8. Actual: %v", offset)
+	}
+	pom.MarkOffset(10, "")
+	if offset, _ := pom.NextOffset(); offset != 20 {
+		t.Errorf("Expected offset 20 once the delivered messages are marked. Actual: %v", offset)
+	}
+
+	// records 20 to 29 were dropped with no messages delivered before them
+	pom.(*partitionOffsetManager).skip(20, 30)
+	if offset, _ := pom.NextOffset(); offset != 30 {
+		t.Errorf("Expected offset 30. Actual: %v", offset)
+	}
+
+	safeClose(t, pom)
+	safeClose(t, om)
+	safeClose(t, testClient)
+}
+
 func TestPartitionOffsetManagerMarkOffsetWithRetention(t *testing.T) {
 	om, testClient, broker, coordinator := initOffsetManager(t, time.Hour)
 	defer broker.Close()
@@ -6,6 +6,9 @@ import (
 	"github.com/rcrowley/go-metrics"
 )
 
+type taggedFieldEncoderFunc func(pe packetEncoder) error
+type taggedFieldEncoders map[uint64]taggedFieldEncoderFunc
+
 // PacketEncoder is the interface providing helpers for writing with Kafka's encoding rules.
 // Types implementing Encoder only need to worry about calling methods like PutString,
 // not about how a string is represented in Kafka.
@@ -81,8 +81,8 @@ func (r *ProduceRequest) encode(pe packetEncoder) error {
 	var batchSizeMetric metrics.Histogram
 	var compressionRatioMetric metrics.Histogram
 	if metricRegistry != nil {
-		batchSizeMetric = getOrRegisterHistogram("batch-size", metricRegistry)
-		compressionRatioMetric = getOrRegisterHistogram("compression-ratio", metricRegistry)
+		batchSizeMetric = getOrRegisterAggregateHistogram("batch-size", metricRegistry)
+		compressionRatioMetric = getOrRegisterAggregateHistogram("compression-ratio", metricRegistry)
 	}
 	totalRecordCount := int64(0)
 
@@ -135,8 +135,8 @@ func (r *ProduceRequest) encode(pe packetEncoder) error {
 		}
 	}
 	if totalRecordCount > 0 {
-		metrics.GetOrRegisterMeter("record-send-rate", metricRegistry).Mark(totalRecordCount)
-		getOrRegisterHistogram("records-per-request", metricRegistry).Update(totalRecordCount)
+		getOrRegisterAggregateMeter("record-send-rate", metricRegistry).Mark(totalRecordCount)
+		getOrRegisterAggregateHistogram("records-per-request", metricRegistry).Update(totalRecordCount)
 	}
 
 	return nil
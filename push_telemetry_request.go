@@ -0,0 +1,91 @@
+package sarama
+
+// PushTelemetryRequest pushes client metrics to a broker, see KIP-714.
+type PushTelemetryRequest struct {
+	// Version defines the protocol version to use for encode and decode
+	Version int16
+	// ClientInstanceId contains the unique identifier of the client
+	// instance.
+	ClientInstanceId Uuid
+	// SubscriptionId contains the identifier of the subscription the
+	// metrics are pushed for.
+	SubscriptionId int32
+	// Terminating contains whether the client is shutting down, this being
+	// its last push.
+	Terminating bool
+	// CompressionType contains the compression codec of Metrics.
+	CompressionType CompressionCodec
+	// Metrics contains the OTLP encoded metrics.
+	Metrics []byte
+}
+
+func (r *PushTelemetryRequest) setVersion(v int16) {
+	r.Version = v
+}
+
+func (r *PushTelemetryRequest) encode(pe packetEncoder) error {
+	if err := pe.putRawBytes(r.ClientInstanceId[:]); err != nil {
+		return err
+	}
+	pe.putInt32(r.SubscriptionId)
+	pe.putBool(r.Terminating)
+	pe.putInt8(int8(r.CompressionType))
+	if err := pe.putBytes(r.Metrics); err != nil {
+		return err
+	}
+	pe.putEmptyTaggedFieldArray()
+	return nil
+}
+
+func (r *PushTelemetryRequest) decode(pd packetDecoder, version int16) (err error) {
+	r.Version = version
+	id, err := pd.getRawBytes(16)
+	if err != nil {
+		return err
+	}
+	copy(r.ClientInstanceId[:], id)
+	if r.SubscriptionId, err = pd.getInt32(); err != nil {
+		return err
+	}
+	if r.Terminating, err = pd.getBool(); err != nil {
+		return err
+	}
+	codec, err := pd.getInt8()
+	if err != nil {
+		return err
+	}
+	r.CompressionType = CompressionCodec(codec)
+	if r.Metrics, err = pd.getBytes(); err != nil {
+		return err
+	}
+	_, err = pd.getEmptyTaggedFieldArray()
+	return err
+}
+
+func (r *PushTelemetryRequest) key() int16 {
+	return apiKeyPushTelemetry
+}
+
+func (r *PushTelemetryRequest) version() int16 {
+	return r.Version
+}
+
+func (r *PushTelemetryRequest) headerVersion() int16 {
+	return 2
+}
+
+func (r *PushTelemetryRequest) isValidVersion() bool {
+	return r.Version == 0
+}
+
+func (r *PushTelemetryRequest) isFlexible() bool {
+	return r.isFlexibleVersion(r.Version)
+}
+
+func (r *PushTelemetryRequest) isFlexibleVersion(version int16) bool {
+	return version >= 0
+}
+
+func (r *PushTelemetryRequest) requiredVersion() KafkaVersion {
+	return V3_7_0_0
+}
@@ -0,0 +1,26 @@
+//go:build !functional
+
+package sarama
+
+import "testing"
+
+var pushTelemetryRequestV0 = []byte{
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, // ClientInstanceId
+	0, 0, 0, 7, // SubscriptionId
+	1,          // Terminating
+	0,          // CompressionType
+	4, 1, 2, 3, // Metrics
+	0, // empty tagged fields
+}
+
+func TestPushTelemetryRequest(t *testing.T) {
+	request := &PushTelemetryRequest{
+		Version:          0,
+		ClientInstanceId: Uuid{15: 1},
+		SubscriptionId:   7,
+		Terminating:      true,
+		CompressionType:  CompressionNone,
+		Metrics:          []byte{1, 2, 3},
+	}
+	testRequest(t, "V0", request, pushTelemetryRequestV0)
+}
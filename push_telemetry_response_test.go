@@ -0,0 +1,23 @@
+//go:build !functional
+
+package sarama
+
+import (
+	"testing"
+	"time"
+)
+
+var pushTelemetryResponseV0 = []byte{
+	0, 0, 0, 100, // ThrottleTimeMs
+	0, 117, // ErrorCode
+	0, // empty tagged fields
+}
+
+func TestPushTelemetryResponse(t *testing.T) {
+	response := &PushTelemetryResponse{
+		Version:      0,
+		ThrottleTime: 100 * time.Millisecond,
+		Err:          ErrUnknownSubscriptionId,
+	}
+	testResponse(t, "V0", response, pushTelemetryResponseV0)
+}
@@ -0,0 +1,125 @@
+package reconciler
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/IBM/sarama"
+)
+
+// Plan contains the changes needed to move the cluster to the desired state,
+// in the order in which Apply executes them.
+type Plan struct {
+	TopicCreates       []TopicCreate
+	PartitionIncreases []PartitionIncrease
+	ConfigUpdates      []ConfigUpdate
+	ACLAdds            []sarama.ResourceAcls
+	ACLRemoves         []sarama.ResourceAcls
+	QuotaAlterations   []QuotaAlteration
+	// Warnings lists differences that cannot be reconciled, such as a
+	// partition count decrease or a replication factor change.
+	Warnings []string
+}
+
+// TopicCreate creates a missing topic.
+type TopicCreate struct {
+	Topic  string
+	Detail *sarama.TopicDetail
+}
+
+// PartitionIncrease grows an existing topic to Count partitions.
+type PartitionIncrease struct {
+	Topic string
+	From  int32
+	Count int32
+}
+
+// ConfigUpdate changes the config overrides of an existing topic.
+type ConfigUpdate struct {
+	Topic   string
+	Entries map[string]sarama.IncrementalAlterConfigsEntry
+}
+
+// QuotaAlteration sets or removes client quota values of an entity.
+type QuotaAlteration struct {
+	Entity []sarama.QuotaEntityComponent
+	Ops    []sarama.ClientQuotasOp
+}
+
+// Empty returns true if the plan contains no changes to apply.
+func (p *Plan) Empty() bool {
+	return len(p.TopicCreates) == 0 &&
+		len(p.PartitionIncreases) == 0 &&
+		len(p.ConfigUpdates) == 0 &&
+		len(p.ACLAdds) == 0 &&
+		len(p.ACLRemoves) == 0 &&
+		len(p.QuotaAlterations) == 0
+}
+
+// String renders the plan in a human readable form, one change per line.
+func (p *Plan) String() string {
+	var sb strings.Builder
+
+	for _, c := range p.TopicCreates {
+		fmt.Fprintf(&sb, "+ topic %s (partitions=%d, replicationFactor=%d)\n", c.Topic, c.Detail.NumPartitions, c.Detail.ReplicationFactor)
+		for _, name := range slices.Sorted(maps.Keys(c.Detail.ConfigEntries)) {
+			fmt.Fprintf(&sb, "    %s=%s\n", name, *c.Detail.ConfigEntries[name])
+		}
+	}
+	for _, pi := range p.PartitionIncreases {
+		fmt.Fprintf(&sb, "~ topic %s partitions %d -> %d\n", pi.Topic, pi.From, pi.Count)
+	}
+	for _, cu := range p.ConfigUpdates {
+		fmt.Fprintf(&sb, "~ topic %s configs\n", cu.Topic)
+		for _, name := range slices.Sorted(maps.Keys(cu.Entries)) {
+			entry := cu.Entries[name]
+			if entry.Operation == sarama.IncrementalAlterConfigsOperationDelete {
+				fmt.Fprintf(&sb, "    - %s\n", name)
+			} else {
+				fmt.Fprintf(&sb, "    ~ %s=%s\n", name, *entry.Value)
+			}
+		}
+	}
+	for _, ra := range p.ACLAdds {
+		fmt.Fprintf(&sb, "+ acl %s\n", formatACL(ra.Resource, ra.Acls[0]))
+	}
+	for _, ra := range p.ACLRemoves {
+		fmt.Fprintf(&sb, "- acl %s\n", formatACL(ra.Resource, ra.Acls[0]))
+	}
+	for _, qa := range p.QuotaAlterations {
+		fmt.Fprintf(&sb, "~ quota %s\n", entityKey(qa.Entity))
+		for _, op := range qa.Ops {
+			if op.Remove {
+				fmt.Fprintf(&sb, "    - %s\n", op.Key)
+			} else {
+				fmt.Fprintf(&sb, "    ~ %s=%g\n", op.Key, op.Value)
+			}
+		}
+	}
+	for _, w := range p.Warnings {
+		fmt.Fprintf(&sb, "! %s\n", w)
+	}
+
+	return sb.String()
+}
+
+func formatACL(resource sarama.Resource, acl *sarama.Acl) string {
+	return fmt.Sprintf("%s:%s:%s principal=%s host=%s operation=%s permission=%s",
+		resource.ResourceType.String(), resource.ResourcePatternType.String(), resource.ResourceName,
+		acl.Principal, acl.Host, acl.Operation.String(), acl.PermissionType.String())
+}
+
+func entityKey(entity []sarama.QuotaEntityComponent) string {
+	parts := make([]string, 0, len(entity))
+	for _, c := range entity {
+		name := c.Name
+		if c.MatchType == sarama.QuotaMatchDefault {
+			name = "<default>"
+		}
+		parts = append(parts, fmt.Sprintf("%s=%s", c.EntityType, name))
+	}
+	slices.Sort(parts)
+	return strings.Join(parts, ",")
+}
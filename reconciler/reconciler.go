@@ -0,0 +1,354 @@
+package reconciler
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/IBM/sarama"
+)
+
+// Options controls which live resources the reconciler may remove.
+type Options struct {
+	// PruneConfigs deletes topic config overrides of declared topics that are
+	// not part of the desired state. The overrides are the configs described
+	// with the SourceTopic source; the values inherited from the brokers are
+	// left alone.
+	PruneConfigs bool
+	// PruneACLs deletes ACL bindings that are not part of the desired state.
+	PruneACLs bool
+	// PruneQuotas removes client quota values that are not part of the
+	// desired state.
+	PruneQuotas bool
+}
+
+// Reconciler diffs a DesiredState against a cluster and applies the result.
+type Reconciler struct {
+	admin sarama.ClusterAdmin
+	opts  Options
+}
+
+// New creates a Reconciler that reads and changes the cluster through admin.
+func New(admin sarama.ClusterAdmin, opts Options) *Reconciler {
+	return &Reconciler{admin: admin, opts: opts}
+}
+
+// Plan computes the changes needed to move the cluster to the desired state.
+// ACLs and client quotas are only read from the cluster when the desired
+// state declares some or the corresponding prune option is enabled.
+func (r *Reconciler) Plan(desired *DesiredState) (*Plan, error) {
+	plan := new(Plan)
+
+	if err := r.planTopics(desired, plan); err != nil {
+		return nil, err
+	}
+	if len(desired.ACLs) > 0 || r.opts.PruneACLs {
+		if err := r.planACLs(desired, plan); err != nil {
+			return nil, err
+		}
+	}
+	if len(desired.Quotas) > 0 || r.opts.PruneQuotas {
+		if err := r.planQuotas(desired, plan); err != nil {
+			return nil, err
+		}
+	}
+
+	return plan, nil
+}
+
+func (r *Reconciler) planTopics(desired *DesiredState, plan *Plan) error {
+	if len(desired.Topics) == 0 {
+		return nil
+	}
+
+	live, err := r.admin.ListTopics()
+	if err != nil {
+		return fmt.Errorf("reconciler: failed to list topics: %w", err)
+	}
+
+	for _, spec := range desired.Topics {
+		current, ok := live[spec.Name]
+		if !ok {
+			plan.TopicCreates = append(plan.TopicCreates, TopicCreate{Topic: spec.Name, Detail: spec.topicDetail()})
+			continue
+		}
+
+		switch {
+		case spec.Partitions == 0 || spec.Partitions == current.NumPartitions:
+		case spec.Partitions > current.NumPartitions:
+			plan.PartitionIncreases = append(plan.PartitionIncreases, PartitionIncrease{
+				Topic: spec.Name,
+				From:  current.NumPartitions,
+				Count: spec.Partitions,
+			})
+		default:
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("topic %s: cannot decrease partitions from %d to %d",
+				spec.Name, current.NumPartitions, spec.Partitions))
+		}
+
+		if spec.ReplicationFactor != 0 && spec.ReplicationFactor != current.ReplicationFactor {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("topic %s: replication factor is %d, not %d, reassign partitions to change it",
+				spec.Name, current.ReplicationFactor, spec.ReplicationFactor))
+		}
+
+		entries := make(map[string]sarama.IncrementalAlterConfigsEntry)
+		for name, value := range spec.Configs {
+			if cur, ok := current.ConfigEntries[name]; ok && cur != nil && *cur == value {
+				continue
+			}
+			entries[name] = sarama.IncrementalAlterConfigsEntry{
+				Operation: sarama.IncrementalAlterConfigsOperationSet,
+				Value:     &value,
+			}
+		}
+		if r.opts.PruneConfigs {
+			overrides, err := r.topicOverrides(spec.Name)
+			if err != nil {
+				return err
+			}
+			for _, name := range overrides {
+				if _, ok := spec.Configs[name]; !ok {
+					entries[name] = sarama.IncrementalAlterConfigsEntry{Operation: sarama.IncrementalAlterConfigsOperationDelete}
+				}
+			}
+		}
+		if len(entries) > 0 {
+			plan.ConfigUpdates = append(plan.ConfigUpdates, ConfigUpdate{Topic: spec.Name, Entries: entries})
+		}
+	}
+
+	return nil
+}
+
+// topicOverrides returns the names of the configs set on topic itself. The
+// configs listed with the topics also include the values inherited from the
+// broker configs, which cannot be deleted from the topic.
+func (r *Reconciler) topicOverrides(topic string) ([]string, error) {
+	configs, err := r.admin.DescribeConfig(sarama.ConfigResource{Type: sarama.TopicResource, Name: topic})
+	if err != nil {
+		return nil, fmt.Errorf("reconciler: failed to describe the configs of topic %s: %w", topic, err)
+	}
+	var overrides []string
+	for _, config := range configs {
+		if config.Source == sarama.SourceTopic {
+			overrides = append(overrides, config.Name)
+		}
+	}
+	return overrides, nil
+}
+
+func (t TopicSpec) topicDetail() *sarama.TopicDetail {
+	detail := &sarama.TopicDetail{NumPartitions: -1, ReplicationFactor: -1}
+	if t.Partitions > 0 {
+		detail.NumPartitions = t.Partitions
+	}
+	if t.ReplicationFactor > 0 {
+		detail.ReplicationFactor = t.ReplicationFactor
+	}
+	if len(t.Configs) > 0 {
+		detail.ConfigEntries = make(map[string]*string, len(t.Configs))
+		for name, value := range t.Configs {
+			detail.ConfigEntries[name] = &value
+		}
+	}
+	return detail
+}
+
+type aclKey struct {
+	resource sarama.Resource
+	acl      sarama.Acl
+}
+
+func (r *Reconciler) planACLs(desired *DesiredState, plan *Plan) error {
+	live, err := r.admin.ListAcls(sarama.AclFilter{
+		ResourceType:              sarama.AclResourceAny,
+		ResourcePatternTypeFilter: sarama.AclPatternAny,
+		Operation:                 sarama.AclOperationAny,
+		PermissionType:            sarama.AclPermissionAny,
+	})
+	if err != nil {
+		return fmt.Errorf("reconciler: failed to list acls: %w", err)
+	}
+
+	existing := make(map[aclKey]struct{})
+	for _, ra := range live {
+		for _, acl := range ra.Acls {
+			existing[aclKey{resource: ra.Resource, acl: *acl}] = struct{}{}
+		}
+	}
+
+	wanted := make(map[aclKey]struct{}, len(desired.ACLs))
+	for _, spec := range desired.ACLs {
+		key := aclKey{
+			resource: sarama.Resource{
+				ResourceType:        spec.ResourceType,
+				ResourceName:        spec.ResourceName,
+				ResourcePatternType: spec.PatternType,
+			},
+			acl: sarama.Acl{
+				Principal:      spec.Principal,
+				Host:           spec.Host,
+				Operation:      spec.Operation,
+				PermissionType: spec.Permission,
+			},
+		}
+		if _, ok := wanted[key]; ok {
+			continue
+		}
+		wanted[key] = struct{}{}
+		if _, ok := existing[key]; !ok {
+			plan.ACLAdds = append(plan.ACLAdds, key.resourceAcls())
+		}
+	}
+
+	if r.opts.PruneACLs {
+		for _, ra := range live {
+			for _, acl := range ra.Acls {
+				key := aclKey{resource: ra.Resource, acl: *acl}
+				if _, ok := wanted[key]; !ok {
+					plan.ACLRemoves = append(plan.ACLRemoves, key.resourceAcls())
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (k aclKey) resourceAcls() sarama.ResourceAcls {
+	acl := k.acl
+	return sarama.ResourceAcls{Resource: k.resource, Acls: []*sarama.Acl{&acl}}
+}
+
+func (r *Reconciler) planQuotas(desired *DesiredState, plan *Plan) error {
+	live, err := r.admin.DescribeClientQuotas(nil, false)
+	if err != nil {
+		return fmt.Errorf("reconciler: failed to describe client quotas: %w", err)
+	}
+
+	existing := make(map[string]sarama.DescribeClientQuotasEntry, len(live))
+	for _, entry := range live {
+		existing[entityKey(entry.Entity)] = entry
+	}
+
+	wanted := make(map[string]struct{}, len(desired.Quotas))
+	for _, spec := range desired.Quotas {
+		entity := spec.entityComponents()
+		key := entityKey(entity)
+		wanted[key] = struct{}{}
+		current := existing[key].Values
+
+		var ops []sarama.ClientQuotasOp
+		for _, name := range slices.Sorted(maps.Keys(spec.Values)) {
+			value := spec.Values[name]
+			if cur, ok := current[name]; ok && cur == value {
+				continue
+			}
+			ops = append(ops, sarama.ClientQuotasOp{Key: name, Value: value})
+		}
+		if r.opts.PruneQuotas {
+			for _, name := range slices.Sorted(maps.Keys(current)) {
+				if _, ok := spec.Values[name]; !ok {
+					ops = append(ops, sarama.ClientQuotasOp{Key: name, Remove: true})
+				}
+			}
+		}
+		if len(ops) > 0 {
+			plan.QuotaAlterations = append(plan.QuotaAlterations, QuotaAlteration{Entity: entity, Ops: ops})
+		}
+	}
+
+	if r.opts.PruneQuotas {
+		for _, key := range slices.Sorted(maps.Keys(existing)) {
+			if _, ok := wanted[key]; ok {
+				continue
+			}
+			entry := existing[key]
+			ops := make([]sarama.ClientQuotasOp, 0, len(entry.Values))
+			for _, name := range slices.Sorted(maps.Keys(entry.Values)) {
+				ops = append(ops, sarama.ClientQuotasOp{Key: name, Remove: true})
+			}
+			plan.QuotaAlterations = append(plan.QuotaAlterations, QuotaAlteration{Entity: entry.Entity, Ops: ops})
+		}
+	}
+
+	return nil
+}
+
+// Apply executes the plan. With dryRun set, topic, partition, config and
+// quota changes are sent with validateOnly so the broker checks them without
+// making changes, and ACL changes, which cannot be validated, are skipped.
+// Apply carries on after a failed change and returns all failures joined.
+func (r *Reconciler) Apply(plan *Plan, dryRun bool) error {
+	var errs []error
+
+	if len(plan.TopicCreates) > 0 {
+		topics := make(map[string]*sarama.TopicDetail, len(plan.TopicCreates))
+		for _, c := range plan.TopicCreates {
+			topics[c.Topic] = c.Detail
+		}
+		results, err := r.admin.CreateTopics(topics, dryRun)
+		if err != nil && len(results) == 0 {
+			errs = append(errs, fmt.Errorf("create topics: %w", err))
+		}
+		for _, topic := range slices.Sorted(maps.Keys(results)) {
+			if results[topic].Err != nil {
+				errs = append(errs, fmt.Errorf("create topic %s: %w", topic, results[topic].Err))
+			}
+		}
+	}
+
+	for _, pi := range plan.PartitionIncreases {
+		if err := r.admin.CreatePartitions(pi.Topic, pi.Count, nil, dryRun); err != nil {
+			errs = append(errs, fmt.Errorf("increase partitions of topic %s: %w", pi.Topic, err))
+		}
+	}
+
+	for _, cu := range plan.ConfigUpdates {
+		if err := r.admin.IncrementalAlterConfig(sarama.TopicResource, cu.Topic, cu.Entries, dryRun); err != nil {
+			errs = append(errs, fmt.Errorf("update configs of topic %s: %w", cu.Topic, err))
+		}
+	}
+
+	if !dryRun {
+		if len(plan.ACLAdds) > 0 {
+			acls := make([]*sarama.ResourceAcls, 0, len(plan.ACLAdds))
+			for i := range plan.ACLAdds {
+				acls = append(acls, &plan.ACLAdds[i])
+			}
+			if err := r.admin.CreateACLs(acls); err != nil {
+				errs = append(errs, fmt.Errorf("create acls: %w", err))
+			}
+		}
+
+		for _, ra := range plan.ACLRemoves {
+			acl := ra.Acls[0]
+			filter := sarama.AclFilter{
+				ResourceType:              ra.ResourceType,
+				ResourceName:              &ra.ResourceName,
+				ResourcePatternTypeFilter: ra.ResourcePatternType,
+				Principal:                 &acl.Principal,
+				Host:                      &acl.Host,
+				Operation:                 acl.Operation,
+				PermissionType:            acl.PermissionType,
+			}
+			if _, err := r.admin.DeleteACL(filter, false); err != nil {
+				errs = append(errs, fmt.Errorf("delete acl %s: %w", formatACL(ra.Resource, acl), err))
+			}
+		}
+	}
+
+	for _, qa := range plan.QuotaAlterations {
+		for _, op := range qa.Ops {
+			if err := r.admin.AlterClientQuotas(qa.Entity, op, dryRun); err != nil {
+				errs = append(errs, fmt.Errorf("alter quota %s of %s: %w", op.Key, entityKey(qa.Entity), err))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("reconciler: failed to apply plan: %w", errors.Join(errs...))
+	}
+	return nil
+}
@@ -0,0 +1,319 @@
+package reconciler
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/IBM/sarama"
+)
+
+type fakeAdmin struct {
+	sarama.ClusterAdmin
+
+	topics    map[string]sarama.TopicDetail
+	described map[string][]sarama.ConfigEntry
+	acls      []sarama.ResourceAcls
+	quotas    []sarama.DescribeClientQuotasEntry
+
+	created     map[string]*sarama.TopicDetail
+	validated   bool
+	partitions  map[string]int32
+	configs     map[string]map[string]sarama.IncrementalAlterConfigsEntry
+	aclsCreated []*sarama.ResourceAcls
+	aclsDeleted []sarama.AclFilter
+	quotaOps    []sarama.ClientQuotasOp
+}
+
+func (f *fakeAdmin) ListTopics() (map[string]sarama.TopicDetail, error) {
+	return f.topics, nil
+}
+
+func (f *fakeAdmin) DescribeConfig(resource sarama.ConfigResource) ([]sarama.ConfigEntry, error) {
+	return f.described[resource.Name], nil
+}
+
+func (f *fakeAdmin) ListAcls(sarama.AclFilter) ([]sarama.ResourceAcls, error) {
+	return f.acls, nil
+}
+
+func (f *fakeAdmin) DescribeClientQuotas([]sarama.QuotaFilterComponent, bool) ([]sarama.DescribeClientQuotasEntry, error) {
+	return f.quotas, nil
+}
+
+func (f *fakeAdmin) CreateTopics(topics map[string]*sarama.TopicDetail, validateOnly bool) (map[string]*sarama.CreateTopicResult, error) {
+	f.created = topics
+	f.validated = validateOnly
+	results := make(map[string]*sarama.CreateTopicResult, len(topics))
+	for topic := range topics {
+		results[topic] = &sarama.CreateTopicResult{}
+	}
+	return results, nil
+}
+
+func (f *fakeAdmin) CreatePartitions(topic string, count int32, _ [][]int32, _ bool) error {
+	if f.partitions == nil {
+		f.partitions = make(map[string]int32)
+	}
+	f.partitions[topic] = count
+	return nil
+}
+
+func (f *fakeAdmin) IncrementalAlterConfig(_ sarama.ConfigResourceType, name string, entries map[string]sarama.IncrementalAlterConfigsEntry, _ bool) error {
+	if f.configs == nil {
+		f.configs = make(map[string]map[string]sarama.IncrementalAlterConfigsEntry)
+	}
+	f.configs[name] = entries
+	return nil
+}
+
+func (f *fakeAdmin) CreateACLs(acls []*sarama.ResourceAcls) error {
+	f.aclsCreated = append(f.aclsCreated, acls...)
+	return nil
+}
+
+func (f *fakeAdmin) DeleteACL(filter sarama.AclFilter, _ bool) ([]sarama.MatchingAcl, error) {
+	f.aclsDeleted = append(f.aclsDeleted, filter)
+	return nil, nil
+}
+
+func (f *fakeAdmin) AlterClientQuotas(_ []sarama.QuotaEntityComponent, op sarama.ClientQuotasOp, _ bool) error {
+	f.quotaOps = append(f.quotaOps, op)
+	return nil
+}
+
+const testState = `
+topics:
+  - name: orders
+    partitions: 6
+    replicationFactor: 3
+    configs:
+      retention.ms: "604800000"
+  - name: payments
+    partitions: 3
+    configs:
+      cleanup.policy: compact
+acls:
+  - resourceType: topic
+    resourceName: orders
+    principal: User:billing
+    operation: read
+    permission: allow
+quotas:
+  - entity:
+      - type: user
+        name: billing
+    values:
+      consumer_byte_rate: 2048
+`
+
+func newTestAdmin() *fakeAdmin {
+	policy := "delete"
+	segment := "536870912"
+	return &fakeAdmin{
+		topics: map[string]sarama.TopicDetail{
+			"orders": {
+				NumPartitions:     3,
+				ReplicationFactor: 3,
+				ConfigEntries:     map[string]*string{"cleanup.policy": &policy, "segment.bytes": &segment},
+			},
+		},
+		described: map[string][]sarama.ConfigEntry{
+			"orders": {
+				{Name: "cleanup.policy", Value: policy, Source: sarama.SourceTopic},
+				{Name: "segment.bytes", Value: segment, Source: sarama.SourceStaticBroker},
+			},
+		},
+		acls: []sarama.ResourceAcls{{
+			Resource: sarama.Resource{
+				ResourceType:        sarama.AclResourceTopic,
+				ResourceName:        "orders",
+				ResourcePatternType: sarama.AclPatternLiteral,
+			},
+			Acls: []*sarama.Acl{{
+				Principal:      "User:legacy",
+				Host:           "*",
+				Operation:      sarama.AclOperationWrite,
+				PermissionType: sarama.AclPermissionAllow,
+			}},
+		}},
+		quotas: []sarama.DescribeClientQuotasEntry{{
+			Entity: []sarama.QuotaEntityComponent{{EntityType: sarama.QuotaEntityUser, MatchType: sarama.QuotaMatchExact, Name: "billing"}},
+			Values: map[string]float64{"consumer_byte_rate": 1024, "producer_byte_rate": 1024},
+		}},
+	}
+}
+
+func TestParse(t *testing.T) {
+	state, err := Parse([]byte(testState))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Topics) != 2 || len(state.ACLs) != 1 || len(state.Quotas) != 1 {
+		t.Fatalf("unexpected state %+v", state)
+	}
+	acl := state.ACLs[0]
+	if acl.ResourceType != sarama.AclResourceTopic || acl.Operation != sarama.AclOperationRead || acl.Permission != sarama.AclPermissionAllow {
+		t.Errorf("unexpected acl %+v", acl)
+	}
+	if acl.PatternType != sarama.AclPatternLiteral || acl.Host != "*" {
+		t.Errorf("expected acl defaults to be applied, got %+v", acl)
+	}
+
+	json := `{"topics": [{"name": "orders", "partitions": 1}]}`
+	if state, err = Parse([]byte(json)); err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Topics) != 1 || state.Topics[0].Partitions != 1 {
+		t.Errorf("unexpected state %+v", state)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for name, doc := range map[string]string{
+		"unknown field":   "topics:\n  - name: a\n    partitons: 1\n",
+		"duplicate topic": "topics:\n  - name: a\n  - name: a\n",
+		"bad operation":   "acls:\n  - resourceType: topic\n    resourceName: a\n    principal: User:a\n    operation: fly\n    permission: allow\n",
+		"bad permission":  "acls:\n  - resourceType: topic\n    resourceName: a\n    principal: User:a\n    operation: read\n    permission: any\n",
+		"bad quota type":  "quotas:\n  - entity:\n      - type: group\n    values:\n      a: 1\n",
+	} {
+		if _, err := Parse([]byte(doc)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestPlan(t *testing.T) {
+	state, err := Parse([]byte(testState))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := New(newTestAdmin(), Options{})
+	plan, err := r.Plan(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(plan.TopicCreates) != 1 || plan.TopicCreates[0].Topic != "payments" {
+		t.Fatalf("expected payments to be created, got %+v", plan.TopicCreates)
+	}
+	if detail := plan.TopicCreates[0].Detail; detail.NumPartitions != 3 || detail.ReplicationFactor != -1 {
+		t.Errorf("unexpected topic detail %+v", detail)
+	}
+	if len(plan.PartitionIncreases) != 1 || plan.PartitionIncreases[0].Count != 6 {
+		t.Errorf("expected orders partitions to increase to 6, got %+v", plan.PartitionIncreases)
+	}
+	if len(plan.ConfigUpdates) != 1 || len(plan.ConfigUpdates[0].Entries) != 1 {
+		t.Fatalf("expected a single config update, got %+v", plan.ConfigUpdates)
+	}
+	if entry := plan.ConfigUpdates[0].Entries["retention.ms"]; entry.Operation != sarama.IncrementalAlterConfigsOperationSet {
+		t.Errorf("expected retention.ms to be set, got %+v", entry)
+	}
+	if len(plan.ACLAdds) != 1 || len(plan.ACLRemoves) != 0 {
+		t.Errorf("expected one acl add and no removes, got %+v / %+v", plan.ACLAdds, plan.ACLRemoves)
+	}
+	if len(plan.QuotaAlterations) != 1 || len(plan.QuotaAlterations[0].Ops) != 1 {
+		t.Errorf("expected one quota op, got %+v", plan.QuotaAlterations)
+	}
+	if !strings.Contains(plan.String(), "+ topic payments") {
+		t.Errorf("unexpected plan rendering:\n%s", plan)
+	}
+}
+
+func TestPlanPrune(t *testing.T) {
+	state, err := Parse([]byte(testState))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := New(newTestAdmin(), Options{PruneConfigs: true, PruneACLs: true, PruneQuotas: true})
+	plan, err := r.Plan(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if entry, ok := plan.ConfigUpdates[0].Entries["cleanup.policy"]; !ok || entry.Operation != sarama.IncrementalAlterConfigsOperationDelete {
+		t.Errorf("expected cleanup.policy to be deleted, got %+v", plan.ConfigUpdates[0].Entries)
+	}
+	if _, ok := plan.ConfigUpdates[0].Entries["segment.bytes"]; ok {
+		t.Errorf("expected the broker sourced segment.bytes not to be deleted, got %+v", plan.ConfigUpdates[0].Entries)
+	}
+	if len(plan.ACLRemoves) != 1 || plan.ACLRemoves[0].Acls[0].Principal != "User:legacy" {
+		t.Errorf("expected the legacy acl to be removed, got %+v", plan.ACLRemoves)
+	}
+	ops := plan.QuotaAlterations[0].Ops
+	if len(ops) != 2 || !ops[1].Remove || ops[1].Key != "producer_byte_rate" {
+		t.Errorf("expected producer_byte_rate to be removed, got %+v", ops)
+	}
+}
+
+func TestPlanPartitionDecrease(t *testing.T) {
+	r := New(newTestAdmin(), Options{})
+	plan, err := r.Plan(&DesiredState{Topics: []TopicSpec{{Name: "orders", Partitions: 1, ReplicationFactor: 1}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.PartitionIncreases) != 0 {
+		t.Errorf("expected no partition changes, got %+v", plan.PartitionIncreases)
+	}
+	if len(plan.Warnings) != 2 {
+		t.Errorf("expected partition and replication factor warnings, got %v", plan.Warnings)
+	}
+}
+
+func TestApply(t *testing.T) {
+	state, err := Parse([]byte(testState))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	admin := newTestAdmin()
+	r := New(admin, Options{PruneACLs: true})
+	plan, err := r.Plan(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Apply(plan, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := admin.created["payments"]; !ok || admin.validated {
+		t.Errorf("expected payments to be created, got %+v (validateOnly=%v)", admin.created, admin.validated)
+	}
+	if admin.partitions["orders"] != 6 {
+		t.Errorf("expected orders to be grown to 6 partitions, got %v", admin.partitions)
+	}
+	if _, ok := admin.configs["orders"]["retention.ms"]; !ok {
+		t.Errorf("expected retention.ms to be updated, got %v", admin.configs)
+	}
+	if len(admin.aclsCreated) != 1 || len(admin.aclsDeleted) != 1 {
+		t.Errorf("expected one acl created and one deleted, got %d / %d", len(admin.aclsCreated), len(admin.aclsDeleted))
+	}
+	if len(admin.quotaOps) != 1 || admin.quotaOps[0].Value != 2048 {
+		t.Errorf("expected consumer_byte_rate to be set, got %+v", admin.quotaOps)
+	}
+}
+
+func TestApplyDryRun(t *testing.T) {
+	state, err := Parse([]byte(testState))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	admin := newTestAdmin()
+	r := New(admin, Options{PruneACLs: true})
+	plan, err := r.Plan(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Apply(plan, true); err != nil {
+		t.Fatal(err)
+	}
+
+	if !admin.validated {
+		t.Error("expected topic creation to be validated only")
+	}
+	if len(admin.aclsCreated) != 0 || len(admin.aclsDeleted) != 0 {
+		t.Error("expected acl changes to be skipped in dry-run")
+	}
+}
@@ -0,0 +1,225 @@
+/*
+Package reconciler compares a declarative description of topics, ACLs and
+client quotas with the live state of a Kafka cluster and applies the
+difference through a sarama.ClusterAdmin.
+
+A desired-state document is parsed with Parse or Load, diffed against the
+cluster with Reconciler.Plan and the resulting Plan is executed, or
+validated only, with Reconciler.Apply:
+
+	state, err := reconciler.Load("cluster.yaml")
+	if err != nil {
+		panic(err)
+	}
+	r := reconciler.New(admin, reconciler.Options{PruneACLs: true})
+	plan, err := r.Plan(state)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Print(plan)
+	if err := r.Apply(plan, false); err != nil {
+		panic(err)
+	}
+
+The reconciler never deletes topics. Topic config overrides, ACLs and
+client quotas that are not part of the desired state are only removed when
+the corresponding prune option is enabled.
+
+NOTE: this package currently does not fall under the API stability
+guarantee of Sarama as it is still considered experimental.
+*/
+package reconciler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/IBM/sarama"
+	"gopkg.in/yaml.v3"
+)
+
+// DesiredState describes the topics, ACLs and client quotas a cluster should
+// have. It can be written as YAML or JSON, for example:
+//
+//	topics:
+//	  - name: orders
+//	    partitions: 12
+//	    replicationFactor: 3
+//	    configs:
+//	      retention.ms: "604800000"
+//	acls:
+//	  - resourceType: topic
+//	    resourceName: orders
+//	    principal: User:billing
+//	    operation: read
+//	    permission: allow
+//	quotas:
+//	  - entity:
+//	      - type: user
+//	        name: billing
+//	    values:
+//	      consumer_byte_rate: 1048576
+type DesiredState struct {
+	Topics []TopicSpec `json:"topics" yaml:"topics"`
+	ACLs   []ACLSpec   `json:"acls" yaml:"acls"`
+	Quotas []QuotaSpec `json:"quotas" yaml:"quotas"`
+}
+
+// TopicSpec describes a single topic.
+type TopicSpec struct {
+	Name string `json:"name" yaml:"name"`
+	// Partitions is the number of partitions of the topic. Zero leaves the
+	// choice to the broker default on creation and the count unmanaged after.
+	Partitions int32 `json:"partitions" yaml:"partitions"`
+	// ReplicationFactor is the replication factor used on creation. Zero
+	// leaves the choice to the broker default.
+	ReplicationFactor int16 `json:"replicationFactor" yaml:"replicationFactor"`
+	// Configs contains the topic config overrides.
+	Configs map[string]string `json:"configs" yaml:"configs"`
+}
+
+// ACLSpec describes a single ACL binding.
+type ACLSpec struct {
+	ResourceType sarama.AclResourceType `json:"resourceType" yaml:"resourceType"`
+	ResourceName string                 `json:"resourceName" yaml:"resourceName"`
+	// PatternType defaults to literal.
+	PatternType sarama.AclResourcePatternType `json:"patternType" yaml:"patternType"`
+	Principal   string                        `json:"principal" yaml:"principal"`
+	// Host defaults to "*".
+	Host       string                   `json:"host" yaml:"host"`
+	Operation  sarama.AclOperation      `json:"operation" yaml:"operation"`
+	Permission sarama.AclPermissionType `json:"permission" yaml:"permission"`
+}
+
+// QuotaSpec describes the client quotas of a single entity.
+type QuotaSpec struct {
+	Entity []QuotaEntitySpec  `json:"entity" yaml:"entity"`
+	Values map[string]float64 `json:"values" yaml:"values"`
+}
+
+// QuotaEntitySpec is one component of a quota entity. A nil Name refers to
+// the default entity of the given type.
+type QuotaEntitySpec struct {
+	Type sarama.QuotaEntityType `json:"type" yaml:"type"`
+	Name *string                `json:"name" yaml:"name"`
+}
+
+// Parse decodes a desired-state document. As JSON is a subset of YAML both
+// formats are accepted. Unknown fields are rejected and the result is
+// validated before it is returned.
+func Parse(data []byte) (*DesiredState, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+
+	state := new(DesiredState)
+	if err := dec.Decode(state); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("reconciler: failed to parse desired state: %w", err)
+	}
+	state.setDefaults()
+	if err := state.Validate(); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// Load reads and parses the desired-state document at path.
+func Load(path string) (*DesiredState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+func (s *DesiredState) setDefaults() {
+	for i := range s.ACLs {
+		acl := &s.ACLs[i]
+		if acl.PatternType == sarama.AclPatternUnknown {
+			acl.PatternType = sarama.AclPatternLiteral
+		}
+		if acl.Host == "" {
+			acl.Host = "*"
+		}
+	}
+}
+
+// Validate checks the desired state for missing or conflicting entries.
+func (s *DesiredState) Validate() error {
+	var errs []error
+
+	topics := make(map[string]struct{}, len(s.Topics))
+	for _, topic := range s.Topics {
+		if topic.Name == "" {
+			errs = append(errs, errors.New("topic without name"))
+			continue
+		}
+		if _, ok := topics[topic.Name]; ok {
+			errs = append(errs, fmt.Errorf("topic %s: declared more than once", topic.Name))
+		}
+		topics[topic.Name] = struct{}{}
+		if topic.Partitions < 0 {
+			errs = append(errs, fmt.Errorf("topic %s: invalid partition count %d", topic.Name, topic.Partitions))
+		}
+		if topic.ReplicationFactor < 0 {
+			errs = append(errs, fmt.Errorf("topic %s: invalid replication factor %d", topic.Name, topic.ReplicationFactor))
+		}
+	}
+
+	for i, acl := range s.ACLs {
+		switch {
+		case acl.ResourceType <= sarama.AclResourceAny:
+			errs = append(errs, fmt.Errorf("acl %d: invalid resource type", i))
+		case acl.ResourceName == "":
+			errs = append(errs, fmt.Errorf("acl %d: missing resource name", i))
+		case acl.PatternType != sarama.AclPatternLiteral && acl.PatternType != sarama.AclPatternPrefixed:
+			errs = append(errs, fmt.Errorf("acl %d: pattern type must be literal or prefixed", i))
+		case acl.Principal == "":
+			errs = append(errs, fmt.Errorf("acl %d: missing principal", i))
+		case acl.Operation <= sarama.AclOperationAny:
+			errs = append(errs, fmt.Errorf("acl %d: invalid operation", i))
+		case acl.Permission != sarama.AclPermissionAllow && acl.Permission != sarama.AclPermissionDeny:
+			errs = append(errs, fmt.Errorf("acl %d: permission must be allow or deny", i))
+		}
+	}
+
+	quotas := make(map[string]struct{}, len(s.Quotas))
+	for i, quota := range s.Quotas {
+		if len(quota.Entity) == 0 {
+			errs = append(errs, fmt.Errorf("quota %d: missing entity", i))
+			continue
+		}
+		for _, component := range quota.Entity {
+			switch component.Type {
+			case sarama.QuotaEntityUser, sarama.QuotaEntityClientID, sarama.QuotaEntityIP:
+			default:
+				errs = append(errs, fmt.Errorf("quota %d: invalid entity type %q", i, component.Type))
+			}
+		}
+		key := entityKey(quota.entityComponents())
+		if _, ok := quotas[key]; ok {
+			errs = append(errs, fmt.Errorf("quota %s: declared more than once", key))
+		}
+		quotas[key] = struct{}{}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("reconciler: invalid desired state: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+func (q QuotaSpec) entityComponents() []sarama.QuotaEntityComponent {
+	components := make([]sarama.QuotaEntityComponent, 0, len(q.Entity))
+	for _, e := range q.Entity {
+		component := sarama.QuotaEntityComponent{EntityType: e.Type, MatchType: sarama.QuotaMatchDefault}
+		if e.Name != nil {
+			component.MatchType = sarama.QuotaMatchExact
+			component.Name = *e.Name
+		}
+		components = append(components, component)
+	}
+	return components
+}
@@ -217,23 +217,138 @@ func allocateBody(key, version int16) protocolBody {
 		return &DescribeUserScramCredentialsRequest{Version: version}
 	case apiKeyAlterUserScramCredentials:
 		return &AlterUserScramCredentialsRequest{Version: version}
-		// 52: VoteRequest
-		// 53: BeginQuorumEpochRequest
-		// 54: EndQuorumEpochRequest
-		// 55: DescribeQuorumRequest
-		// 56: AlterPartitionRequest
-		// 57: UpdateFeaturesRequest
-		// 58: EnvelopeRequest
-		// 59: FetchSnapshotRequest
-		// 60: DescribeClusterRequest
-		// 61: DescribeProducersRequest
-		// 62: BrokerRegistrationRequest
-		// 63: BrokerHeartbeatRequest
-		// 64: UnregisterBrokerRequest
-		// 65: DescribeTransactionsRequest
-		// 66: ListTransactionsRequest
-		// 67: AllocateProducerIdsRequest
-		// 68: ConsumerGroupHeartbeatRequest
+	// 52: VoteRequest
+	// 53: BeginQuorumEpochRequest
+	// 54: EndQuorumEpochRequest
+	case apiKeyDescribeQuorum:
+		return &DescribeQuorumRequest{Version: version}
+	// 56: AlterPartitionRequest
+	case apiKeyUpdateFeatures:
+		return &UpdateFeaturesRequest{Version: version}
+	// 58: EnvelopeRequest
+	// 59: FetchSnapshotRequest
+	case apiKeyDescribeCluster:
+		return &DescribeClusterRequest{Version: version}
+	// 61: DescribeProducersRequest
+	// 62: BrokerRegistrationRequest
+	// 63: BrokerHeartbeatRequest
+	// 64: UnregisterBrokerRequest
+	// 65: DescribeTransactionsRequest
+	// 66: ListTransactionsRequest
+	// 67: AllocateProducerIdsRequest
+	// 68: ConsumerGroupHeartbeatRequest
+	case apiKeyConsumerGroupDescribe:
+		return &ConsumerGroupDescribeRequest{Version: version}
+	// 70: ControllerRegistrationRequest
+	case apiKeyGetTelemetrySubscriptions:
+		return &GetTelemetrySubscriptionsRequest{Version: version}
+	case apiKeyPushTelemetry:
+		return &PushTelemetryRequest{Version: version}
+	}
+	return nil
+}
+
+// allocateResponseBody is the response counterpart of allocateBody, used to
+// decode the responses of a capture (see CaptureRecord). There's no central
+// registry of types, so we can't do this using reflection for Response types
+// and assuming that the struct is identically named, just with Response
+// instead of Request.
+func allocateResponseBody(key, version int16) protocolBody {
+	switch key {
+	case apiKeyProduce:
+		return &ProduceResponse{Version: version}
+	case apiKeyFetch:
+		return &FetchResponse{Version: version}
+	case apiKeyListOffsets:
+		return &OffsetResponse{Version: version}
+	case apiKeyMetadata:
+		return &MetadataResponse{Version: version}
+	case apiKeyOffsetCommit:
+		return &OffsetCommitResponse{Version: version}
+	case apiKeyOffsetFetch:
+		return &OffsetFetchResponse{Version: version}
+	case apiKeyFindCoordinator:
+		return &FindCoordinatorResponse{Version: version}
+	case apiKeyJoinGroup:
+		return &JoinGroupResponse{Version: version}
+	case apiKeyHeartbeat:
+		return &HeartbeatResponse{Version: version}
+	case apiKeyLeaveGroup:
+		return &LeaveGroupResponse{Version: version}
+	case apiKeySyncGroup:
+		return &SyncGroupResponse{Version: version}
+	case apiKeyDescribeGroups:
+		return &DescribeGroupsResponse{Version: version}
+	case apiKeyListGroups:
+		return &ListGroupsResponse{Version: version}
+	case apiKeySaslHandshake:
+		return &SaslHandshakeResponse{Version: version}
+	case apiKeyApiVersions:
+		return &ApiVersionsResponse{Version: version, FinalizedFeaturesEpoch: -1}
+	case apiKeyCreateTopics:
+		return &CreateTopicsResponse{Version: version}
+	case apiKeyDeleteTopics:
+		return &DeleteTopicsResponse{Version: version}
+	case apiKeyDeleteRecords:
+		return &DeleteRecordsResponse{Version: version}
+	case apiKeyInitProducerId:
+		return &InitProducerIDResponse{Version: version}
+	case apiKeyAddPartitionsToTxn:
+		return &AddPartitionsToTxnResponse{Version: version}
+	case apiKeyAddOffsetsToTxn:
+		return &AddOffsetsToTxnResponse{Version: version}
+	case apiKeyEndTxn:
+		return &EndTxnResponse{Version: version}
+	case apiKeyTxnOffsetCommit:
+		return &TxnOffsetCommitResponse{Version: version}
+	case apiKeyDescribeAcls:
+		return &DescribeAclsResponse{Version: version}
+	case apiKeyCreateAcls:
+		return &CreateAclsResponse{Version: version}
+	case apiKeyDeleteAcls:
+		return &DeleteAclsResponse{Version: version}
+	case apiKeyDescribeConfigs:
+		return &DescribeConfigsResponse{Version: version}
+	case apiKeyAlterConfigs:
+		return &AlterConfigsResponse{Version: version}
+	case apiKeyDescribeLogDirs:
+		return &DescribeLogDirsResponse{Version: version}
+	case apiKeySASLAuth:
+		return &SaslAuthenticateResponse{Version: version}
+	case apiKeyCreatePartitions:
+		return &CreatePartitionsResponse{Version: version}
+	case apiKeyDeleteGroups:
+		return &DeleteGroupsResponse{Version: version}
+	case apiKeyElectLeaders:
+		return &ElectLeadersResponse{Version: version}
+	case apiKeyIncrementalAlterConfigs:
+		return &IncrementalAlterConfigsResponse{Version: version}
+	case apiKeyAlterPartitionReassignments:
+		return &AlterPartitionReassignmentsResponse{Version: version}
+	case apiKeyListPartitionReassignments:
+		return &ListPartitionReassignmentsResponse{Version: version}
+	case apiKeyOffsetDelete:
+		return &DeleteOffsetsResponse{Version: version}
+	case apiKeyDescribeClientQuotas:
+		return &DescribeClientQuotasResponse{Version: version}
+	case apiKeyAlterClientQuotas:
+		return &AlterClientQuotasResponse{Version: version}
+	case apiKeyDescribeUserScramCredentials:
+		return &DescribeUserScramCredentialsResponse{Version: version}
+	case apiKeyAlterUserScramCredentials:
+		return &AlterUserScramCredentialsResponse{Version: version}
+	case apiKeyDescribeQuorum:
+		return &DescribeQuorumResponse{Version: version}
+	case apiKeyUpdateFeatures:
+		return &UpdateFeaturesResponse{Version: version}
+	case apiKeyDescribeCluster:
+		return &DescribeClusterResponse{Version: version}
+	case apiKeyConsumerGroupDescribe:
+		return &ConsumerGroupDescribeResponse{Version: version}
+	case apiKeyGetTelemetrySubscriptions:
+		return &GetTelemetrySubscriptionsResponse{Version: version}
+	case apiKeyPushTelemetry:
+		return &PushTelemetryResponse{Version: version}
 	}
 	return nil
 }
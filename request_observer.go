@@ -0,0 +1,157 @@
+package sarama
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// RequestObserver is notified of the requests sent to the brokers and of
+// their responses, e.g. for audit logging, debugging or custom metrics (see
+// Config.RequestObserver, and the github.com/IBM/sarama/tracing/otel module
+// tracing them with OpenTelemetry). Its methods are called synchronously from the
+// goroutines sending the requests and decoding the responses, so they must
+// not block.
+type RequestObserver interface {
+	// BeforeRequest is called right before req is written to broker.
+	BeforeRequest(broker *Broker, req ObservedRequest)
+	// AfterResponse is called once the response to a request has been
+	// received and decoded, or the request has failed. It is called right
+	// after the request was written for requests without response.
+	AfterResponse(broker *Broker, res ObservedResponse)
+}
+
+// ObservedRequest describes a request sent to a broker for a
+// RequestObserver.
+type ObservedRequest struct {
+	// Name is the name of the Kafka API, e.g. "Metadata".
+	Name          string
+	APIKey        int16
+	APIVersion    int16
+	CorrelationID int32
+	// Size is the size in bytes of the encoded request, including its
+	// header.
+	Size int
+}
+
+// ObservedResponse describes the outcome of a request for a
+// RequestObserver.
+type ObservedResponse struct {
+	Request ObservedRequest
+	// Size is the size in bytes of the response read off the broker, zero
+	// for requests without response.
+	Size int
+	// Latency is the time elapsed between the request being written and its
+	// response being read.
+	Latency time.Duration
+	// ThrottleTime is the throttle time reported by the response, if any.
+	ThrottleTime time.Duration
+	// ErrorCodes are the distinct error codes other than ErrNoError found in
+	// the response, be they of the response itself or of its topics,
+	// partitions, groups, etc.
+	ErrorCodes []KError
+	// Err is the error the request failed with, e.g. a network or decoding
+	// error, nil if a response was decoded.
+	Err error
+}
+
+// startRequestObservation notifies the configured RequestObserver that rb
+// is about to be sent with correlationID as size bytes and returns the
+// function to call with its outcome, which is never nil.
+func (b *Broker) startRequestObservation(rb protocolBody, correlationID int32, size int) func(res protocolBody, size int, latency time.Duration, err error) {
+	observer := b.conf.RequestObserver
+	if observer == nil {
+		return func(protocolBody, int, time.Duration, error) {}
+	}
+	req := ObservedRequest{
+		Name:          apiName(rb),
+		APIKey:        rb.key(),
+		APIVersion:    rb.version(),
+		CorrelationID: correlationID,
+		Size:          size,
+	}
+	observer.BeforeRequest(b, req)
+	return func(res protocolBody, size int, latency time.Duration, err error) {
+		observed := ObservedResponse{Request: req, Size: size, Latency: latency, Err: err}
+		if res != nil && err == nil {
+			if throttled, ok := res.(throttleSupport); ok {
+				observed.ThrottleTime = throttled.throttleTime()
+			}
+			observed.ErrorCodes = responseErrorCodes(res)
+		}
+		observer.AfterResponse(b, observed)
+	}
+}
+
+// apiName returns the name of the Kafka API of rb, e.g. "Metadata" for a
+// *MetadataRequest.
+func apiName(rb protocolBody) string {
+	name := fmt.Sprintf("%T", rb)
+	name = name[strings.LastIndexByte(name, '.')+1:]
+	return strings.TrimSuffix(name, "Request")
+}
+
+var (
+	kerrorType  = reflect.TypeOf(ErrNoError)
+	brokerType  = reflect.TypeOf(Broker{})
+	recordsType = reflect.TypeOf(Records{})
+)
+
+// responseErrorCodes returns the distinct error codes other than ErrNoError
+// of res, found by walking its KError fields and its int16 ErrorCode fields.
+func responseErrorCodes(res protocolBody) []KError {
+	var codes []KError
+	var walk func(v reflect.Value)
+	walk = func(v reflect.Value) {
+		switch v.Kind() {
+		case reflect.Ptr, reflect.Interface:
+			if !v.IsNil() {
+				walk(v.Elem())
+			}
+		case reflect.Struct:
+			if v.Type() == brokerType || v.Type() == recordsType {
+				// brokers and fetched records have no error codes
+				return
+			}
+			for i := 0; i < v.NumField(); i++ {
+				field := v.Field(i)
+				if v.Type().Field(i).Name == "ErrorCode" && field.Kind() == reflect.Int16 {
+					codes = appendErrorCode(codes, KError(field.Int()))
+					continue
+				}
+				walk(field)
+			}
+		case reflect.Slice, reflect.Array:
+			if v.Type().Elem().Kind() == reflect.Uint8 {
+				return
+			}
+			for i := 0; i < v.Len(); i++ {
+				walk(v.Index(i))
+			}
+		case reflect.Map:
+			iter := v.MapRange()
+			for iter.Next() {
+				walk(iter.Value())
+			}
+		case reflect.Int16:
+			if v.Type() == kerrorType {
+				codes = appendErrorCode(codes, KError(v.Int()))
+			}
+		}
+	}
+	walk(reflect.ValueOf(res))
+	return codes
+}
+
+func appendErrorCode(codes []KError, code KError) []KError {
+	if code == ErrNoError {
+		return codes
+	}
+	for _, c := range codes {
+		if c == code {
+			return codes
+		}
+	}
+	return append(codes, code)
+}
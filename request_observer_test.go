@@ -0,0 +1,96 @@
+//go:build !functional
+
+package sarama
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+type recordingRequestObserver struct {
+	mu        sync.Mutex
+	requests  []ObservedRequest
+	responses []ObservedResponse
+}
+
+func (o *recordingRequestObserver) BeforeRequest(broker *Broker, req ObservedRequest) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.requests = append(o.requests, req)
+}
+
+func (o *recordingRequestObserver) AfterResponse(broker *Broker, res ObservedResponse) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.responses = append(o.responses, res)
+}
+
+func TestBrokerRequestObserver(t *testing.T) {
+	mockBroker := NewMockBroker(t, 0)
+	defer mockBroker.Close()
+
+	mockBroker.SetHandlerByMap(map[string]MockResponse{
+		"ProduceRequest": NewMockProduceResponse(t).
+			SetError("my_topic", 0, ErrNotLeaderForPartition).
+			SetError("my_topic", 1, ErrNotLeaderForPartition).
+			SetError("other_topic", 0, ErrMessageSizeTooLarge),
+	})
+
+	observer := &recordingRequestObserver{}
+	conf := NewTestConfig()
+	conf.Version = V0_10_0_0
+	conf.RequestObserver = observer
+	broker := NewBroker(mockBroker.Addr())
+	if err := broker.Open(conf); err != nil {
+		t.Fatal(err)
+	}
+	defer safeClose(t, broker)
+
+	request := &ProduceRequest{Version: 2, RequiredAcks: WaitForLocal}
+	request.AddMessage("my_topic", 0, &Message{Value: []byte("foo")})
+	request.AddMessage("my_topic", 1, &Message{Value: []byte("bar")})
+	request.AddMessage("other_topic", 0, &Message{Value: []byte("baz")})
+	if _, err := broker.Produce(request); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(observer.requests) != 1 || len(observer.responses) != 1 {
+		t.Fatalf("Expected 1 request and response to be observed, got %v and %v", observer.requests, observer.responses)
+	}
+	req := observer.requests[0]
+	if req.Name != "Produce" || req.APIKey != apiKeyProduce || req.APIVersion != 2 || req.CorrelationID != 0 || req.Size == 0 {
+		t.Errorf("Unexpected observed request %+v", req)
+	}
+	res := observer.responses[0]
+	if res.Request != req || res.Size == 0 || res.Latency <= 0 || res.Err != nil {
+		t.Errorf("Unexpected observed response %+v", res)
+	}
+	// the produce response blocks are a map, so the order of the error codes is not defined
+	codes := make(map[KError]bool)
+	for _, code := range res.ErrorCodes {
+		codes[code] = true
+	}
+	expected := map[KError]bool{ErrNotLeaderForPartition: true, ErrMessageSizeTooLarge: true}
+	if len(res.ErrorCodes) != 2 || !reflect.DeepEqual(codes, expected) {
+		t.Errorf("Expected error codes %v, got %v", expected, res.ErrorCodes)
+	}
+}
+
+func TestResponseErrorCodes(t *testing.T) {
+	response := &MetadataResponse{
+		Brokers: []*Broker{NewBroker("localhost:9092")},
+		Topics: []*TopicMetadata{
+			{Name: "a", Err: ErrUnknownTopicOrPartition},
+			{Name: "b", Partitions: []*PartitionMetadata{{Err: ErrLeaderNotAvailable}, {}}},
+		},
+	}
+	expected := []KError{ErrUnknownTopicOrPartition, ErrLeaderNotAvailable}
+	if codes := responseErrorCodes(response); !reflect.DeepEqual(codes, expected) {
+		t.Errorf("Expected %v, got %v", expected, codes)
+	}
+
+	if codes := responseErrorCodes(&ApiVersionsResponse{ErrorCode: int16(ErrUnsupportedVersion)}); !reflect.DeepEqual(codes, []KError{ErrUnsupportedVersion}) {
+		t.Errorf("Expected the ErrorCode field to be reported, got %v", codes)
+	}
+}
@@ -68,12 +68,12 @@ var names = map[int16]string{
 	52:                                 "VoteRequest",
 	53:                                 "BeginQuorumEpochRequest",
 	54:                                 "EndQuorumEpochRequest",
-	55:                                 "DescribeQuorumRequest",
+	apiKeyDescribeQuorum:               "DescribeQuorumRequest",
 	56:                                 "AlterPartitionRequest",
-	57:                                 "UpdateFeaturesRequest",
+	apiKeyUpdateFeatures:               "UpdateFeaturesRequest",
 	58:                                 "EnvelopeRequest",
 	59:                                 "FetchSnapshotRequest",
-	60:                                 "DescribeClusterRequest",
+	apiKeyDescribeCluster:              "DescribeClusterRequest",
 	61:                                 "DescribeProducersRequest",
 	62:                                 "BrokerRegistrationRequest",
 	63:                                 "BrokerHeartbeatRequest",
@@ -82,100 +82,10 @@ var names = map[int16]string{
 	66:                                 "ListTransactionsRequest",
 	67:                                 "AllocateProducerIdsRequest",
 	68:                                 "ConsumerGroupHeartbeatRequest",
-}
-
-// allocateResponseBody is a test-only clone of allocateBody. There's no
-// central registry of types, so we can't do this using reflection for Response
-// types and assuming that the struct is identically named, just with Response
-// instead of Request.
-func allocateResponseBody(req protocolBody) protocolBody {
-	key := req.key()
-	version := req.version()
-	switch key {
-	case apiKeyProduce:
-		return &ProduceResponse{Version: version}
-	case apiKeyFetch:
-		return &FetchResponse{Version: version}
-	case apiKeyListOffsets:
-		return &OffsetResponse{Version: version}
-	case apiKeyMetadata:
-		return &MetadataResponse{Version: version}
-	case apiKeyOffsetCommit:
-		return &OffsetCommitResponse{Version: version}
-	case apiKeyOffsetFetch:
-		return &OffsetFetchResponse{Version: version}
-	case apiKeyFindCoordinator:
-		return &FindCoordinatorResponse{Version: version}
-	case apiKeyJoinGroup:
-		return &JoinGroupResponse{Version: version}
-	case apiKeyHeartbeat:
-		return &HeartbeatResponse{Version: version}
-	case apiKeyLeaveGroup:
-		return &LeaveGroupResponse{Version: version}
-	case apiKeySyncGroup:
-		return &SyncGroupResponse{Version: version}
-	case apiKeyDescribeGroups:
-		return &DescribeGroupsResponse{Version: version}
-	case apiKeyListGroups:
-		return &ListGroupsResponse{Version: version}
-	case apiKeySaslHandshake:
-		return &SaslHandshakeResponse{Version: version}
-	case apiKeyApiVersions:
-		return &ApiVersionsResponse{Version: version}
-	case apiKeyCreateTopics:
-		return &CreateTopicsResponse{Version: version}
-	case apiKeyDeleteTopics:
-		return &DeleteTopicsResponse{Version: version}
-	case apiKeyDeleteRecords:
-		return &DeleteRecordsResponse{Version: version}
-	case apiKeyInitProducerId:
-		return &InitProducerIDResponse{Version: version}
-	case apiKeyAddPartitionsToTxn:
-		return &AddPartitionsToTxnResponse{Version: version}
-	case apiKeyAddOffsetsToTxn:
-		return &AddOffsetsToTxnResponse{Version: version}
-	case apiKeyEndTxn:
-		return &EndTxnResponse{Version: version}
-	case apiKeyTxnOffsetCommit:
-		return &TxnOffsetCommitResponse{Version: version}
-	case apiKeyDescribeAcls:
-		return &DescribeAclsResponse{Version: version}
-	case apiKeyCreateAcls:
-		return &CreateAclsResponse{Version: version}
-	case apiKeyDeleteAcls:
-		return &DeleteAclsResponse{Version: version}
-	case apiKeyDescribeConfigs:
-		return &DescribeConfigsResponse{Version: version}
-	case apiKeyAlterConfigs:
-		return &AlterConfigsResponse{Version: version}
-	case apiKeyDescribeLogDirs:
-		return &DescribeLogDirsResponse{Version: version}
-	case apiKeySASLAuth:
-		return &SaslAuthenticateResponse{Version: version}
-	case apiKeyCreatePartitions:
-		return &CreatePartitionsResponse{Version: version}
-	case apiKeyDeleteGroups:
-		return &DeleteGroupsResponse{Version: version}
-	case apiKeyElectLeaders:
-		return &ElectLeadersResponse{Version: version}
-	case apiKeyIncrementalAlterConfigs:
-		return &IncrementalAlterConfigsResponse{Version: version}
-	case apiKeyAlterPartitionReassignments:
-		return &AlterPartitionReassignmentsResponse{Version: version}
-	case apiKeyListPartitionReassignments:
-		return &ListPartitionReassignmentsResponse{Version: version}
-	case apiKeyOffsetDelete:
-		return &DeleteOffsetsResponse{Version: version}
-	case apiKeyDescribeClientQuotas:
-		return &DescribeClientQuotasResponse{Version: version}
-	case apiKeyAlterClientQuotas:
-		return &AlterClientQuotasResponse{Version: version}
-	case apiKeyDescribeUserScramCredentials:
-		return &DescribeUserScramCredentialsResponse{Version: version}
-	case apiKeyAlterUserScramCredentials:
-		return &AlterUserScramCredentialsResponse{Version: version}
-	}
-	return nil
+	apiKeyConsumerGroupDescribe:        "ConsumerGroupDescribeRequest",
+	70:                                 "ControllerRegistrationRequest",
+	apiKeyGetTelemetrySubscriptions:    "GetTelemetrySubscriptionsRequest",
+	apiKeyPushTelemetry:                "PushTelemetryRequest",
 }
 
 func TestAllocateBodyProtocolVersions(t *testing.T) {
@@ -410,7 +320,7 @@ func TestAllocateBodyProtocolVersions(t *testing.T) {
 					t.Skipf("apikey %d is not implemented", key)
 				}
 				t.Logf("Testing %s V%d", reflect.TypeOf(req), version)
-				resp := allocateResponseBody(req)
+				resp := allocateResponseBody(req.key(), req.version())
 				assert.NotNil(t, resp, fmt.Sprintf("%s has no matching response type in allocateResponseBody", reflect.TypeOf(req)))
 				assert.Equal(t, req.isValidVersion(), resp.isValidVersion(), fmt.Sprintf("%s isValidVersion should match %s", reflect.TypeOf(req), reflect.TypeOf(resp)))
 				assert.Equal(t, req.requiredVersion(), resp.requiredVersion(), fmt.Sprintf("%s requiredVersion should match %s", reflect.TypeOf(req), reflect.TypeOf(resp)))
@@ -47,6 +47,10 @@ Broker related metrics:
 	|                                                         |            | https://kafka.apache.org/protocol.html#protocol_api_keys      |                                        |
 	| protocol-requests-rate-<api-key>-for-broker-<broker-id> | meter      | Number of packets sent to the brokers by api-key for a given  |
 	|                                                         |            | broker                                                        |
+	| tls-certificate-expiry                                  | gauge      | Expiry (unix seconds) of the client certificate used by the   |
+	|                                                         |            | latest TLS connection to any broker                           |
+	| tls-certificate-expiry-for-broker-<broker-id>           | gauge      | Expiry (unix seconds) of the client certificate used by the   |
+	|                                                         |            | TLS connection to a given broker                              |
 	+---------------------------------------------------------+------------+---------------------------------------------------------------+
 
 Note that we do not gather specific metrics for seed brokers but they are part of the "all brokers" metrics.
@@ -64,6 +68,18 @@ Producer related metrics:
 	| records-per-request-for-topic-<topic>     | histogram  | Distribution of the number of records sent per request for a given topic             |
 	| compression-ratio                         | histogram  | Distribution of the compression ratio times 100 of record batches for all topics     |
 	| compression-ratio-for-topic-<topic>       | histogram  | Distribution of the compression ratio times 100 of record batches for a given topic  |
+	| record-queue-time-in-ms                   | histogram  | Distribution of the time in ms record batches were queued before being sent          |
+	| record-retry-rate                         | meter      | Records/second retried for all topics                                                |
+	| record-retry-rate-for-topic-<topic>       | meter      | Records/second retried for a given topic                                             |
+	| record-error-rate                         | meter      | Records/second that failed to be produced for all topics                             |
+	| record-error-rate-for-topic-<topic>       | meter      | Records/second that failed to be produced for a given topic                          |
+	| retry-buffer-length                       | gauge      | Number of messages in the retry buffer                                               |
+	| retry-buffer-bytes                        | gauge      | Size in bytes of the messages in the retry buffer                                    |
+	| buffer-available-bytes                    | gauge      | Bytes left in the retry buffer when Producer.Retry.MaxBufferBytes is set             |
+	| produce-throttle-time-in-ms               | histogram  | Distribution of the produce throttle time in ms for all brokers                      |
+	| produce-throttle-time-in-ms-for-broker-   | histogram  | Distribution of the produce throttle time in ms for a given broker                   |
+	|   <broker>                                |            |                                                                                      |
+	| batches-in-flight-for-broker-<broker>     | counter    | Number of record batches sent to a given broker awaiting a response                  |
 	+-------------------------------------------+------------+--------------------------------------------------------------------------------------+
 
 Consumer related metrics:
@@ -76,11 +92,29 @@ Consumer related metrics:
 	| consumer-fetch-rate-for-broker-<broker>   | meter      | Fetch requests/second sent to a given broker                                         |
 	| consumer-fetch-rate-for-topic-<topic>     | meter      | Fetch requests/second sent for a given topic                                         |
 	| consumer-fetch-response-size              | histogram  | Distribution of the fetch response size in bytes                                     |
+	| fetch-latency-in-ms                       | histogram  | Distribution of the fetch request latency in ms for all brokers                      |
+	| fetch-latency-in-ms-for-broker-<broker>   | histogram  | Distribution of the fetch request latency in ms for a given broker                   |
+	| bytes-consumed-rate                       | meter      | Bytes/second of message keys and values consumed from all topics                     |
+	| bytes-consumed-rate-for-topic-<topic>     | meter      | Bytes/second of message keys and values consumed from a given topic                  |
+	| records-lag-for-topic-<topic>-for-        | gauge      | Number of messages the consumer of a given partition is behind the high watermark,   |
+	|   partition-<partition>                   |            | or the last stable offset for read committed consumers                               |
+	| records-lead-for-topic-<topic>-for-       | gauge      | Number of messages the consumer of a given partition is ahead of the log start       |
+	|   partition-<partition>                   |            | offset (requires Kafka 0.11)                                                         |
+	| records-lag-max                           | gauge      | Maximum records-lag of the partitions being consumed                                 |
+	| records-lead-min                          | gauge      | Minimum records-lead of the partitions being consumed                                |
+	| consumer-paused-partitions                | counter    | Number of paused partitions                                                          |
 	| consumer-group-join-total-<GroupID>       | counter    | Total count of consumer group join attempts                                          |
 	| consumer-group-join-failed-<GroupID>      | counter    | Total count of consumer group join failures                                          |
 	| consumer-group-sync-total-<GroupID>       | counter    | Total count of consumer group sync attempts                                          |
 	| consumer-group-sync-failed-<GroupID>      | counter    | Total count of consumer group sync failures                                          |
 	+-------------------------------------------+------------+--------------------------------------------------------------------------------------+
+
+Alternatively, the metrics can be reported to another metrics backend by setting Config.MetricsSink, e.g. to the
+Prometheus and OpenTelemetry sinks of the github.com/IBM/sarama/metrics/prometheus and
+github.com/IBM/sarama/metrics/otel modules. The broker, topic, partition, consumer group and api key suffixes above are then
+labels of the metrics (e.g. request-rate{broker="1"}), the "all brokers" and "all topics" metrics are not reported,
+seed brokers are reported with their -1 id and consumer-fetch-rate-for-topic-<topic> is reported as
+consumer-topic-fetch-rate{topic="<topic>"}.
 */
 package sarama
 
@@ -92,7 +126,8 @@ import (
 var (
 	// Logger is the instance of a StdLogger interface that Sarama writes connection
 	// management events to. By default it is set to discard all log messages via io.Discard,
-	// but you can set it to redirect wherever you want.
+	// but you can set it to redirect wherever you want. Components whose Config.Logger is set
+	// log to that structured logger instead.
 	Logger StdLogger = log.New(io.Discard, "[Sarama] ", log.LstdFlags)
 
 	// PanicHandler is called for recovering from panics spawned internally to the library (and thus
@@ -135,5 +170,6 @@ func (d *debugLogger) Println(v ...interface{}) {
 // DebugLogger is the instance of a StdLogger that Sarama writes more verbose
 // debug information to. By default it is set to redirect all debug to the
 // default Logger above, but you can optionally set it to another StdLogger
-// instance to (e.g.,) discard debug information
+// instance to (e.g.,) discard debug information. Components whose
+// Config.Logger is set log their debug information to it instead.
 var DebugLogger StdLogger = &debugLogger{}
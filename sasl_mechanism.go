@@ -0,0 +1,239 @@
+package sarama
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// SASLMechanismClient implements the client side of a SASL mechanism carried
+// by SaslAuthenticate requests. A new client is created for every
+// authentication exchange, including re-authentications, and is driven as
+// follows: the response returned by Start is sent to the broker, then, for as
+// long as Done returns false, the challenge returned by the broker is passed
+// to Step and the response it returns is sent in turn.
+//
+// If the client also implements io.Closer, Close is called once the exchange
+// has ended, whether it succeeded or not.
+type SASLMechanismClient interface {
+	// Start returns the initial client response.
+	Start() ([]byte, error)
+	// Step processes a challenge sent by the broker and returns the next
+	// client response.
+	Step(challenge []byte) ([]byte, error)
+	// Done returns true once the exchange has completed successfully and no
+	// further response needs to be sent. It is checked before every response
+	// is sent, including the initial one.
+	Done() bool
+}
+
+// SASLSessionLifetimeProvider may be implemented by a SASLMechanismClient
+// whose credentials expire, such as short-lived tokens. When SessionLifetime
+// returns a positive duration shorter than the session lifetime returned by
+// the broker, the session is re-authenticated (KIP-368) based on it instead.
+type SASLSessionLifetimeProvider interface {
+	SessionLifetime() time.Duration
+}
+
+// runSASLExchange drives mechanism through an authentication exchange with
+// broker, using authSendReceiver to send each client response and receive the
+// challenge that follows it. It returns the last response received from the
+// broker.
+func runSASLExchange(broker *Broker, mechanism SASLMechanismClient, authSendReceiver func(authBytes []byte) (*SaslAuthenticateResponse, error)) (*SaslAuthenticateResponse, error) {
+	log := broker.logger()
+	if closer, ok := mechanism.(io.Closer); ok {
+		defer func() {
+			if err := closer.Close(); err != nil {
+				log.Warn("Error while closing SASL mechanism client", "err", err)
+			}
+		}()
+	}
+
+	msg, err := mechanism.Start()
+	if err != nil {
+		return nil, err
+	}
+
+	var res *SaslAuthenticateResponse
+	for !mechanism.Done() {
+		res, err = authSendReceiver(msg)
+		if err != nil {
+			return nil, err
+		}
+
+		msg, err = mechanism.Step(res.SaslAuthBytes)
+		if err != nil {
+			log.Error("SASL authentication failed", "err", err)
+			return nil, err
+		}
+	}
+
+	log.Debug("SASL authentication succeeded")
+	return res, nil
+}
+
+// newSASLMechanismClient returns the client for the configured mechanism,
+// either created by Net.SASL.MechanismClientFunc or one of the built-in
+// implementations.
+func (b *Broker) newSASLMechanismClient() (SASLMechanismClient, error) {
+	sasl := &b.conf.Net.SASL
+	if sasl.MechanismClientFunc != nil {
+		return sasl.MechanismClientFunc(b)
+	}
+
+	switch sasl.Mechanism {
+	case SASLTypeGSSAPI:
+		b.kerberosAuthenticator.Config = &sasl.GSSAPI
+		if b.kerberosAuthenticator.NewKerberosClientFunc == nil {
+			b.kerberosAuthenticator.NewKerberosClientFunc = NewKerberosClient
+		}
+		return &gssapiSASLClient{auth: &b.kerberosAuthenticator, broker: b}, nil
+	case SASLTypeOAuth:
+		return &oauthBearerSASLClient{provider: sasl.TokenProvider}, nil
+	case SASLTypeSCRAMSHA256, SASLTypeSCRAMSHA512:
+		return &scramSASLClient{
+			client:  sasl.SCRAMClientGeneratorFunc(),
+			user:    sasl.User,
+			pass:    sasl.Password,
+			authzID: sasl.SCRAMAuthzID,
+		}, nil
+	default:
+		return &plainSASLClient{
+			authzID: sasl.AuthIdentity,
+			user:    sasl.User,
+			pass:    sasl.Password,
+		}, nil
+	}
+}
+
+// plainSASLClient implements SASL/PLAIN (RFC 4616), see
+// sendAndReceiveSASLPlainAuthV0 for the message format.
+type plainSASLClient struct {
+	authzID, user, pass string
+	done                bool
+}
+
+func (c *plainSASLClient) Start() ([]byte, error) {
+	return []byte(c.authzID + "\x00" + c.user + "\x00" + c.pass), nil
+}
+
+func (c *plainSASLClient) Step([]byte) ([]byte, error) {
+	c.done = true
+	return nil, nil
+}
+
+func (c *plainSASLClient) Done() bool {
+	return c.done
+}
+
+// scramSASLClient adapts a SCRAMClient to SASLMechanismClient.
+type scramSASLClient struct {
+	client              SCRAMClient
+	user, pass, authzID string
+}
+
+func (c *scramSASLClient) Start() ([]byte, error) {
+	if err := c.client.Begin(c.user, c.pass, c.authzID); err != nil {
+		return nil, fmt.Errorf("failed to start SCRAM exchange with the server: %w", err)
+	}
+
+	msg, err := c.client.Step("")
+	if err != nil {
+		return nil, fmt.Errorf("failed to advance the SCRAM exchange: %w", err)
+	}
+	return []byte(msg), nil
+}
+
+func (c *scramSASLClient) Step(challenge []byte) ([]byte, error) {
+	msg, err := c.client.Step(string(challenge))
+	return []byte(msg), err
+}
+
+func (c *scramSASLClient) Done() bool {
+	return c.client.Done()
+}
+
+// oauthBearerSASLClient implements SASL/OAUTHBEARER as described by KIP-255
+// https://cwiki.apache.org/confluence/pages/viewpage.action?pageId=75968876
+type oauthBearerSASLClient struct {
+	provider AccessTokenProvider
+	aborted  bool
+	done     bool
+}
+
+func (c *oauthBearerSASLClient) Start() ([]byte, error) {
+	token, err := c.provider.Token()
+	if err != nil {
+		return nil, err
+	}
+	return buildClientFirstMessage(token)
+}
+
+func (c *oauthBearerSASLClient) Step(challenge []byte) ([]byte, error) {
+	if len(challenge) > 0 && !c.aborted {
+		// Abort the token exchange. The broker returns the failure code.
+		c.aborted = true
+		return []byte(`\x01`), nil
+	}
+	c.done = true
+	return nil, nil
+}
+
+func (c *oauthBearerSASLClient) Done() bool {
+	return c.done
+}
+
+// gssapiSASLClient implements SASL/GSSAPI on top of GSSAPIKerberosAuth.
+type gssapiSASLClient struct {
+	auth      *GSSAPIKerberosAuth
+	broker    *Broker
+	client    KerberosClient
+	principal string
+	done      bool
+}
+
+func (c *gssapiSASLClient) Start() ([]byte, error) {
+	client, err := c.auth.newKerberosClient()
+	if err != nil {
+		c.broker.logger().Error("Kerberos client initialization error", "err", err)
+		return nil, err
+	}
+	c.client = client
+
+	ticket, err := c.auth.Login(client, c.auth.spn(c.broker))
+	if err != nil {
+		return nil, err
+	}
+	c.principal = strings.Join(ticket.SName.NameString, "/") + "@" + ticket.Realm
+
+	return c.initSecContext(nil)
+}
+
+func (c *gssapiSASLClient) Step(challenge []byte) ([]byte, error) {
+	// the final token has already been sent
+	if c.auth.step == GSS_API_FINISH {
+		c.done = true
+		return nil, nil
+	}
+	return c.initSecContext(challenge)
+}
+
+func (c *gssapiSASLClient) initSecContext(challenge []byte) ([]byte, error) {
+	token, err := c.auth.initSecContext(c.client, challenge)
+	if err != nil {
+		c.broker.logger().Error("SASL Kerberos init error", "principal", c.principal, "err", err)
+	}
+	return token, err
+}
+
+func (c *gssapiSASLClient) Done() bool {
+	return c.done
+}
+
+func (c *gssapiSASLClient) Close() error {
+	if c.client != nil {
+		c.client.Destroy()
+	}
+	return nil
+}
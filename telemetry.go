@@ -0,0 +1,288 @@
+package sarama
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rcrowley/go-metrics"
+)
+
+const (
+	// defaultTelemetryPushInterval is the push interval used when the broker
+	// does not set one, the default of its `interval.ms` subscription config.
+	defaultTelemetryPushInterval = 5 * time.Minute
+	// telemetryRetryBackoff is how long to wait before getting the telemetry
+	// subscriptions again after a failure.
+	telemetryRetryBackoff = 30 * time.Second
+)
+
+var errTelemetryUnsupported = errors.New("kafka: broker does not support client telemetry")
+
+// telemetryReporter pushes the metrics of the client's MetricRegistry
+// requested by the broker's client metrics subscriptions (KIP-714). It
+// sticks to a single broker, getting the subscriptions again whenever that
+// broker or the subscriptions change.
+type telemetryReporter struct {
+	client         *client
+	log            *logger
+	closer, closed chan none
+
+	broker           *Broker
+	subscription     *GetTelemetrySubscriptionsResponse
+	clientInstanceId Uuid
+
+	start    time.Time        // start of the cumulative sums
+	lastPush time.Time        // start of the delta sums
+	last     map[string]int64 // sums at the last push, by registry name
+}
+
+func newTelemetryReporter(client *client) *telemetryReporter {
+	now := time.Now()
+	return &telemetryReporter{
+		client:   client,
+		log:      client.log,
+		closer:   make(chan none),
+		closed:   make(chan none),
+		start:    now,
+		lastPush: now,
+		last:     make(map[string]int64),
+	}
+}
+
+func (r *telemetryReporter) run() {
+	defer close(r.closed)
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			next, ok := r.report()
+			if !ok {
+				return
+			}
+			timer.Reset(next)
+		case <-r.closer:
+			r.terminate()
+			return
+		}
+	}
+}
+
+// report gets the subscriptions or pushes the metrics, depending on the
+// state of the reporter, and returns when to report next, or false if the
+// reporting must stop.
+func (r *telemetryReporter) report() (time.Duration, bool) {
+	if r.subscription == nil {
+		if err := r.subscribe(); errors.Is(err, errTelemetryUnsupported) {
+			r.log.Info("client/telemetry broker does not support client telemetry, disabling it", "broker", r.broker.ID())
+			return 0, false
+		} else if err != nil {
+			r.log.Warn("client/telemetry failed to get the telemetry subscriptions", "err", err)
+			r.broker = nil
+			return telemetryRetryBackoff, true
+		}
+		if len(r.subscription.RequestedMetrics) == 0 {
+			// no metrics are requested, check for new subscriptions later
+			r.log.Debug("client/telemetry no metrics requested by the broker", "broker", r.broker.ID())
+			interval := r.pushInterval()
+			r.subscription = nil
+			return interval, true
+		}
+		// spread the first pushes of the clients over the push interval
+		return time.Duration((0.5 + rand.Float64()) * float64(r.pushInterval())), true
+	}
+
+	err := r.push(false)
+	var kerr KError
+	switch {
+	case err == nil:
+	case errors.As(err, &kerr) && (kerr == ErrUnknownSubscriptionId || kerr == ErrUnsupportedCompressionType):
+		r.log.Debug("client/telemetry telemetry subscription changed", "broker", r.broker.ID(), "err", err)
+		r.subscription = nil
+		return 0, true
+	case errors.As(err, &kerr):
+		r.log.Warn("client/telemetry failed to push the client metrics", "broker", r.broker.ID(), "err", err)
+	default:
+		r.log.Warn("client/telemetry failed to push the client metrics", "broker", r.broker.ID(), "err", err)
+		r.broker = nil
+		r.subscription = nil
+		return telemetryRetryBackoff, true
+	}
+	return r.pushInterval(), true
+}
+
+// terminate pushes the metrics a last time, telling the broker the client
+// is closing.
+func (r *telemetryReporter) terminate() {
+	if r.broker == nil || r.subscription == nil || len(r.subscription.RequestedMetrics) == 0 {
+		return
+	}
+	if err := r.push(true); err != nil {
+		r.log.Warn("client/telemetry failed to push the final client metrics", "broker", r.broker.ID(), "err", err)
+	}
+}
+
+func (r *telemetryReporter) subscribe() error {
+	if r.broker == nil {
+		r.broker = r.client.LeastLoadedBroker()
+		if r.broker == nil {
+			return ErrOutOfBrokers
+		}
+	}
+	if _, err := r.broker.Connected(); err != nil {
+		return err
+	}
+	if r.client.conf.ApiVersionsRequest && !r.broker.supportsAPI(apiKeyGetTelemetrySubscriptions) {
+		return errTelemetryUnsupported
+	}
+
+	response, err := r.broker.GetTelemetrySubscriptions(&GetTelemetrySubscriptionsRequest{
+		ClientInstanceId: r.clientInstanceId,
+	})
+	if err != nil {
+		return err
+	}
+	if !errors.Is(response.Err, ErrNoError) {
+		return response.Err
+	}
+
+	r.clientInstanceId = response.ClientInstanceId
+	r.subscription = response
+	r.log.Debug("client/telemetry got telemetry subscription", "broker", r.broker.ID(),
+		"subscription", response.SubscriptionId, "metrics", response.RequestedMetrics)
+	return nil
+}
+
+func (r *telemetryReporter) pushInterval() time.Duration {
+	if r.subscription == nil || r.subscription.PushInterval <= 0 {
+		return defaultTelemetryPushInterval
+	}
+	return r.subscription.PushInterval
+}
+
+func (r *telemetryReporter) push(terminating bool) error {
+	subscription := r.subscription
+	now := time.Now()
+
+	points, sums := r.collect(subscription.RequestedMetrics, subscription.DeltaTemporality)
+	start := r.start
+	if subscription.DeltaTemporality {
+		start = r.lastPush
+	}
+	payload, encoded := encodeOTLPMetrics(points, start, now, subscription.DeltaTemporality, int(subscription.TelemetryMaxBytes))
+	if encoded < len(points) {
+		r.log.Warn("client/telemetry client metrics larger than the broker's limit, some were dropped",
+			"broker", r.broker.ID(), "dropped", len(points)-encoded)
+	}
+	codec := CompressionNone
+	if len(subscription.AcceptedCompressionTypes) > 0 {
+		codec = subscription.AcceptedCompressionTypes[0]
+	}
+	if codec != CompressionNone {
+		if compressed, err := compress(codec, CompressionLevelDefault, payload); err == nil {
+			payload = compressed
+		} else {
+			codec = CompressionNone
+		}
+	}
+
+	response, err := r.broker.PushTelemetry(&PushTelemetryRequest{
+		ClientInstanceId: r.clientInstanceId,
+		SubscriptionId:   subscription.SubscriptionId,
+		Terminating:      terminating,
+		CompressionType:  codec,
+		Metrics:          payload,
+	})
+	if err != nil {
+		return err
+	}
+	if !errors.Is(response.Err, ErrNoError) {
+		return response.Err
+	}
+	// the deltas of failed pushes are carried over to the next one
+	r.lastPush = now
+	r.last = sums
+	return nil
+}
+
+// collect returns the data points of the metrics of the registry whose
+// names start with one of the requested prefixes, or of all of them if "*"
+// or "" is requested. The metrics are named
+// after their registry name prefixed with "sarama." and with dots instead of
+// dashes, e.g. sarama.request.rate, with the broker, topic and partition
+// suffixes of their registry name as attributes. Meters are reported as the
+// sums of their counts, histograms as their mean and max, suffixed with .avg
+// and .max. The sums of the meters are returned alongside, to become the
+// start of the delta sums once pushed.
+func (r *telemetryReporter) collect(requested []string, delta bool) ([]otlpDataPoint, map[string]int64) {
+	var names []string
+	values := make(map[string]interface{})
+	r.client.conf.MetricRegistry.Each(func(name string, metric interface{}) {
+		names = append(names, name)
+		values[name] = metric
+	})
+	sort.Strings(names)
+
+	var points []otlpDataPoint
+	sums := make(map[string]int64)
+	add := func(point otlpDataPoint) {
+		if telemetryMetricRequested(point.name, requested) {
+			points = append(points, point)
+		}
+	}
+	for _, name := range names {
+		base, attributes := telemetryMetricName(name)
+		switch metric := values[name].(type) {
+		case metrics.Meter:
+			count := metric.Count()
+			value := count
+			if delta {
+				value -= r.last[name]
+			}
+			sums[name] = count
+			add(otlpDataPoint{name: base, attributes: attributes, sum: true, intValue: value})
+		case metrics.Counter:
+			add(otlpDataPoint{name: base, attributes: attributes, intValue: metric.Count()})
+		case metrics.Gauge:
+			add(otlpDataPoint{name: base, attributes: attributes, intValue: metric.Value()})
+		case metrics.GaugeFloat64:
+			add(otlpDataPoint{name: base, attributes: attributes, isDouble: true, double: metric.Value()})
+		case metrics.Histogram:
+			snapshot := metric.Snapshot()
+			add(otlpDataPoint{name: base + ".avg", attributes: attributes, isDouble: true, double: snapshot.Mean()})
+			add(otlpDataPoint{name: base + ".max", attributes: attributes, intValue: snapshot.Max()})
+		}
+	}
+	return points, sums
+}
+
+// telemetryMetricRequested returns whether the metric name starts with one of
+// the requested prefixes, "*" and "" requesting all the metrics.
+func telemetryMetricRequested(name string, requested []string) bool {
+	for _, prefix := range requested {
+		if prefix == "*" || strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// telemetryMetricName returns the pushed name of the metric registered as
+// name, and the attributes parsed from its broker, topic and partition
+// suffixes.
+func telemetryMetricName(name string) (string, []otlpAttribute) {
+	var attributes []otlpAttribute
+	for _, label := range []string{MetricLabelPartition, MetricLabelTopic, MetricLabelBroker} {
+		suffix := "-for-" + label + "-"
+		if i := strings.LastIndex(name, suffix); i > 0 {
+			attributes = append([]otlpAttribute{{key: label, value: name[i+len(suffix):]}}, attributes...)
+			name = name[:i]
+		}
+	}
+	return "sarama." + strings.ReplaceAll(name, "-", "."), attributes
+}
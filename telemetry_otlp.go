@@ -0,0 +1,135 @@
+package sarama
+
+import (
+	"encoding/binary"
+	"math"
+	"time"
+)
+
+// The client metrics pushed to the brokers (KIP-714) are encoded as an OTLP
+// MetricsData protobuf message, see
+// https://github.com/open-telemetry/opentelemetry-proto/blob/main/opentelemetry/proto/metrics/v1/metrics.proto
+// Only the few messages and fields used by Sarama are encoded, by hand, to
+// avoid depending on the OpenTelemetry modules.
+
+const (
+	protoWireVarint  = 0
+	protoWireFixed64 = 1
+	protoWireBytes   = 2
+)
+
+const (
+	otlpTemporalityDelta      = 1
+	otlpTemporalityCumulative = 2
+)
+
+// otlpDataPoint is a metric data point to encode as an OTLP Gauge, or as a
+// monotonic Sum if sum is true.
+type otlpDataPoint struct {
+	name       string
+	attributes []otlpAttribute
+	sum        bool
+	isDouble   bool
+	intValue   int64
+	double     float64
+}
+
+type otlpAttribute struct {
+	key, value string
+}
+
+// encodeOTLPMetrics encodes the data points as an OTLP MetricsData message
+// whose sums started at start, skipping the data points that would make it
+// larger than maxBytes if positive. It returns the message and the number of
+// data points encoded.
+func encodeOTLPMetrics(points []otlpDataPoint, start, now time.Time, delta bool, maxBytes int) ([]byte, int) {
+	temporality := uint64(otlpTemporalityCumulative)
+	if delta {
+		temporality = otlpTemporalityDelta
+	}
+
+	// InstrumentationScope: name = 1
+	scope := protoAppendString(nil, 1, "sarama")
+	// ScopeMetrics: scope = 1, metrics = 2
+	scopeMetrics := protoAppendBytes(nil, 1, scope)
+	// the overhead of the enclosing messages, assuming their lengths fit in
+	// 3 bytes varints (i.e. messages smaller than 2 MiB)
+	const overhead = 2 * (1 + 3)
+
+	encoded := 0
+	for _, point := range points {
+		metric := encodeOTLPMetric(point, start, now, temporality)
+		size := len(scopeMetrics) + 1 + protoSizeVarint(uint64(len(metric))) + len(metric)
+		if maxBytes > 0 && size+overhead > maxBytes {
+			continue
+		}
+		scopeMetrics = protoAppendBytes(scopeMetrics, 2, metric)
+		encoded++
+	}
+
+	// ResourceMetrics: resource = 1, scope_metrics = 2
+	resourceMetrics := protoAppendBytes(nil, 2, scopeMetrics)
+	// MetricsData: resource_metrics = 1
+	return protoAppendBytes(nil, 1, resourceMetrics), encoded
+}
+
+func encodeOTLPMetric(point otlpDataPoint, start, now time.Time, temporality uint64) []byte {
+	// NumberDataPoint: start_time_unix_nano = 2, time_unix_nano = 3,
+	// as_double = 4, as_int = 6, attributes = 7
+	var dataPoint []byte
+	if point.sum {
+		dataPoint = protoAppendFixed64(dataPoint, 2, uint64(start.UnixNano()))
+	}
+	dataPoint = protoAppendFixed64(dataPoint, 3, uint64(now.UnixNano()))
+	if point.isDouble {
+		dataPoint = protoAppendFixed64(dataPoint, 4, math.Float64bits(point.double))
+	} else {
+		dataPoint = protoAppendFixed64(dataPoint, 6, uint64(point.intValue))
+	}
+	for _, attribute := range point.attributes {
+		// AnyValue: string_value = 1
+		value := protoAppendString(nil, 1, attribute.value)
+		// KeyValue: key = 1, value = 2
+		keyValue := protoAppendString(nil, 1, attribute.key)
+		keyValue = protoAppendBytes(keyValue, 2, value)
+		dataPoint = protoAppendBytes(dataPoint, 7, keyValue)
+	}
+
+	// Metric: name = 1, gauge = 5, sum = 7
+	metric := protoAppendString(nil, 1, point.name)
+	if point.sum {
+		// Sum: data_points = 1, aggregation_temporality = 2, is_monotonic = 3
+		sum := protoAppendBytes(nil, 1, dataPoint)
+		sum = protoAppendVarint(sum, 2, temporality)
+		sum = protoAppendVarint(sum, 3, 1)
+		return protoAppendBytes(metric, 7, sum)
+	}
+	// Gauge: data_points = 1
+	return protoAppendBytes(metric, 5, protoAppendBytes(nil, 1, dataPoint))
+}
+
+func protoAppendTag(b []byte, field int, wireType int) []byte {
+	return binary.AppendUvarint(b, uint64(field)<<3|uint64(wireType))
+}
+
+func protoAppendVarint(b []byte, field int, v uint64) []byte {
+	return binary.AppendUvarint(protoAppendTag(b, field, protoWireVarint), v)
+}
+
+func protoAppendFixed64(b []byte, field int, v uint64) []byte {
+	return binary.LittleEndian.AppendUint64(protoAppendTag(b, field, protoWireFixed64), v)
+}
+
+func protoAppendBytes(b []byte, field int, v []byte) []byte {
+	b = binary.AppendUvarint(protoAppendTag(b, field, protoWireBytes), uint64(len(v)))
+	return append(b, v...)
+}
+
+func protoAppendString(b []byte, field int, v string) []byte {
+	b = binary.AppendUvarint(protoAppendTag(b, field, protoWireBytes), uint64(len(v)))
+	return append(b, v...)
+}
+
+func protoSizeVarint(v uint64) int {
+	return len(binary.AppendUvarint(nil, v))
+}
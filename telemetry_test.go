@@ -0,0 +1,192 @@
+//go:build !functional
+
+package sarama
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var otlpGaugeMetricsData = []byte{
+	0x0a, 61, // MetricsData.resource_metrics
+	0x12, 59, // ResourceMetrics.scope_metrics
+	0x0a, 8, // ScopeMetrics.scope
+	0x0a, 6, 's', 'a', 'r', 'a', 'm', 'a', // InstrumentationScope.name
+	0x12, 47, // ScopeMetrics.metrics
+	0x0a, 8, 's', 'a', 'r', 'a', 'm', 'a', '.', 'x', // Metric.name
+	0x2a, 35, // Metric.gauge
+	0x0a, 33, // Gauge.data_points
+	0x19, 1, 0, 0, 0, 0, 0, 0, 0, // NumberDataPoint.time_unix_nano
+	0x31, 5, 0, 0, 0, 0, 0, 0, 0, // NumberDataPoint.as_int
+	0x3a, 13, // NumberDataPoint.attributes
+	0x0a, 6, 'b', 'r', 'o', 'k', 'e', 'r', // KeyValue.key
+	0x12, 3, // KeyValue.value
+	0x0a, 1, '1', // AnyValue.string_value
+}
+
+func TestEncodeOTLPMetrics(t *testing.T) {
+	points := []otlpDataPoint{{
+		name:       "sarama.x",
+		attributes: []otlpAttribute{{key: "broker", value: "1"}},
+		intValue:   5,
+	}}
+
+	payload, encoded := encodeOTLPMetrics(points, time.Unix(0, 0), time.Unix(0, 1), false, 0)
+	if encoded != 1 || !bytes.Equal(payload, otlpGaugeMetricsData) {
+		t.Errorf("Unexpected payload of %d data points:\n%v\nexpected:\n%v", encoded, payload, otlpGaugeMetricsData)
+	}
+
+	if _, encoded := encodeOTLPMetrics(points, time.Unix(0, 0), time.Unix(0, 1), false, 50); encoded != 0 {
+		t.Errorf("Expected the data point larger than the limit to be dropped, got %d data points", encoded)
+	}
+}
+
+func TestTelemetryMetricName(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		expected   string
+		attributes []otlpAttribute
+	}{
+		{"request-rate", "sarama.request.rate", nil},
+		{"request-rate-for-broker-1", "sarama.request.rate", []otlpAttribute{{"broker", "1"}}},
+		{
+			"records-lag-for-topic-my_topic-for-partition-3", "sarama.records.lag",
+			[]otlpAttribute{{"topic", "my_topic"}, {"partition", "3"}},
+		},
+	} {
+		name, attributes := telemetryMetricName(tc.name)
+		if name != tc.expected || !reflect.DeepEqual(attributes, tc.attributes) {
+			t.Errorf("%s: expected %s %v, got %s %v", tc.name, tc.expected, tc.attributes, name, attributes)
+		}
+	}
+}
+
+func TestTelemetryCollectRequestedMetrics(t *testing.T) {
+	conf := NewTestConfig()
+	getOrRegisterAggregateMeter("request-rate", conf.MetricRegistry).Mark(1)
+	getOrRegisterAggregateCounter("requests-in-flight", conf.MetricRegistry).Inc(2)
+	reporter := newTelemetryReporter(&client{conf: conf})
+
+	for _, tc := range []struct {
+		requested []string
+		expected  []string
+	}{
+		{nil, nil},
+		{[]string{"sarama.request.rate"}, []string{"sarama.request.rate"}},
+		{[]string{"sarama.requests"}, []string{"sarama.requests.in.flight"}},
+		{[]string{"*"}, []string{"sarama.request.rate", "sarama.requests.in.flight"}},
+		{[]string{""}, []string{"sarama.request.rate", "sarama.requests.in.flight"}},
+	} {
+		var names []string
+		points, _ := reporter.collect(tc.requested, false)
+		for _, point := range points {
+			names = append(names, point.name)
+		}
+		if !reflect.DeepEqual(names, tc.expected) {
+			t.Errorf("%q: expected the metrics %v, got %v", tc.requested, tc.expected, names)
+		}
+	}
+}
+
+func TestTelemetryFailedPushKeepsDeltas(t *testing.T) {
+	seedBroker := NewMockBroker(t, 1)
+	defer seedBroker.Close()
+	seedBroker.SetHandlerByMap(map[string]MockResponse{
+		"PushTelemetryRequest": NewMockPushTelemetryResponse(t).SetError(ErrThrottlingQuotaExceeded),
+	})
+
+	conf := NewTestConfig()
+	conf.Version = V3_7_0_0
+	meter := getOrRegisterAggregateMeter("record-send-rate", conf.MetricRegistry)
+	meter.Mark(5)
+	reporter := newTelemetryReporter(&client{conf: conf})
+	reporter.broker = NewBroker(seedBroker.Addr())
+	if err := reporter.broker.Open(conf); err != nil {
+		t.Fatal(err)
+	}
+	defer safeClose(t, reporter.broker)
+	reporter.subscription = &GetTelemetrySubscriptionsResponse{
+		DeltaTemporality:  true,
+		RequestedMetrics:  []string{"sarama.record.send.rate"},
+		TelemetryMaxBytes: 1024 * 1024,
+	}
+	lastPush := reporter.lastPush
+
+	if err := reporter.push(false); !errors.Is(err, ErrThrottlingQuotaExceeded) {
+		t.Fatalf("Expected ErrThrottlingQuotaExceeded, got %v", err)
+	}
+	if reporter.lastPush != lastPush || reporter.last["record-send-rate"] != 0 {
+		t.Errorf("Expected the deltas to be kept after a failed push, got %v since %v", reporter.last, reporter.lastPush)
+	}
+
+	// the next push carries the increments of the failed one
+	meter.Mark(2)
+	seedBroker.SetHandlerByMap(map[string]MockResponse{
+		"PushTelemetryRequest": NewMockPushTelemetryResponse(t),
+	})
+	if err := reporter.push(false); err != nil {
+		t.Fatal(err)
+	}
+	if reporter.lastPush == lastPush || reporter.last["record-send-rate"] != 7 {
+		t.Errorf("Expected the deltas to start over after a successful push, got %v since %v", reporter.last, reporter.lastPush)
+	}
+}
+
+func TestClientTelemetry(t *testing.T) {
+	seedBroker := NewMockBroker(t, 1)
+	defer seedBroker.Close()
+
+	seedBroker.SetHandlerByMap(map[string]MockResponse{
+		"MetadataRequest": NewMockMetadataResponse(t).
+			SetBroker(seedBroker.Addr(), seedBroker.BrokerID()),
+		"GetTelemetrySubscriptionsRequest": NewMockGetTelemetrySubscriptionsResponse(t).
+			SetClientInstanceId(Uuid{15: 1}).
+			SetSubscription(7, 10*time.Millisecond, true, "sarama.request"),
+		"PushTelemetryRequest": NewMockPushTelemetryResponse(t),
+	})
+
+	conf := NewTestConfig()
+	conf.Version = V3_7_0_0
+	conf.Telemetry.Enable = true
+	client, err := NewClient([]string{seedBroker.Addr()}, conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pushes := func() []*PushTelemetryRequest {
+		var requests []*PushTelemetryRequest
+		for _, rr := range seedBroker.History() {
+			if request, ok := rr.Request.(*PushTelemetryRequest); ok {
+				requests = append(requests, request)
+			}
+		}
+		return requests
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for len(pushes()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	safeClose(t, client)
+
+	requests := pushes()
+	if len(requests) < 3 {
+		t.Fatalf("Expected at least 3 pushes, got %d", len(requests))
+	}
+	for i, request := range requests {
+		if request.ClientInstanceId != (Uuid{15: 1}) || request.SubscriptionId != 7 {
+			t.Errorf("Unexpected client instance id %v or subscription %d", request.ClientInstanceId, request.SubscriptionId)
+		}
+		if request.Terminating != (i == len(requests)-1) {
+			t.Errorf("Push %d: unexpected terminating %v", i, request.Terminating)
+		}
+		if !bytes.Contains(request.Metrics, []byte("sarama.request.rate")) {
+			t.Errorf("Push %d: expected sarama.request.rate to be pushed", i)
+		}
+		if bytes.Contains(request.Metrics, []byte("sarama.response")) {
+			t.Errorf("Push %d: expected only the requested metrics to be pushed", i)
+		}
+	}
+}
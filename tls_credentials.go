@@ -0,0 +1,194 @@
+package sarama
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// TLSCredentials contains the TLS material used to establish a broker
+// connection.
+type TLSCredentials struct {
+	// Certificate is the client certificate presented to the broker, or nil.
+	Certificate *tls.Certificate
+	// RootCAs is the pool of CAs used to verify the broker certificate, or
+	// nil to use Net.TLS.Config.RootCAs.
+	RootCAs *x509.CertPool
+}
+
+// TLSCredentialSource supplies TLS credentials that may change over time,
+// such as short-lived client certificates. It is called every time a broker
+// connection is established, so new connections always use the current
+// material. Implementations must be safe for concurrent use.
+type TLSCredentialSource interface {
+	Credentials() (*TLSCredentials, error)
+}
+
+// TLSCredentialsFunc is an adapter to use an ordinary function as a
+// TLSCredentialSource.
+type TLSCredentialsFunc func() (*TLSCredentials, error)
+
+// Credentials calls f.
+func (f TLSCredentialsFunc) Credentials() (*TLSCredentials, error) {
+	return f()
+}
+
+// FileTLSCredentialSource is a TLSCredentialSource reading PEM encoded
+// files. The files are reloaded whenever their size or modification time
+// changes. If a reload fails, for instance because the certificate and key
+// are being replaced one after the other, the previously loaded credentials
+// are returned until the files can be loaded again.
+type FileTLSCredentialSource struct {
+	// Logger receives the reload failures and successes (defaults to the
+	// global Logger and DebugLogger).
+	Logger StructuredLogger
+
+	certFile, keyFile, caFile string
+
+	lock        sync.Mutex
+	fingerprint [3]fileFingerprint
+	credentials *TLSCredentials
+}
+
+type fileFingerprint struct {
+	size    int64
+	modTime time.Time
+}
+
+// NewFileTLSCredentialSource creates a FileTLSCredentialSource for the given
+// client certificate, private key and CA bundle files. Either certFile and
+// keyFile, or caFile may be empty. The files are loaded once to verify them.
+func NewFileTLSCredentialSource(certFile, keyFile, caFile string) (*FileTLSCredentialSource, error) {
+	if (certFile == "") != (keyFile == "") {
+		return nil, ConfigurationError("both a certificate and a key file must be provided")
+	}
+	if certFile == "" && caFile == "" {
+		return nil, ConfigurationError("a certificate or a CA file must be provided")
+	}
+	s := &FileTLSCredentialSource{certFile: certFile, keyFile: keyFile, caFile: caFile}
+	fingerprint, err := s.stat()
+	if err != nil {
+		return nil, err
+	}
+	if s.credentials, err = s.load(); err != nil {
+		return nil, err
+	}
+	s.fingerprint = fingerprint
+	return s, nil
+}
+
+// Credentials returns the credentials loaded from the files, reloading them
+// first if they changed.
+func (s *FileTLSCredentialSource) Credentials() (*TLSCredentials, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	log := &logger{base: s.Logger}
+	fingerprint, err := s.stat()
+	if err != nil {
+		log.Warn("Failed to check TLS credential files, using the previous credentials", "err", err)
+		return s.credentials, nil
+	}
+	if fingerprint == s.fingerprint {
+		return s.credentials, nil
+	}
+
+	credentials, err := s.load()
+	if err != nil {
+		log.Warn("Failed to reload TLS credentials, using the previous credentials", "err", err)
+		return s.credentials, nil
+	}
+	log.Debug("Reloaded TLS credentials")
+	s.credentials = credentials
+	s.fingerprint = fingerprint
+	return s.credentials, nil
+}
+
+func (s *FileTLSCredentialSource) stat() (fingerprint [3]fileFingerprint, err error) {
+	for i, name := range []string{s.certFile, s.keyFile, s.caFile} {
+		if name == "" {
+			continue
+		}
+		info, err := os.Stat(name)
+		if err != nil {
+			return fingerprint, err
+		}
+		fingerprint[i] = fileFingerprint{size: info.Size(), modTime: info.ModTime()}
+	}
+	return fingerprint, nil
+}
+
+func (s *FileTLSCredentialSource) load() (*TLSCredentials, error) {
+	credentials := &TLSCredentials{}
+	if s.certFile != "" {
+		cert, err := tls.LoadX509KeyPair(s.certFile, s.keyFile)
+		if err != nil {
+			return nil, err
+		}
+		credentials.Certificate = &cert
+	}
+	if s.caFile != "" {
+		pem, err := os.ReadFile(s.caFile)
+		if err != nil {
+			return nil, err
+		}
+		credentials.RootCAs = x509.NewCertPool()
+		if !credentials.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", s.caFile)
+		}
+	}
+	return credentials, nil
+}
+
+// tlsConfigForBroker returns the TLS configuration to connect to addr,
+// including the current credentials of Net.TLS.CredentialSource if set, and
+// the expiry of the client certificate, if any.
+func tlsConfigForBroker(addr string, conf *Config) (*tls.Config, time.Time, error) {
+	cfg := validServerNameTLS(addr, conf.Net.TLS.Config)
+	if conf.Net.TLS.CredentialSource == nil {
+		var expiry time.Time
+		if len(cfg.Certificates) > 0 {
+			expiry = certificateExpiry(&cfg.Certificates[0])
+		}
+		return cfg, expiry, nil
+	}
+
+	credentials, err := conf.Net.TLS.CredentialSource.Credentials()
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	if credentials == nil {
+		return nil, time.Time{}, errors.New("kafka: TLS credential source returned no credentials")
+	}
+
+	cfg = cfg.Clone()
+	var expiry time.Time
+	if credentials.Certificate != nil {
+		cfg.Certificates = []tls.Certificate{*credentials.Certificate}
+		expiry = certificateExpiry(credentials.Certificate)
+	}
+	if credentials.RootCAs != nil {
+		cfg.RootCAs = credentials.RootCAs
+	}
+	return cfg, expiry, nil
+}
+
+// certificateExpiry returns the NotAfter time of the leaf certificate of
+// cert, or the zero time if it cannot be parsed.
+func certificateExpiry(cert *tls.Certificate) time.Time {
+	leaf := cert.Leaf
+	if leaf == nil {
+		if len(cert.Certificate) == 0 {
+			return time.Time{}
+		}
+		var err error
+		if leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+			return time.Time{}
+		}
+	}
+	return leaf.NotAfter
+}
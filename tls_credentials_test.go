@@ -0,0 +1,225 @@
+//go:build !functional
+
+package sarama
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rcrowley/go-metrics"
+)
+
+type testCertificate struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCertificate(t *testing.T, template *x509.Certificate, parent *testCertificate) *testCertificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template.SerialNumber = big.NewInt(time.Now().UnixNano())
+	if template.NotBefore.IsZero() {
+		template.NotBefore = time.Now().Add(-time.Hour)
+	}
+	parentCert, parentKey := template, key
+	if parent != nil {
+		parentCert, parentKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parentCert, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCertificate{cert: cert, key: key}
+}
+
+func (c *testCertificate) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.cert.Raw}, PrivateKey: c.key}
+}
+
+func (c *testCertificate) writePEM(t *testing.T, certFile, keyFile string) {
+	t.Helper()
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.cert.Raw}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if keyFile == "" {
+		return
+	}
+	der, err := x509.MarshalECPrivateKey(c.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newTestCA(t *testing.T) *testCertificate {
+	return newTestCertificate(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "ca"},
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil)
+}
+
+func newTestClientCertificate(t *testing.T, ca *testCertificate, notAfter time.Time) *testCertificate {
+	return newTestCertificate(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "client"},
+		NotAfter:    notAfter,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca)
+}
+
+func TestFileTLSCredentialSourceReload(t *testing.T) {
+	ca := newTestCA(t)
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "client.crt")
+	keyFile := filepath.Join(dir, "client.key")
+	caFile := filepath.Join(dir, "ca.crt")
+
+	first := newTestClientCertificate(t, ca, time.Now().Add(time.Hour).Truncate(time.Second))
+	first.writePEM(t, certFile, keyFile)
+	ca.writePEM(t, caFile, "")
+
+	source, err := NewFileTLSCredentialSource(certFile, keyFile, caFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	credentials, err := source.Credentials()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expiry := certificateExpiry(credentials.Certificate); !expiry.Equal(first.cert.NotAfter) {
+		t.Errorf("expected the first certificate, got one expiring at %s", expiry)
+	}
+	if credentials.RootCAs == nil {
+		t.Error("expected the CA bundle to be loaded")
+	}
+
+	// rotate the certificate
+	second := newTestClientCertificate(t, ca, time.Now().Add(2*time.Hour).Truncate(time.Second))
+	second.writePEM(t, certFile, keyFile)
+	future := time.Now().Add(time.Minute)
+	for _, name := range []string{certFile, keyFile} {
+		if err := os.Chtimes(name, future, future); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if credentials, err = source.Credentials(); err != nil {
+		t.Fatal(err)
+	}
+	if expiry := certificateExpiry(credentials.Certificate); !expiry.Equal(second.cert.NotAfter) {
+		t.Errorf("expected the rotated certificate, got one expiring at %s", expiry)
+	}
+
+	// a partially written key keeps the previous credentials
+	if err := os.WriteFile(keyFile, []byte("garbage"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if credentials, err = source.Credentials(); err != nil {
+		t.Fatal(err)
+	}
+	if expiry := certificateExpiry(credentials.Certificate); !expiry.Equal(second.cert.NotAfter) {
+		t.Errorf("expected the previous certificate, got one expiring at %s", expiry)
+	}
+
+	if _, err := NewFileTLSCredentialSource(certFile, "", caFile); err == nil {
+		t.Error("expected an error without a key file")
+	}
+}
+
+func TestTLSCredentialSourceRecycle(t *testing.T) {
+	ca := newTestCA(t)
+	host := newTestCertificate(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "host"},
+		IPAddresses: []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotAfter:    time.Now().Add(time.Hour),
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, ca)
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{host.tlsCertificate()},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+		MinVersion:   tls.VersionTLS12,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mockBroker := NewMockBrokerListener(t, 1, listener)
+	defer mockBroker.Close()
+	mockBroker.SetHandlerByMap(map[string]MockResponse{
+		"MetadataRequest": NewMockMetadataResponse(t),
+	})
+
+	var calls atomic.Int32
+	client := newTestClientCertificate(t, ca, time.Now().Add(time.Hour))
+
+	conf := NewTestConfig()
+	conf.Net.TLS.Enable = true
+	conf.Net.TLS.Config = &tls.Config{MinVersion: tls.VersionTLS12}
+	conf.Net.TLS.CredentialSource = TLSCredentialsFunc(func() (*TLSCredentials, error) {
+		calls.Add(1)
+		cert := client.tlsCertificate()
+		return &TLSCredentials{Certificate: &cert, RootCAs: pool}, nil
+	})
+	conf.Net.TLS.RecycleBeforeExpiry = time.Until(client.cert.NotAfter) - 500*time.Millisecond
+
+	broker := NewBroker(mockBroker.Addr())
+	if err := broker.Open(conf); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = broker.Close() })
+	if _, err := broker.GetMetadata(&MetadataRequest{}); err != nil {
+		t.Fatal(err)
+	}
+
+	gauge, ok := conf.MetricRegistry.Get("tls-certificate-expiry").(metrics.Gauge)
+	if !ok {
+		t.Fatal("expected a tls-certificate-expiry gauge")
+	}
+	if gauge.Value() != client.cert.NotAfter.Unix() {
+		t.Errorf("unexpected certificate expiry %d", gauge.Value())
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if connected, _ := broker.Connected(); !connected {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("connection was not recycled before the certificate expiry")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// the next request re-establishes the connection with the current credentials
+	if _, err := broker.GetMetadata(&MetadataRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("expected the credentials to be fetched for each connection, got %d calls", n)
+	}
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/davecgh/go-spew/spew"
+)
+
+var (
+	capture = flag.String("capture", "", "REQUIRED: the capture file to read, as written by Config.Net.Capture")
+	replay  = flag.Bool("replay", false, "Whether to replay the captured requests against a mock broker serving the captured responses")
+	verbose = flag.Bool("verbose", false, "Whether to turn on sarama logging")
+
+	logger = log.New(os.Stderr, "", log.LstdFlags)
+
+	dump = spew.ConfigState{Indent: "  ", DisablePointerAddresses: true, DisableCapacities: true, SortKeys: true}
+)
+
+func main() {
+	flag.Parse()
+
+	if *capture == "" {
+		printUsageErrorAndExit("-capture is required")
+	}
+
+	if *verbose {
+		sarama.Logger = logger
+	}
+
+	records, err := readCapture(*capture)
+	if err != nil {
+		printErrorAndExit(66, "Failed to read capture: %s", err)
+	}
+
+	if *replay {
+		if err := replayCapture(records); err != nil {
+			printErrorAndExit(69, "Failed to replay capture: %s", err)
+		}
+		return
+	}
+
+	for _, record := range records {
+		printRecord(record)
+	}
+}
+
+func readCapture(name string) ([]*sarama.CaptureRecord, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	reader, err := sarama.NewCaptureReader(f)
+	if err != nil {
+		return nil, err
+	}
+	var records []*sarama.CaptureRecord
+	for {
+		record, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		} else if err != nil {
+			return records, err
+		}
+		records = append(records, record)
+	}
+}
+
+func printRecord(record *sarama.CaptureRecord) {
+	direction := "->"
+	if record.Response {
+		direction = "<-"
+	}
+	body, err := record.Decode()
+	fmt.Printf("%s broker %d conn %d %s key %d v%d correlation %d (%d bytes)\n",
+		record.Time.Format(time.RFC3339Nano), record.BrokerID, record.ConnectionID, direction,
+		record.APIKey, record.APIVersion, record.CorrelationID, len(record.Frame))
+	if err != nil {
+		fmt.Printf("failed to decode: %s\n\n", err)
+		return
+	}
+	fmt.Println(dump.Sdump(body))
+}
+
+// replayCapture sends the captured requests to a mock broker answering them
+// with the captured responses, and prints the responses decoded by sarama.
+func replayCapture(records []*sarama.CaptureRecord) error {
+	broker := sarama.NewMockBroker(reporter{}, 1)
+	defer broker.Close()
+
+	// answered tells the requests, by index, that got a response. Correlation
+	// ids are only unique within a connection.
+	type requestID struct {
+		connection    int64
+		correlationID int32
+	}
+	answered := make(map[int]bool)
+	pending := make(map[requestID]int)
+	handlers := make(map[string]sarama.MockResponse)
+	mock := sarama.NewMockCaptureResponse(reporter{}, records)
+	for i, record := range records {
+		id := requestID{record.ConnectionID, record.CorrelationID}
+		if record.Response {
+			if request, ok := pending[id]; ok {
+				answered[request] = true
+				delete(pending, id)
+			}
+			continue
+		}
+		pending[id] = i
+		body, err := record.Decode()
+		if err != nil {
+			return err
+		}
+		handlers[strings.TrimPrefix(fmt.Sprintf("%T", body), "*sarama.")] = mock
+	}
+	broker.SetHandlerByMap(handlers)
+
+	conn, err := net.Dial("tcp", broker.Addr())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	for i, record := range records {
+		if record.Response {
+			continue
+		}
+		printRecord(record)
+
+		frame := binary.BigEndian.AppendUint32(nil, uint32(len(record.Frame)))
+		if _, err := conn.Write(append(frame, record.Frame...)); err != nil {
+			return err
+		}
+		if !answered[i] {
+			continue
+		}
+
+		var size [4]byte
+		if _, err := io.ReadFull(conn, size[:]); err != nil {
+			return err
+		}
+		response := &sarama.CaptureRecord{
+			Time:          time.Now(),
+			Response:      true,
+			BrokerID:      broker.BrokerID(),
+			ConnectionID:  record.ConnectionID,
+			APIKey:        record.APIKey,
+			APIVersion:    record.APIVersion,
+			CorrelationID: record.CorrelationID,
+			Frame:         make([]byte, binary.BigEndian.Uint32(size[:])),
+		}
+		if _, err := io.ReadFull(conn, response.Frame); err != nil {
+			return err
+		}
+		printRecord(response)
+	}
+	return nil
+}
+
+// reporter reports the errors of the mock broker.
+type reporter struct{}
+
+func (reporter) Error(args ...interface{}) {
+	logger.Println(args...)
+}
+
+func (reporter) Errorf(format string, args ...interface{}) {
+	logger.Printf(format, args...)
+}
+
+func (reporter) Fatal(args ...interface{}) {
+	printErrorAndExit(70, "%s", fmt.Sprint(args...))
+}
+
+func (reporter) Fatalf(format string, args ...interface{}) {
+	printErrorAndExit(70, format, args...)
+}
+
+func (reporter) Helper() {}
+
+func printErrorAndExit(code int, format string, values ...interface{}) {
+	fmt.Fprintf(os.Stderr, "ERROR: %s\n", fmt.Sprintf(format, values...))
+	fmt.Fprintln(os.Stderr)
+	os.Exit(code)
+}
+
+func printUsageErrorAndExit(format string, values ...interface{}) {
+	fmt.Fprintf(os.Stderr, "ERROR: %s\n", fmt.Sprintf(format, values...))
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Available command line options:")
+	flag.PrintDefaults()
+	os.Exit(64)
+}
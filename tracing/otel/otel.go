@@ -0,0 +1,238 @@
+// Package otel provides OpenTelemetry tracing for Sarama, following the
+// OpenTelemetry semantic conventions for messaging systems:
+//
+//   - a producer span is started for each ProducerMessage and ended when the
+//     message is acknowledged by the broker, or fails,
+//   - a consumer span is created for each ConsumerMessage,
+//   - a client span is created for each request sent to a broker.
+//
+// The trace context is propagated in the headers of the messages with the
+// configured propagator, W3C traceparent by default, so that consumer spans
+// are children of the producer spans of their messages.
+//
+// Register a Tracer with a sarama.Config with Tracer.Apply:
+//
+//	tracer := otel.NewTracer(otel.WithTracerProvider(provider))
+//	tracer.Apply(config)
+package otel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+
+	"github.com/IBM/sarama"
+	otelapi "go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope name of the tracer.
+const ScopeName = "github.com/IBM/sarama/tracing/otel"
+
+// Option configures a Tracer.
+type Option func(*Tracer)
+
+// WithTracerProvider sets the tracer provider spans are created with,
+// otel.GetTracerProvider() by default.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(t *Tracer) { t.provider = provider }
+}
+
+// WithPropagator sets the propagator of the trace context in the headers of
+// the messages, W3C trace context by default.
+func WithPropagator(propagator propagation.TextMapPropagator) Option {
+	return func(t *Tracer) { t.propagator = propagator }
+}
+
+// Tracer traces the messages produced and consumed, and the requests sent to
+// the brokers, by the clients of the configurations it is applied to.
+//
+// It implements sarama.ProducerAcknowledgementInterceptor,
+// sarama.ConsumerInterceptor and sarama.RequestObserver.
+type Tracer struct {
+	provider   trace.TracerProvider
+	propagator propagation.TextMapPropagator
+	tracer     trace.Tracer
+
+	// spans of the messages being produced
+	spans sync.Map // map[*sarama.ProducerMessage]trace.Span
+	// spans of the requests awaiting their response
+	requests sync.Map // map[requestKey]trace.Span
+}
+
+// requestKey identifies a request in flight, correlation ids being unique
+// to a broker connection.
+type requestKey struct {
+	broker        *sarama.Broker
+	correlationID int32
+}
+
+// NewTracer returns a Tracer configured with opts.
+func NewTracer(opts ...Option) *Tracer {
+	t := &Tracer{
+		provider:   otelapi.GetTracerProvider(),
+		propagator: propagation.TraceContext{},
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	t.tracer = t.provider.Tracer(ScopeName)
+	return t
+}
+
+// Apply registers t with conf, as producer and consumer interceptor and as
+// request observer. It is appended to the interceptors already configured so
+// that it sees messages as they are sent to and received from the brokers,
+// and notified after the request observer already configured, if any.
+func (t *Tracer) Apply(conf *sarama.Config) {
+	conf.Producer.Interceptors = append(conf.Producer.Interceptors, t)
+	conf.Consumer.Interceptors = append(conf.Consumer.Interceptors, t)
+	if conf.RequestObserver != nil {
+		conf.RequestObserver = requestObservers{conf.RequestObserver, t}
+	} else {
+		conf.RequestObserver = t
+	}
+}
+
+// OnSend starts the producer span of msg, as a child of the trace context
+// found in its headers if any, and injects the span context in its headers.
+// The span of a retried message is kept until it is acknowledged.
+func (t *Tracer) OnSend(msg *sarama.ProducerMessage) {
+	if _, retried := t.spans.LoadOrStore(msg, noopSpan); retried {
+		return
+	}
+	carrier := producerMessageCarrier{msg}
+	ctx := t.propagator.Extract(context.Background(), carrier)
+	attrs := []attribute.KeyValue{
+		messagingSystem,
+		attribute.String("messaging.operation.type", "send"),
+		attribute.String("messaging.operation.name", "send"),
+		attribute.String("messaging.destination.name", msg.Topic),
+	}
+	if msg.Key != nil {
+		if key, err := msg.Key.Encode(); err == nil {
+			attrs = append(attrs, attribute.String("messaging.kafka.message.key", string(key)))
+		}
+	}
+	ctx, span := t.tracer.Start(ctx, "send "+msg.Topic,
+		trace.WithSpanKind(trace.SpanKindProducer), trace.WithAttributes(attrs...))
+	t.propagator.Inject(ctx, carrier)
+	t.spans.Store(msg, span)
+}
+
+// OnAcknowledgement ends the producer span of msg.
+func (t *Tracer) OnAcknowledgement(msg *sarama.ProducerMessage, err error) {
+	value, ok := t.spans.LoadAndDelete(msg)
+	if !ok {
+		return
+	}
+	span := value.(trace.Span)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		span.SetAttributes(attribute.String("error.type", errorType(err)))
+	} else {
+		span.SetAttributes(
+			attribute.String("messaging.destination.partition.id", strconv.FormatInt(int64(msg.Partition), 10)),
+			attribute.Int64("messaging.kafka.offset", msg.Offset),
+		)
+	}
+	span.End()
+}
+
+// OnConsume creates the consumer span of msg, as a child of the producer span
+// whose context is found in its headers. The span is ended right away as it
+// only records the receipt of the message, use Context to trace its
+// processing.
+func (t *Tracer) OnConsume(msg *sarama.ConsumerMessage) {
+	ctx := t.propagator.Extract(context.Background(), consumerMessageCarrier{msg})
+	_, span := t.tracer.Start(ctx, "receive "+msg.Topic,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithTimestamp(msg.Timestamp),
+		trace.WithAttributes(
+			messagingSystem,
+			attribute.String("messaging.operation.type", "receive"),
+			attribute.String("messaging.operation.name", "receive"),
+			attribute.String("messaging.destination.name", msg.Topic),
+			attribute.String("messaging.destination.partition.id", strconv.FormatInt(int64(msg.Partition), 10)),
+			attribute.Int64("messaging.kafka.offset", msg.Offset),
+			attribute.String("messaging.kafka.message.key", string(msg.Key)),
+		))
+	span.End()
+}
+
+// Context returns ctx with the trace context propagated in the headers of
+// msg, so that the spans processing the message are children of the span
+// that produced it.
+func (t *Tracer) Context(ctx context.Context, msg *sarama.ConsumerMessage) context.Context {
+	return t.propagator.Extract(ctx, consumerMessageCarrier{msg})
+}
+
+// BeforeRequest starts the client span of a request sent to broker.
+func (t *Tracer) BeforeRequest(broker *sarama.Broker, req sarama.ObservedRequest) {
+	attrs := []attribute.KeyValue{
+		messagingSystem,
+		attribute.Int("kafka.api.key", int(req.APIKey)),
+		attribute.Int("kafka.api.version", int(req.APIVersion)),
+		attribute.Int("kafka.correlation_id", int(req.CorrelationID)),
+		attribute.Int("kafka.broker.id", int(broker.ID())),
+	}
+	if host, port, err := net.SplitHostPort(broker.Addr()); err == nil {
+		attrs = append(attrs, attribute.String("server.address", host))
+		if port, err := strconv.Atoi(port); err == nil {
+			attrs = append(attrs, attribute.Int("server.port", port))
+		}
+	}
+	_, span := t.tracer.Start(context.Background(), req.Name,
+		trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+	t.requests.Store(requestKey{broker, req.CorrelationID}, span)
+}
+
+// AfterResponse ends the client span of the request res answers to.
+func (t *Tracer) AfterResponse(broker *sarama.Broker, res sarama.ObservedResponse) {
+	value, ok := t.requests.LoadAndDelete(requestKey{broker, res.Request.CorrelationID})
+	if !ok {
+		return
+	}
+	span := value.(trace.Span)
+	if err := res.Err; err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		span.SetAttributes(attribute.String("error.type", errorType(err)))
+	}
+	span.End()
+}
+
+// requestObservers notifies several request observers in turn.
+type requestObservers []sarama.RequestObserver
+
+func (o requestObservers) BeforeRequest(broker *sarama.Broker, req sarama.ObservedRequest) {
+	for _, observer := range o {
+		observer.BeforeRequest(broker, req)
+	}
+}
+
+func (o requestObservers) AfterResponse(broker *sarama.Broker, res sarama.ObservedResponse) {
+	for _, observer := range o {
+		observer.AfterResponse(broker, res)
+	}
+}
+
+var messagingSystem = attribute.String("messaging.system", "kafka")
+
+// noopSpan holds the place of the span of a message while it is started.
+var noopSpan = trace.SpanFromContext(context.Background())
+
+func errorType(err error) string {
+	var kerr sarama.KError
+	if errors.As(err, &kerr) {
+		return strconv.Itoa(int(kerr))
+	}
+	return fmt.Sprintf("%T", err)
+}
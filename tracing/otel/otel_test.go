@@ -0,0 +1,166 @@
+package otel
+
+import (
+	"testing"
+
+	"github.com/IBM/sarama"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newTestTracer() (*Tracer, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	return NewTracer(WithTracerProvider(provider)), recorder
+}
+
+func attr(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestTracerPropagation(t *testing.T) {
+	tracer, recorder := newTestTracer()
+
+	msg := &sarama.ProducerMessage{Topic: "orders", Key: sarama.StringEncoder("k")}
+	tracer.OnSend(msg)
+	if len(msg.Headers) != 1 || string(msg.Headers[0].Key) != "traceparent" {
+		t.Fatalf("expected a traceparent header, got %v", msg.Headers)
+	}
+	msg.Partition, msg.Offset = 2, 42
+	tracer.OnAcknowledgement(msg, nil)
+
+	failed := &sarama.ProducerMessage{Topic: "orders"}
+	tracer.OnSend(failed)
+	tracer.OnAcknowledgement(failed, sarama.ErrNotLeaderForPartition)
+
+	cm := &sarama.ConsumerMessage{Topic: "orders", Partition: 2, Offset: 42, Headers: []*sarama.RecordHeader{&msg.Headers[0]}}
+	tracer.OnConsume(cm)
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("expected 3 spans, got %d", len(spans))
+	}
+	send, sendFailed, receive := spans[0], spans[1], spans[2]
+	if send.Name() != "send orders" || send.SpanKind() != trace.SpanKindProducer ||
+		attr(send, "messaging.kafka.offset").AsInt64() != 42 ||
+		attr(send, "messaging.kafka.message.key").AsString() != "k" {
+		t.Errorf("unexpected producer span %s %v", send.Name(), send.Attributes())
+	}
+	if sendFailed.Status().Code != codes.Error || attr(sendFailed, "error.type").AsString() != "6" {
+		t.Errorf("expected the failed producer span to have an error status, got %v", sendFailed.Status())
+	}
+	if receive.Name() != "receive orders" || receive.SpanKind() != trace.SpanKindConsumer ||
+		receive.Parent().SpanID() != send.SpanContext().SpanID() ||
+		receive.SpanContext().TraceID() != send.SpanContext().TraceID() {
+		t.Errorf("expected the consumer span to be a child of the producer span")
+	}
+
+	ctx := tracer.Context(t.Context(), cm)
+	if trace.SpanContextFromContext(ctx).SpanID() != send.SpanContext().SpanID() {
+		t.Error("expected the context of the producer span")
+	}
+}
+
+func TestTracerRetriedMessage(t *testing.T) {
+	tracer, recorder := newTestTracer()
+
+	mockBroker := sarama.NewMockBroker(t, 1)
+	defer mockBroker.Close()
+	mockBroker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(mockBroker.Addr(), mockBroker.BrokerID()).
+			SetLeader("orders", 0, mockBroker.BrokerID()),
+		"ProduceRequest": sarama.NewMockSequence(
+			sarama.NewMockProduceResponse(t).SetError("orders", 0, sarama.ErrNotLeaderForPartition),
+			sarama.NewMockProduceResponse(t),
+		),
+	})
+
+	config := sarama.NewConfig()
+	config.ApiVersionsRequest = false
+	config.Producer.Return.Successes = true
+	config.Producer.Retry.Backoff = 0
+	config.Producer.Interceptors = []sarama.ProducerInterceptor{tracer}
+	producer, err := sarama.NewSyncProducer([]string{mockBroker.Addr()}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer producer.Close()
+
+	msg := &sarama.ProducerMessage{Topic: "orders"}
+	if _, _, err := producer.SendMessage(msg); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	if started := recorder.Started(); len(started) != 1 {
+		t.Errorf("expected the retried message to have a single span, got %d", len(started))
+	}
+	if len(msg.Headers) != 1 || string(msg.Headers[0].Key) != "traceparent" {
+		t.Errorf("expected a single traceparent header, got %v", msg.Headers)
+	}
+	if spans[0].Parent().IsValid() || spans[0].Status().Code == codes.Error {
+		t.Errorf("expected a successful root producer span, got %v", spans[0].Status())
+	}
+}
+
+// countingObserver counts the responses it is notified of.
+type countingObserver struct {
+	responses int
+}
+
+func (o *countingObserver) BeforeRequest(*sarama.Broker, sarama.ObservedRequest) {}
+
+func (o *countingObserver) AfterResponse(*sarama.Broker, sarama.ObservedResponse) {
+	o.responses++
+}
+
+func TestTracerRequests(t *testing.T) {
+	tracer, recorder := newTestTracer()
+
+	mockBroker := sarama.NewMockBroker(t, 1)
+	defer mockBroker.Close()
+	mockBroker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t),
+	})
+
+	config := sarama.NewConfig()
+	config.ApiVersionsRequest = false
+	observer := &countingObserver{}
+	config.RequestObserver = observer
+	tracer.Apply(config)
+	broker := sarama.NewBroker(mockBroker.Addr())
+	if err := broker.Open(config); err != nil {
+		t.Fatal(err)
+	}
+	defer broker.Close()
+	if _, err := broker.GetMetadata(&sarama.MetadataRequest{}); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "Metadata" || span.SpanKind() != trace.SpanKindClient ||
+		attr(span, "kafka.api.key").AsInt64() != 3 ||
+		attr(span, "kafka.broker.id").AsInt64() != -1 ||
+		attr(span, "server.address").AsString() != "127.0.0.1" {
+		t.Errorf("unexpected request span %s %v", span.Name(), span.Attributes())
+	}
+	if observer.responses != 1 {
+		t.Errorf("expected the configured observer to be notified of 1 response, got %d", observer.responses)
+	}
+}
@@ -80,6 +80,7 @@ type transactionManager struct {
 	transactionalID    string
 	transactionTimeout time.Duration
 	client             Client
+	log                *logger
 
 	// when kafka cluster is at least 2.5.0.
 	// used to recover when producer failed.
@@ -222,7 +223,7 @@ func (t *transactionManager) transitionTo(target ProducerTxnStatusFlag, err erro
 		t.lastError = nil
 	}
 
-	DebugLogger.Printf("txnmgr/transition [%s] transition from %s to %s\n", t.transactionalID, t.status, target)
+	t.log.Debug("txnmgr/transition transition", "from", t.status, "to", target)
 
 	t.status = target
 	return err
@@ -305,8 +306,7 @@ func (t *transactionManager) publishOffsetsToTxn(offsets topicPartitionOffsets,
 				return err
 			}
 			backoff := t.computeBackoff(attemptsRemaining)
-			Logger.Printf("txnmgr/add-offset-to-txn [%s] retrying after %dms... (%d attempts remaining) (%s)\n",
-				t.transactionalID, backoff/time.Millisecond, attemptsRemaining, err)
+			t.log.Info("txnmgr/add-offset-to-txn retrying", "backoff", backoff, "attempts_remaining", attemptsRemaining, "err", err)
 			time.Sleep(backoff)
 			attemptsRemaining--
 		}
@@ -342,8 +342,7 @@ func (t *transactionManager) publishOffsetsToTxn(offsets topicPartitionOffsets,
 			return true, ErrTxnUnableToParseResponse
 		}
 		if response.Err == ErrNoError {
-			DebugLogger.Printf("txnmgr/add-offset-to-txn [%s] successful add-offset-to-txn with group %s %+v\n",
-				t.transactionalID, groupId, response)
+			t.log.Debug("txnmgr/add-offset-to-txn successful add-offset-to-txn", "group", groupId, "response", response)
 			// If no error, just exit.
 			return false, nil
 		}
@@ -388,8 +387,7 @@ func (t *transactionManager) publishOffsetsToTxn(offsets topicPartitionOffsets,
 				return r, err
 			}
 			backoff := t.computeBackoff(attemptsRemaining)
-			Logger.Printf("txnmgr/txn-offset-commit [%s] retrying after %dms... (%d attempts remaining) (%s)\n",
-				t.transactionalID, backoff/time.Millisecond, attemptsRemaining, err)
+			t.log.Info("txnmgr/txn-offset-commit retrying", "backoff", backoff, "attempts_remaining", attemptsRemaining, "err", err)
 			time.Sleep(backoff)
 			attemptsRemaining--
 		}
@@ -466,8 +464,7 @@ func (t *transactionManager) publishOffsetsToTxn(offsets topicPartitionOffsets,
 		resultOffsets = failedTxn
 
 		if len(resultOffsets) == 0 {
-			DebugLogger.Printf("txnmgr/txn-offset-commit [%s] successful txn-offset-commit with group %s\n",
-				t.transactionalID, groupId)
+			t.log.Debug("txnmgr/txn-offset-commit successful txn-offset-commit", "group", groupId)
 			return resultOffsets, false, nil
 		}
 		return resultOffsets, true, Wrap(ErrTxnOffsetCommit, responseErrors...)
@@ -509,11 +506,9 @@ func (t *transactionManager) initProducerId() (int64, int16, error) {
 		if err != nil {
 			return -1, -1, err
 		}
-		DebugLogger.Printf("txnmgr/init-producer-id [%s] invoking InitProducerId for the first time in order to acquire a producer ID\n",
-			t.transactionalID)
+		t.log.Debug("txnmgr/init-producer-id invoking InitProducerId for the first time in order to acquire a producer ID")
 	} else {
-		DebugLogger.Printf("txnmgr/init-producer-id [%s] invoking InitProducerId with current producer ID %d and epoch %d in order to bump the epoch\n",
-			t.transactionalID, t.producerID, t.producerEpoch)
+		t.log.Debug("txnmgr/init-producer-id invoking InitProducerId in order to bump the epoch", "producer_id", t.producerID, "producer_epoch", t.producerEpoch)
 	}
 
 	attemptsRemaining := t.client.Config().Producer.Transaction.Retry.Max
@@ -527,8 +522,7 @@ func (t *transactionManager) initProducerId() (int64, int16, error) {
 				return pid, pepoch, err
 			}
 			backoff := t.computeBackoff(attemptsRemaining)
-			Logger.Printf("txnmgr/init-producer-id [%s] retrying after %dms... (%d attempts remaining) (%s)\n",
-				t.transactionalID, backoff/time.Millisecond, attemptsRemaining, err)
+			t.log.Info("txnmgr/init-producer-id retrying", "backoff", backoff, "attempts_remaining", attemptsRemaining, "err", err)
 			time.Sleep(backoff)
 			attemptsRemaining--
 		}
@@ -564,8 +558,7 @@ func (t *transactionManager) initProducerId() (int64, int16, error) {
 			if err != nil {
 				return -1, -1, true, err
 			}
-			DebugLogger.Printf("txnmgr/init-producer-id [%s] successful init producer id %+v\n",
-				t.transactionalID, response)
+			t.log.Debug("txnmgr/init-producer-id successful init producer id", "response", response)
 			return response.ProducerID, response.ProducerEpoch, false, nil
 		}
 		switch response.Err {
@@ -626,8 +619,7 @@ func (t *transactionManager) endTxn(commit bool) error {
 				return err
 			}
 			backoff := t.computeBackoff(attemptsRemaining)
-			Logger.Printf("txnmgr/endtxn [%s] retrying after %dms... (%d attempts remaining) (%s)\n",
-				t.transactionalID, backoff/time.Millisecond, attemptsRemaining, err)
+			t.log.Info("txnmgr/endtxn retrying", "backoff", backoff, "attempts_remaining", attemptsRemaining, "err", err)
 			time.Sleep(backoff)
 			attemptsRemaining--
 		}
@@ -662,8 +654,7 @@ func (t *transactionManager) endTxn(commit bool) error {
 			return true, ErrTxnUnableToParseResponse
 		}
 		if response.Err == ErrNoError {
-			DebugLogger.Printf("txnmgr/endtxn [%s] successful to end txn %+v\n",
-				t.transactionalID, response)
+			t.log.Debug("txnmgr/endtxn successful to end txn", "response", response)
 			return false, t.completeTransaction()
 		}
 		switch response.Err {
@@ -804,7 +795,7 @@ func (t *transactionManager) publishTxnPartitions() error {
 				return err
 			}
 			backoff := computeBackoff(attemptsRemaining)
-			Logger.Printf("txnmgr/add-partition-to-txn retrying after %dms... (%d attempts remaining) (%s)\n", backoff/time.Millisecond, attemptsRemaining, err)
+			t.log.Info("txnmgr/add-partition-to-txn retrying", "backoff", backoff, "attempts_remaining", attemptsRemaining, "err", err)
 			time.Sleep(backoff)
 			attemptsRemaining--
 		}
@@ -885,8 +876,7 @@ func (t *transactionManager) publishTxnPartitions() error {
 
 		// handle end
 		if len(t.pendingPartitionsInCurrentTxn) == 0 {
-			DebugLogger.Printf("txnmgr/add-partition-to-txn [%s] successful to add partitions txn %+v\n",
-				t.transactionalID, addPartResponse)
+			t.log.Debug("txnmgr/add-partition-to-txn successful to add partitions txn", "response", addPartResponse)
 			return false, nil
 		}
 		return true, Wrap(ErrAddPartitionsToTxn, responseErrors...)
@@ -903,6 +893,10 @@ func newTransactionManager(conf *Config, client Client) (*transactionManager, er
 		partitionsInCurrentTxn:        topicPartitionSet{},
 		offsetsInCurrentTxn:           make(map[string]topicPartitionOffsets),
 		status:                        ProducerTxnFlagUninitialized,
+		log:                           newLogger(conf),
+	}
+	if conf.Producer.Transaction.ID != "" {
+		txnmgr.log = txnmgr.log.with("transactional_id", conf.Producer.Transaction.ID)
 	}
 
 	if conf.Producer.Idempotent {
@@ -916,8 +910,7 @@ func newTransactionManager(conf *Config, client Client) (*transactionManager, er
 		if err != nil {
 			return nil, err
 		}
-		Logger.Printf("txnmgr/init-producer-id [%s] obtained a ProducerId: %d and ProducerEpoch: %d\n",
-			txnmgr.transactionalID, txnmgr.producerID, txnmgr.producerEpoch)
+		txnmgr.log.Info("txnmgr/init-producer-id obtained a producer ID", "producer_id", txnmgr.producerID, "producer_epoch", txnmgr.producerEpoch)
 	}
 
 	return txnmgr, nil
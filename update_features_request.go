@@ -0,0 +1,164 @@
+package sarama
+
+import "time"
+
+// UpdateFeaturesRequestFeatureUpdate contains the requested change of a single finalized feature.
+type UpdateFeaturesRequestFeatureUpdate struct {
+	// Feature contains the name of the finalized feature to be updated.
+	Feature string
+	// MaxVersionLevel contains the new maximum version level for the finalized
+	// feature. A value >= 1 is valid. A value < 1, is special, and can be used
+	// to request the deletion of the finalized feature.
+	MaxVersionLevel int16
+	// AllowDowngrade is used in v0 only, and allows the max version level to be lowered.
+	AllowDowngrade bool
+	// UpgradeType determines the type of upgrade to perform (v1+).
+	UpgradeType FeatureUpgradeType
+}
+
+func (u *UpdateFeaturesRequestFeatureUpdate) encode(pe packetEncoder, version int16) error {
+	if err := pe.putString(u.Feature); err != nil {
+		return err
+	}
+	pe.putInt16(u.MaxVersionLevel)
+	if version == 0 {
+		pe.putBool(u.AllowDowngrade)
+	} else {
+		pe.putInt8(int8(u.UpgradeType))
+	}
+	pe.putEmptyTaggedFieldArray()
+	return nil
+}
+
+func (u *UpdateFeaturesRequestFeatureUpdate) decode(pd packetDecoder, version int16) (err error) {
+	if u.Feature, err = pd.getString(); err != nil {
+		return err
+	}
+	if u.MaxVersionLevel, err = pd.getInt16(); err != nil {
+		return err
+	}
+	if version == 0 {
+		if u.AllowDowngrade, err = pd.getBool(); err != nil {
+			return err
+		}
+	} else {
+		upgradeType, err := pd.getInt8()
+		if err != nil {
+			return err
+		}
+		u.UpgradeType = FeatureUpgradeType(upgradeType)
+	}
+	_, err = pd.getEmptyTaggedFieldArray()
+	return err
+}
+
+// UpdateFeaturesRequest is a request to update the cluster-wide finalized
+// features (KIP-584).
+type UpdateFeaturesRequest struct {
+	// Version defines the protocol version to use for encode and decode
+	Version int16
+	// Timeout contains how long to wait for the updates to be applied.
+	Timeout time.Duration
+	// FeatureUpdates contains the list of updates to finalized features.
+	FeatureUpdates []UpdateFeaturesRequestFeatureUpdate
+	// ValidateOnly is true if we should validate the request, but not perform the upgrade or downgrade (v1+).
+	ValidateOnly bool
+}
+
+func NewUpdateFeaturesRequest(version KafkaVersion, updates []UpdateFeaturesRequestFeatureUpdate, timeout time.Duration, validateOnly bool) *UpdateFeaturesRequest {
+	r := &UpdateFeaturesRequest{
+		Timeout:        timeout,
+		FeatureUpdates: updates,
+		ValidateOnly:   validateOnly,
+	}
+	if version.IsAtLeast(V3_3_0_0) {
+		r.Version = 1
+	}
+	return r
+}
+
+func (r *UpdateFeaturesRequest) setVersion(v int16) {
+	r.Version = v
+}
+
+func (r *UpdateFeaturesRequest) encode(pe packetEncoder) error {
+	pe.putInt32(int32(r.Timeout / time.Millisecond))
+
+	if err := pe.putArrayLength(len(r.FeatureUpdates)); err != nil {
+		return err
+	}
+	for i := range r.FeatureUpdates {
+		if err := r.FeatureUpdates[i].encode(pe, r.Version); err != nil {
+			return err
+		}
+	}
+
+	if r.Version >= 1 {
+		pe.putBool(r.ValidateOnly)
+	}
+
+	pe.putEmptyTaggedFieldArray()
+	return nil
+}
+
+func (r *UpdateFeaturesRequest) decode(pd packetDecoder, version int16) (err error) {
+	r.Version = version
+	timeout, err := pd.getInt32()
+	if err != nil {
+		return err
+	}
+	r.Timeout = time.Duration(timeout) * time.Millisecond
+
+	n, err := pd.getArrayLength()
+	if err != nil {
+		return err
+	}
+	r.FeatureUpdates = make([]UpdateFeaturesRequestFeatureUpdate, n)
+	for i := 0; i < n; i++ {
+		if err := r.FeatureUpdates[i].decode(pd, version); err != nil {
+			return err
+		}
+	}
+
+	if r.Version >= 1 {
+		if r.ValidateOnly, err = pd.getBool(); err != nil {
+			return err
+		}
+	}
+
+	_, err = pd.getEmptyTaggedFieldArray()
+	return err
+}
+
+func (r *UpdateFeaturesRequest) key() int16 {
+	return apiKeyUpdateFeatures
+}
+
+func (r *UpdateFeaturesRequest) version() int16 {
+	return r.Version
+}
+
+func (r *UpdateFeaturesRequest) headerVersion() int16 {
+	return 2
+}
+
+func (r *UpdateFeaturesRequest) isValidVersion() bool {
+	return r.Version >= 0 && r.Version <= 1
+}
+
+func (r *UpdateFeaturesRequest) isFlexible() bool {
+	return r.isFlexibleVersion(r.Version)
+}
+
+func (r *UpdateFeaturesRequest) isFlexibleVersion(version int16) bool {
+	return version >= 0
+}
+
+func (r *UpdateFeaturesRequest) requiredVersion() KafkaVersion {
+	switch r.Version {
+	case 1:
+		return V3_3_0_0
+	default:
+		return V2_7_0_0
+	}
+}
@@ -0,0 +1,52 @@
+//go:build !functional
+
+package sarama
+
+import (
+	"testing"
+	"time"
+)
+
+var (
+	updateFeaturesRequestV0 = []byte{
+		0, 0, 3, 232, // timeout 1000ms
+		2,                                                                                  // FeatureUpdates array, length 1
+		17, 'm', 'e', 't', 'a', 'd', 'a', 't', 'a', '.', 'v', 'e', 'r', 's', 'i', 'o', 'n', // Feature
+		0, 7, // MaxVersionLevel
+		1, // AllowDowngrade
+		0, // empty tagged fields
+		0, // empty tagged fields
+	}
+
+	updateFeaturesRequestV1 = []byte{
+		0, 0, 3, 232, // timeout 1000ms
+		2,                                                                                  // FeatureUpdates array, length 1
+		17, 'm', 'e', 't', 'a', 'd', 'a', 't', 'a', '.', 'v', 'e', 'r', 's', 'i', 'o', 'n', // Feature
+		0, 20, // MaxVersionLevel
+		1, // UpgradeType
+		0, // empty tagged fields
+		1, // ValidateOnly
+		0, // empty tagged fields
+	}
+)
+
+func TestUpdateFeaturesRequest(t *testing.T) {
+	request := &UpdateFeaturesRequest{
+		Version: 0,
+		Timeout: time.Second,
+		FeatureUpdates: []UpdateFeaturesRequestFeatureUpdate{
+			{Feature: MetadataVersionFeature, MaxVersionLevel: 7, AllowDowngrade: true},
+		},
+	}
+	testRequest(t, "V0", request, updateFeaturesRequestV0)
+
+	request = &UpdateFeaturesRequest{
+		Version: 1,
+		Timeout: time.Second,
+		FeatureUpdates: []UpdateFeaturesRequestFeatureUpdate{
+			{Feature: MetadataVersionFeature, MaxVersionLevel: 20, UpgradeType: FeatureUpgradeTypeUpgrade},
+		},
+		ValidateOnly: true,
+	}
+	testRequest(t, "V1", request, updateFeaturesRequestV1)
+}
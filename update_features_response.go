@@ -0,0 +1,141 @@
+package sarama
+
+import "time"
+
+// UpdatableFeatureResult contains the result of the update of a single finalized feature.
+type UpdatableFeatureResult struct {
+	// Feature contains the name of the finalized feature.
+	Feature string
+	// ErrorCode contains the feature update error code or 0 if the feature update succeeded.
+	ErrorCode KError
+	// ErrorMessage contains the feature update error, or `null` if the feature update succeeded.
+	ErrorMessage *string
+}
+
+func (u *UpdatableFeatureResult) encode(pe packetEncoder) error {
+	if err := pe.putString(u.Feature); err != nil {
+		return err
+	}
+	pe.putKError(u.ErrorCode)
+	if err := pe.putNullableString(u.ErrorMessage); err != nil {
+		return err
+	}
+	pe.putEmptyTaggedFieldArray()
+	return nil
+}
+
+func (u *UpdatableFeatureResult) decode(pd packetDecoder) (err error) {
+	if u.Feature, err = pd.getString(); err != nil {
+		return err
+	}
+	if u.ErrorCode, err = pd.getKError(); err != nil {
+		return err
+	}
+	if u.ErrorMessage, err = pd.getNullableString(); err != nil {
+		return err
+	}
+	_, err = pd.getEmptyTaggedFieldArray()
+	return err
+}
+
+type UpdateFeaturesResponse struct {
+	// Version defines the protocol version to use for encode and decode
+	Version int16
+	// ThrottleTime contains the duration for which the request was throttled due
+	// to a quota violation, or zero if the request did not violate any quota.
+	ThrottleTime time.Duration
+	// ErrorCode contains the top-level error code, or `0` if there was no top-level error.
+	ErrorCode KError
+	// ErrorMessage contains the top-level error message, or `null` if there was no top-level error.
+	ErrorMessage *string
+	// Results contains the results for each feature update.
+	Results []UpdatableFeatureResult
+}
+
+func (r *UpdateFeaturesResponse) setVersion(v int16) {
+	r.Version = v
+}
+
+func (r *UpdateFeaturesResponse) encode(pe packetEncoder) error {
+	pe.putDurationMs(r.ThrottleTime)
+	pe.putKError(r.ErrorCode)
+	if err := pe.putNullableString(r.ErrorMessage); err != nil {
+		return err
+	}
+
+	if err := pe.putArrayLength(len(r.Results)); err != nil {
+		return err
+	}
+	for i := range r.Results {
+		if err := r.Results[i].encode(pe); err != nil {
+			return err
+		}
+	}
+
+	pe.putEmptyTaggedFieldArray()
+	return nil
+}
+
+func (r *UpdateFeaturesResponse) decode(pd packetDecoder, version int16) (err error) {
+	r.Version = version
+	if r.ThrottleTime, err = pd.getDurationMs(); err != nil {
+		return err
+	}
+	if r.ErrorCode, err = pd.getKError(); err != nil {
+		return err
+	}
+	if r.ErrorMessage, err = pd.getNullableString(); err != nil {
+		return err
+	}
+
+	n, err := pd.getArrayLength()
+	if err != nil {
+		return err
+	}
+	r.Results = make([]UpdatableFeatureResult, n)
+	for i := 0; i < n; i++ {
+		if err := r.Results[i].decode(pd); err != nil {
+			return err
+		}
+	}
+
+	_, err = pd.getEmptyTaggedFieldArray()
+	return err
+}
+
+func (r *UpdateFeaturesResponse) key() int16 {
+	return apiKeyUpdateFeatures
+}
+
+func (r *UpdateFeaturesResponse) version() int16 {
+	return r.Version
+}
+
+func (r *UpdateFeaturesResponse) headerVersion() int16 {
+	return 1
+}
+
+func (r *UpdateFeaturesResponse) isValidVersion() bool {
+	return r.Version >= 0 && r.Version <= 1
+}
+
+func (r *UpdateFeaturesResponse) isFlexible() bool {
+	return r.isFlexibleVersion(r.Version)
+}
+
+func (r *UpdateFeaturesResponse) isFlexibleVersion(version int16) bool {
+	return version >= 0
+}
+
+func (r *UpdateFeaturesResponse) requiredVersion() KafkaVersion {
+	switch r.Version {
+	case 1:
+		return V3_3_0_0
+	default:
+		return V2_7_0_0
+	}
+}
+
+func (r *UpdateFeaturesResponse) throttleTime() time.Duration {
+	return r.ThrottleTime
+}
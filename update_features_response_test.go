@@ -0,0 +1,50 @@
+//go:build !functional
+
+package sarama
+
+import (
+	"testing"
+	"time"
+)
+
+var (
+	updateFeaturesResponseNoError = []byte{
+		0, 0, 0, 100, // ThrottleTimeMs 100
+		0, 0, // ErrorCode
+		0,                                                                                  // ErrorMessage null
+		2,                                                                                  // Results array, length 1
+		17, 'm', 'e', 't', 'a', 'd', 'a', 't', 'a', '.', 'v', 'e', 'r', 's', 'i', 'o', 'n', // Feature
+		0, 0, // ErrorCode
+		0, // ErrorMessage null
+		0, // empty tagged fields
+		0, // empty tagged fields
+	}
+
+	updateFeaturesResponseWithError = []byte{
+		0, 0, 0, 0, // ThrottleTimeMs 0
+		0, 95, // ErrorCode INVALID_UPDATE_VERSION
+		4, 'b', 'a', 'd', // ErrorMessage
+		1, // Results array, length 0
+		0, // empty tagged fields
+	}
+)
+
+func TestUpdateFeaturesResponse(t *testing.T) {
+	response := &UpdateFeaturesResponse{
+		Version:      1,
+		ThrottleTime: 100 * time.Millisecond,
+		Results: []UpdatableFeatureResult{
+			{Feature: MetadataVersionFeature},
+		},
+	}
+	testResponse(t, "no error", response, updateFeaturesResponseNoError)
+
+	errMsg := "bad"
+	response = &UpdateFeaturesResponse{
+		Version:      0,
+		ErrorCode:    KError(95),
+		ErrorMessage: &errMsg,
+		Results:      []UpdatableFeatureResult{},
+	}
+	testResponse(t, "with error", response, updateFeaturesResponseWithError)
+}